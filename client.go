@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/xxiiaaon/gotwi/internal/gotwierrors"
@@ -22,6 +25,10 @@ const (
 	APIKeySecretEnvName = "GOTWI_API_KEY_SECRET"
 )
 
+// DefaultBaseURL is the base URL that every endpoint is resolved against
+// when NewClientInput.BaseURL (or NewClientWithAccessTokenInput.BaseURL) is empty.
+const DefaultBaseURL = "https://api.twitter.com"
+
 type AuthenticationMethod string
 
 const (
@@ -40,12 +47,84 @@ type NewClientInput struct {
 	OAuthTokenSecret     string
 	APIKey               string
 	APIKeySecret         string
-	Debug                bool
+	BaseURL              string
+	RetryPolicy          *RetryPolicy
+	RateLimiter          *RateLimiter
+	Middlewares          []Middleware
+	Logger               *slog.Logger
+	Instrumentation      Instrumentation
+
+	// Clock and NonceSource are used for OAuth 1.0a signing.
+	// They default to the system clock and crypto/rand.
+	Clock       Clock
+	NonceSource NonceSource
+
+	// ReturnPartialErrors makes CallAPI return a *PartialErrors together with the data
+	// when the response contains partial errors.
+	ReturnPartialErrors bool
+
+	// MaxStreamMessageSize is the size in bytes of the largest stream message.
+	// Default is DefaultMaxStreamMessageSize.
+	MaxStreamMessageSize int
+
+	// Stream configures the HTTP client of the streaming endpoints.
+	Stream *StreamHTTPConfig
+
+	// Debug writes debug level logs to stdout when Logger is nil.
+	Debug bool
 }
 
 type NewClientWithAccessTokenInput struct {
-	HTTPClient  *http.Client
-	AccessToken string
+	HTTPClient      *http.Client
+	AccessToken     string
+	BaseURL         string
+	RetryPolicy     *RetryPolicy
+	RateLimiter     *RateLimiter
+	Middlewares     []Middleware
+	Logger          *slog.Logger
+	Instrumentation Instrumentation
+
+	// ReturnPartialErrors makes CallAPI return a *PartialErrors together with the data
+	// when the response contains partial errors.
+	ReturnPartialErrors bool
+
+	// MaxStreamMessageSize is the size in bytes of the largest stream message.
+	// Default is DefaultMaxStreamMessageSize.
+	MaxStreamMessageSize int
+
+	// Stream configures the HTTP client of the streaming endpoints.
+	Stream *StreamHTTPConfig
+}
+
+type NewUnauthenticatedClientInput struct {
+	HTTPClient      *http.Client
+	BaseURL         string
+	RateLimiter     *RateLimiter
+	Middlewares     []Middleware
+	Logger          *slog.Logger
+	Instrumentation Instrumentation
+}
+
+type NewClientWithTokenSourceInput struct {
+	HTTPClient      *http.Client
+	TokenSource     TokenSource
+	BaseURL         string
+	RetryPolicy     *RetryPolicy
+	RateLimiter     *RateLimiter
+	Middlewares     []Middleware
+	Logger          *slog.Logger
+	Instrumentation Instrumentation
+
+	// ReturnPartialErrors makes CallAPI return a *PartialErrors together with the data
+	// when the response contains partial errors.
+	ReturnPartialErrors bool
+
+	// MaxStreamMessageSize is the size in bytes of the largest stream message.
+	// Default is DefaultMaxStreamMessageSize.
+	MaxStreamMessageSize int
+
+	// Stream configures the HTTP client of the streaming endpoints.
+	Stream *StreamHTTPConfig
 }
 
 type IClient interface {
@@ -58,16 +137,31 @@ type IClient interface {
 	SigningKey() string
 }
 
+// Client is safe for concurrent use by multiple goroutines. The setters of the options,
+// such as SetBaseURL, SetRetryPolicy, SetRateLimiter, SetLogger, SetInstrumentation,
+// SetReturnPartialErrors and SetMaxStreamMessageSize, are not synchronized and must
+// only be called before the client is used. The setters of the credentials and Use
+// can be called at any time.
 type Client struct {
 	Client               *http.Client
-	authenticationMethod AuthenticationMethod
-	accessToken          string
-	oauthToken           string
-	oauthConsumerKey     string
-	signingKey           string
+	credentials          CredentialProvider
+	signer               oauth1Signer
+	tokenSource          TokenSource
 	apiKeyOverride       string
 	apiKeySecretOverride string
-	debug                bool
+	baseURL              string
+	retryPolicy          *RetryPolicy
+	rateLimits           rateLimitTracker
+	rateLimiter          *RateLimiter
+	middlewaresMu        sync.RWMutex
+	middlewares          []Middleware
+	logger               *slog.Logger
+	instrumentation      Instrumentation
+	returnPartialErrors  bool
+	maxStreamMessageSize int
+	streamHTTPClient     *http.Client
+	streamIdleTimeout    time.Duration
+	debugLogger          *slog.Logger
 }
 
 type ClientResponse struct {
@@ -91,18 +185,35 @@ func NewClient(in *NewClientInput) (*Client, error) {
 		return nil, fmt.Errorf("AuthenticationMethod is invalid.")
 	}
 
+	if !ValidBaseURL(in.BaseURL) {
+		return nil, fmt.Errorf("BaseURL is invalid.")
+	}
+
 	c := Client{
 		Client:               defaultHTTPClient,
-		authenticationMethod: in.AuthenticationMethod,
 		apiKeyOverride:       in.APIKey,
 		apiKeySecretOverride: in.APIKeySecret,
-		debug:                in.Debug,
+		baseURL:              in.BaseURL,
+		retryPolicy:          in.RetryPolicy,
+		rateLimiter:          in.RateLimiter,
+		middlewares:          in.Middlewares,
+		logger:               in.Logger,
+		instrumentation:      in.Instrumentation,
+		returnPartialErrors:  in.ReturnPartialErrors,
+		maxStreamMessageSize: in.MaxStreamMessageSize,
 	}
 
 	if in.HTTPClient != nil {
 		c.Client = in.HTTPClient
 	}
+	c.signer.clock = in.Clock
+	c.signer.nonceSource = in.NonceSource
+	c.configureStream(in.Stream)
+	if in.Debug {
+		c.debugLogger = newDebugLogger()
+	}
 
+	c.credentials.Rotate(Credentials{AuthenticationMethod: in.AuthenticationMethod})
 	if err := c.authorize(in.OAuthToken, in.OAuthTokenSecret); err != nil {
 		return nil, err
 	}
@@ -119,10 +230,91 @@ func NewClientWithAccessToken(in *NewClientWithAccessTokenInput) (*Client, error
 		return nil, fmt.Errorf("AccessToken is empty.")
 	}
 
+	if !ValidBaseURL(in.BaseURL) {
+		return nil, fmt.Errorf("BaseURL is invalid.")
+	}
+
 	c := Client{
 		Client:               defaultHTTPClient,
-		authenticationMethod: AuthenMethodOAuth2BearerToken,
-		accessToken:          in.AccessToken,
+		baseURL:              in.BaseURL,
+		retryPolicy:          in.RetryPolicy,
+		rateLimiter:          in.RateLimiter,
+		middlewares:          in.Middlewares,
+		logger:               in.Logger,
+		instrumentation:      in.Instrumentation,
+		returnPartialErrors:  in.ReturnPartialErrors,
+		maxStreamMessageSize: in.MaxStreamMessageSize,
+	}
+	c.credentials.Rotate(Credentials{
+		AuthenticationMethod: AuthenMethodOAuth2BearerToken,
+		AccessToken:          in.AccessToken,
+	})
+
+	if in.HTTPClient != nil {
+		c.Client = in.HTTPClient
+	}
+	c.configureStream(in.Stream)
+
+	return &c, nil
+}
+
+// NewClientWithTokenSource returns a client that authenticates every request
+// with the OAuth 2.0 access token returned by in.TokenSource.
+func NewClientWithTokenSource(in *NewClientWithTokenSourceInput) (*Client, error) {
+	if in == nil {
+		return nil, fmt.Errorf("NewClientWithTokenSourceInput is nil.")
+	}
+
+	if in.TokenSource == nil {
+		return nil, fmt.Errorf("TokenSource is nil.")
+	}
+
+	if !ValidBaseURL(in.BaseURL) {
+		return nil, fmt.Errorf("BaseURL is invalid.")
+	}
+
+	c := Client{
+		Client:               defaultHTTPClient,
+		tokenSource:          in.TokenSource,
+		baseURL:              in.BaseURL,
+		retryPolicy:          in.RetryPolicy,
+		rateLimiter:          in.RateLimiter,
+		middlewares:          in.Middlewares,
+		logger:               in.Logger,
+		instrumentation:      in.Instrumentation,
+		returnPartialErrors:  in.ReturnPartialErrors,
+		maxStreamMessageSize: in.MaxStreamMessageSize,
+	}
+	c.credentials.Rotate(Credentials{AuthenticationMethod: AuthenMethodOAuth2BearerToken})
+
+	if in.HTTPClient != nil {
+		c.Client = in.HTTPClient
+	}
+	c.configureStream(in.Stream)
+
+	return &c, nil
+}
+
+// NewUnauthenticatedClient returns a client without credentials for the endpoints that are
+// authenticated by the request itself, such as the OAuth 2.0 token endpoint. Its requests are
+// sent with Exec, through the middlewares, the logger and the instrumentation. It is not ready
+// for CallAPI.
+func NewUnauthenticatedClient(in *NewUnauthenticatedClientInput) (*Client, error) {
+	if in == nil {
+		return nil, fmt.Errorf("NewUnauthenticatedClientInput is nil.")
+	}
+
+	if !ValidBaseURL(in.BaseURL) {
+		return nil, fmt.Errorf("BaseURL is invalid.")
+	}
+
+	c := Client{
+		Client:          defaultHTTPClient,
+		baseURL:         in.BaseURL,
+		rateLimiter:     in.RateLimiter,
+		middlewares:     in.Middlewares,
+		logger:          in.Logger,
+		instrumentation: in.Instrumentation,
 	}
 
 	if in.HTTPClient != nil {
@@ -138,7 +330,10 @@ func (c *Client) authorize(oauthToken, oauthTokenSecret string) error {
 	if apiKey == "" || apiKeySecret == "" {
 		return fmt.Errorf("env '%s' and '%s' is required.", APIKeyEnvName, APIKeySecretEnvName)
 	}
-	c.oauthConsumerKey = apiKey
+	cr := Credentials{
+		AuthenticationMethod: c.AuthenticationMethod(),
+		OAuthConsumerKey:     apiKey,
+	}
 
 	switch c.AuthenticationMethod() {
 	case AuthenMethodOAuth1UserContext:
@@ -146,8 +341,8 @@ func (c *Client) authorize(oauthToken, oauthTokenSecret string) error {
 			return fmt.Errorf("OAuthToken and OAuthTokenSecret is required for using %s.", AuthenMethodOAuth1UserContext)
 		}
 
-		c.oauthToken = oauthToken
-		c.signingKey = fmt.Sprintf("%s&%s",
+		cr.OAuthToken = oauthToken
+		cr.SigningKey = fmt.Sprintf("%s&%s",
 			url.QueryEscape(apiKeySecret),
 			url.QueryEscape(oauthTokenSecret))
 	case AuthenMethodOAuth2BearerToken:
@@ -156,9 +351,10 @@ func (c *Client) authorize(oauthToken, oauthTokenSecret string) error {
 			return err
 		}
 
-		c.accessToken = accessToken
+		cr.AccessToken = accessToken
 	}
 
+	c.credentials.Rotate(cr)
 	return nil
 }
 
@@ -167,30 +363,32 @@ func (c *Client) IsReady() bool {
 		return false
 	}
 
-	if !c.AuthenticationMethod().Valid() {
-		return false
-	}
+	return credentialsReady(c.credentials.Credentials(), c.TokenSource())
+}
 
-	switch c.AuthenticationMethod() {
-	case AuthenMethodOAuth1UserContext:
-		if c.OAuthToken() == "" || c.SigningKey() == "" {
-			return false
-		}
-	case AuthenMethodOAuth2BearerToken:
-		if c.AccessToken() == "" {
-			return false
-		}
-	}
+func (c *Client) AccessToken() string {
+	return c.credentials.Credentials().AccessToken
+}
 
-	return true
+// CredentialProvider returns the live credentials of the client.
+// Rotating them also affects the TypedClients created from the client.
+func (c *Client) CredentialProvider() *CredentialProvider {
+	if c == nil {
+		return nil
+	}
+	return &c.credentials
 }
 
-func (c *Client) AccessToken() string {
-	return c.accessToken
+// TokenSource returns the source of the access tokens, or nil if the client uses a static access token.
+func (c *Client) TokenSource() TokenSource {
+	if c == nil {
+		return nil
+	}
+	return c.tokenSource
 }
 
 func (c *Client) AuthenticationMethod() AuthenticationMethod {
-	return c.authenticationMethod
+	return c.credentials.Credentials().AuthenticationMethod
 }
 
 func (c *Client) APIKey() string {
@@ -207,50 +405,151 @@ func (c *Client) APIKeySecret() string {
 	return os.Getenv(APIKeySecretEnvName)
 }
 
+// BaseURL returns the base URL that endpoints are resolved against.
+func (c *Client) BaseURL() string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return DefaultBaseURL
+}
+
+// ResolveURL joins an endpoint path such as "/2/tweets" to the base URL of the client.
+// Endpoints that are already absolute URLs are returned as is.
+func (c *Client) ResolveURL(endpoint string) string {
+	return resolveURL(c.BaseURL(), endpoint)
+}
+
 func (c *Client) OAuthToken() string {
-	return c.oauthToken
+	return c.credentials.Credentials().OAuthToken
 }
 func (c *Client) OAuthConsumerKey() string {
-	return c.oauthConsumerKey
+	return c.credentials.Credentials().OAuthConsumerKey
 }
 func (c *Client) SigningKey() string {
-	return c.signingKey
+	return c.credentials.Credentials().SigningKey
 }
 
 func (c *Client) SetAccessToken(v string) {
-	c.accessToken = v
+	c.credentials.update(func(cr *Credentials) { cr.AccessToken = v })
 }
 
 func (c *Client) SetAuthenticationMethod(v AuthenticationMethod) {
-	c.authenticationMethod = v
+	c.credentials.update(func(cr *Credentials) { cr.AuthenticationMethod = v })
+}
+
+func (c *Client) SetBaseURL(v string) {
+	c.baseURL = v
+}
+
+func (c *Client) SetRetryPolicy(v *RetryPolicy) {
+	c.retryPolicy = v
+}
+
+func (c *Client) RateLimiter() *RateLimiter {
+	if c == nil {
+		return nil
+	}
+	return c.rateLimiter
+}
+
+// Logger returns the logger of the client, or nil if logging is disabled.
+func (c *Client) Logger() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return c.debugLogger
+}
+
+func (c *Client) SetLogger(v *slog.Logger) {
+	c.logger = v
+}
+
+// Instrumentation returns the instrumentation of the client. It is never nil.
+func (c *Client) Instrumentation() Instrumentation {
+	if c.instrumentation == nil {
+		return NopInstrumentation{}
+	}
+	return c.instrumentation
+}
+
+func (c *Client) SetInstrumentation(v Instrumentation) {
+	c.instrumentation = v
+}
+
+// ReturnPartialErrors reports whether CallAPI returns a *PartialErrors for the partial errors in a response.
+func (c *Client) ReturnPartialErrors() bool {
+	return c.returnPartialErrors
+}
+
+func (c *Client) SetReturnPartialErrors(v bool) {
+	c.returnPartialErrors = v
+}
+
+// MaxStreamMessageSize returns the size in bytes of the largest stream message, or 0 for the default.
+func (c *Client) MaxStreamMessageSize() int {
+	return c.maxStreamMessageSize
+}
+
+func (c *Client) SetMaxStreamMessageSize(v int) {
+	c.maxStreamMessageSize = v
+}
+
+func (c *Client) SetRateLimiter(v *RateLimiter) {
+	c.rateLimiter = v
 }
 
 func (c *Client) SetOAuthToken(v string) {
-	c.oauthToken = v
+	c.credentials.update(func(cr *Credentials) { cr.OAuthToken = v })
 }
 func (c *Client) SetOAuthConsumerKey(v string) {
-	c.oauthConsumerKey = v
+	c.credentials.update(func(cr *Credentials) { cr.OAuthConsumerKey = v })
 }
 func (c *Client) SetSigningKey(v string) {
-	c.signingKey = v
+	c.credentials.update(func(cr *Credentials) { cr.SigningKey = v })
 }
 
 func (c *Client) CallAPI(ctx context.Context, endpoint, method string, p util.Parameters, i util.Response) error {
-	req, err := prepare(ctx, endpoint, method, p, c)
-	if err != nil {
-		return wrapErr(err)
-	}
+	key := strings.ToUpper(method) + " " + endpointTemplate(endpoint)
+	authRetried := false
+	for attempt := 1; ; attempt++ {
+		if err := c.RateLimiter().wait(ctx, key); err != nil {
+			return wrapErr(err)
+		}
 
-	non200err, err := c.Exec(req, i)
-	if err != nil {
-		return wrapErr(err)
-	}
+		// The request is prepared for every attempt so that the body and
+		// the OAuth 1.0a nonce and timestamp are fresh.
+		req, err := prepare(ctx, endpoint, method, p, c)
+		if err != nil {
+			return wrapErr(err)
+		}
 
-	if non200err != nil {
-		return wrapWithAPIErr(non200err)
-	}
+		non200err, partial, err := c.exec(req, i, c.returnPartialErrors)
+		if err != nil {
+			return wrapErr(err)
+		}
 
-	return nil
+		if non200err == nil {
+			if partial != nil {
+				return partial
+			}
+			return nil
+		}
+
+		if !authRetried && retryAuthError(c.TokenSource(), &c.signer, non200err, p.AccessToken()) {
+			// retry once with a new token or a corrected clock
+			authRetried = true
+			continue
+		}
+
+		d, ok := c.retryPolicy.retryDelay(attempt, method, non200err, time.Now())
+		if !ok {
+			return wrapWithAPIErr(non200err)
+		}
+
+		if err := sleepContext(ctx, d); err != nil {
+			return wrapErr(err)
+		}
+	}
 }
 
 var okCodes map[int]struct{} = map[int]struct{}{
@@ -259,37 +558,85 @@ var okCodes map[int]struct{} = map[int]struct{}{
 }
 
 func (c *Client) Exec(req *http.Request, i util.Response) (*resources.Non2XXError, error) {
-	res, err := c.Client.Do(req)
+	non200err, _, err := c.exec(req, i, false)
+	return non200err, err
+}
+
+// exec is Exec that also returns the partial errors of a successful response
+// if collectPartial is true and i has any.
+func (c *Client) exec(req *http.Request, i util.Response, collectPartial bool) (*resources.Non2XXError, *PartialErrors, error) {
+	res, non200err, err := c.do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer res.Body.Close()
 
-	if _, ok := okCodes[res.StatusCode]; !ok {
-		non200err, err := resolveNon2XXResponse(res)
-		if err != nil {
-			return nil, err
-		}
-		return non200err, nil
+	if non200err != nil {
+		return non200err, nil, nil
 	}
+	defer res.Body.Close()
 
-	var tr io.Reader
-	debugBuf := new(bytes.Buffer)
-	if c.debug {
-		tr = io.TeeReader(res.Body, debugBuf)
-	} else {
-		tr = res.Body
+	logger := c.Logger()
+	var tr io.Reader = res.Body
+	logBody := logger != nil && logger.Enabled(req.Context(), slog.LevelDebug)
+	bodyBuf := new(bytes.Buffer)
+	if logBody || collectPartial {
+		tr = io.TeeReader(res.Body, bodyBuf)
 	}
 
 	jerr := json.NewDecoder(tr).Decode(i)
-	if c.debug {
-		fmt.Printf("------DEBUG------\n[request url]\n%v\n[response header]\n%v\n[response body]\n%s\n------DEBUG END------\n", req.URL, res.Header, debugBuf.String())
+	if logBody {
+		logResponseBody(req.Context(), logger, req, bodyBuf.Bytes())
 	}
 	if jerr != nil && jerr != io.EOF {
-		return nil, jerr
+		return nil, nil, jerr
+	}
+
+	if !collectPartial || !i.HasPartialError() {
+		return nil, nil, nil
+	}
+
+	// The outputs have no common accessor for their errors, so they are decoded again.
+	pe := struct {
+		Errors []resources.PartialError `json:"errors"`
+	}{}
+	if err := json.Unmarshal(bodyBuf.Bytes(), &pe); err != nil {
+		return nil, nil, err
+	}
+	if len(pe.Errors) == 0 {
+		return nil, nil, nil
+	}
+
+	return nil, NewPartialErrors(pe.Errors), nil
+}
+
+// do sends req through the middleware chain. If there is no error,
+// the caller must close the body of the returned response.
+func (c *Client) do(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+	exec := chainMiddlewares(execHTTP(c.Client, &c.rateLimits, c.rateLimiter, &c.signer), buildMiddlewares(c.Middlewares(), c.instrumentation, c.Logger(), false))
+	res, non200err, err := exec(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if non200err != nil {
+		return nil, non200err, nil
+	}
+
+	if res == nil {
+		return nil, nil, errors.New("HTTP Response is nil.")
+	}
+
+	if _, ok := okCodes[res.StatusCode]; !ok {
+		// a middleware returned a response without resolving the error
+		defer res.Body.Close()
+		non200err, err := resolveNon2XXResponse(res)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, non200err, nil
 	}
 
-	return nil, nil
+	return res, nil, nil
 }
 
 func prepare(ctx context.Context, endpointBase, method string, p util.Parameters, c IClient) (*http.Request, error) {
@@ -301,17 +648,29 @@ func prepare(ctx context.Context, endpointBase, method string, p util.Parameters
 		return nil, fmt.Errorf(gotwierrors.ErrorClientNotReady)
 	}
 
-	endpoint := p.ResolveEndpoint(endpointBase)
-	p.SetAccessToken(c.AccessToken())
+	// one snapshot, so that a concurrent rotation is not seen half way
+	cr := credentialsOf(c)
+
+	ctx = withEndpoint(ctx, endpointBase)
+	endpoint := resolveURL(baseURLOf(c), p.ResolveEndpoint(endpointBase))
+	if cr.AuthenticationMethod == AuthenMethodOAuth2BearerToken {
+		token, err := requestAccessToken(ctx, c, cr.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+		p.SetAccessToken(token)
+	} else {
+		p.SetAccessToken(cr.AccessToken)
+	}
 	req, err := newRequest(ctx, endpoint, method, p)
 	if err != nil {
 		return nil, err
 	}
 
-	switch c.AuthenticationMethod() {
+	switch cr.AuthenticationMethod {
 	case AuthenMethodOAuth1UserContext:
 		pm := p.ParameterMap()
-		req, err = setOAuth1Header(req, pm, c)
+		req, err = signOAuth1Request(req, pm, cr.OAuthConsumerKey, cr.OAuthToken, cr.SigningKey, signerOf(c))
 		if err != nil {
 			return nil, err
 		}
@@ -322,31 +681,94 @@ func prepare(ctx context.Context, endpointBase, method string, p util.Parameters
 	return req, nil
 }
 
-const oauth1header = `OAuth oauth_consumer_key="%s",oauth_nonce="%s",oauth_signature="%s",oauth_signature_method="%s",oauth_timestamp="%s",oauth_token="%s",oauth_version="%s"`
+// ValidBaseURL reports whether v can be used as a base URL. An empty value is valid
+// and means DefaultBaseURL.
+func ValidBaseURL(v string) bool {
+	if v == "" {
+		return true
+	}
+
+	u, err := url.Parse(v)
+	if err != nil {
+		return false
+	}
+
+	return u.Scheme != "" && u.Host != "" && u.RawQuery == "" && u.Fragment == ""
+}
+
+type baseURLHolder interface {
+	BaseURL() string
+}
+
+// baseURLOf returns the base URL of c, or DefaultBaseURL if c has none.
+func baseURLOf(c IClient) string {
+	if h, ok := c.(baseURLHolder); ok {
+		if u := h.BaseURL(); u != "" {
+			return u
+		}
+	}
+
+	return DefaultBaseURL
+}
+
+// resolveURL joins an endpoint path such as "/2/tweets" to the base URL.
+// Endpoints that are already absolute URLs (or empty) are returned as is.
+func resolveURL(baseURL, endpoint string) string {
+	if !strings.HasPrefix(endpoint, "/") {
+		return endpoint
+	}
+
+	return strings.TrimRight(baseURL, "/") + endpoint
+}
+
+const (
+	oauth1header             = `OAuth oauth_consumer_key="%s",oauth_nonce="%s",oauth_signature="%s",oauth_signature_method="%s",oauth_timestamp="%s",oauth_token="%s",oauth_version="%s"`
+	oauth1headerWithoutToken = `OAuth oauth_consumer_key="%s",oauth_nonce="%s",oauth_signature="%s",oauth_signature_method="%s",oauth_timestamp="%s",oauth_version="%s"`
+)
 
 // setOAuth1Header returns http.Request with the header information required for OAuth1.0a authentication.
 func setOAuth1Header(r *http.Request, paramsMap map[string]string, c IClient) (*http.Request, error) {
+	cr := credentialsOf(c)
+	return signOAuth1Request(r, paramsMap, cr.OAuthConsumerKey, cr.OAuthToken, cr.SigningKey, signerOf(c))
+}
+
+// signOAuth1Request adds the OAuth 1.0a Authorization header to r. oauthToken is empty
+// when obtaining a request token, and then it is omitted from the header. s may be nil.
+func signOAuth1Request(r *http.Request, paramsMap map[string]string, consumerKey, oauthToken, signingKey string, s *oauth1Signer) (*http.Request, error) {
 	in := &CreateOAuthSignatureInput{
 		HTTPMethod:       r.Method,
 		RawEndpoint:      r.URL.String(),
-		OAuthConsumerKey: c.OAuthConsumerKey(),
-		OAuthToken:       c.OAuthToken(),
-		SigningKey:       c.SigningKey(),
+		OAuthConsumerKey: consumerKey,
+		OAuthToken:       oauthToken,
+		SigningKey:       signingKey,
 		ParameterMap:     paramsMap,
 	}
+	s.apply(in)
 
 	out, err := CreateOAuthSignature(in)
 	if err != nil {
 		return nil, err
 	}
 
+	if oauthToken == "" {
+		r.Header.Add("Authorization", fmt.Sprintf(oauth1headerWithoutToken,
+			url.QueryEscape(consumerKey),
+			url.QueryEscape(out.OAuthNonce),
+			url.QueryEscape(out.OAuthSignature),
+			url.QueryEscape(out.OAuthSignatureMethod),
+			url.QueryEscape(out.OAuthTimestamp),
+			url.QueryEscape(out.OAuthVersion),
+		))
+		return r, nil
+	}
+
 	r.Header.Add("Authorization", fmt.Sprintf(oauth1header,
-		url.QueryEscape(c.OAuthConsumerKey()),
+		url.QueryEscape(consumerKey),
 		url.QueryEscape(out.OAuthNonce),
 		url.QueryEscape(out.OAuthSignature),
 		url.QueryEscape(out.OAuthSignatureMethod),
 		url.QueryEscape(out.OAuthTimestamp),
-		url.QueryEscape(c.OAuthToken()),
+		url.QueryEscape(oauthToken),
 		url.QueryEscape(out.OAuthVersion),
 	))
 
@@ -43,6 +43,7 @@ type gotwiClientField struct {
 	OAuthToken           string
 	OAuthConsumerKey     string
 	SigningKey           string
+	BaseURL              string
 	Client               *http.Client
 }
 
@@ -162,6 +163,18 @@ func Test_NewClient(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:            "error: invalid base url",
+			envAPIKey:       "api-key",
+			envAPIKeySecret: "api-key-secret",
+			in: &gotwi.NewClientInput{
+				AuthenticationMethod: gotwi.AuthenMethodOAuth1UserContext,
+				OAuthToken:           "oauth-token",
+				OAuthTokenSecret:     "oauth-token-secret",
+				BaseURL:              "api.example.com",
+			},
+			wantErr: true,
+		},
 		{
 			name:            "error: api key is empty",
 			envAPIKey:       "",
@@ -277,11 +290,33 @@ func Test_NewClientWithAccessToken(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "ok: with base url",
+			in: &gotwi.NewClientWithAccessTokenInput{
+				AccessToken: "test-token",
+				BaseURL:     "https://api.x.com",
+			},
+			wantErr: false,
+			expect: gotwiClientField{
+				AuthenticationMethod: gotwi.AuthenMethodOAuth2BearerToken,
+				AccessToken:          "test-token",
+				BaseURL:              "https://api.x.com",
+				Client:               defaultHTTPClient,
+			},
+		},
 		{
 			name:    "error: access token is empty",
 			in:      &gotwi.NewClientWithAccessTokenInput{},
 			wantErr: true,
 		},
+		{
+			name: "error: invalid base url",
+			in: &gotwi.NewClientWithAccessTokenInput{
+				AccessToken: "test-token",
+				BaseURL:     "://api.x.com",
+			},
+			wantErr: true,
+		},
 		{
 			name:    "error: access token is empty",
 			in:      nil,
@@ -300,6 +335,11 @@ func Test_NewClientWithAccessToken(t *testing.T) {
 
 			assert.NoError(tt, err)
 			assert.Equal(tt, c.expect.Client, gc.Client)
+			if c.expect.BaseURL != "" {
+				assert.Equal(tt, c.expect.BaseURL, gc.BaseURL())
+			} else {
+				assert.Equal(tt, gotwi.DefaultBaseURL, gc.BaseURL())
+			}
 			assert.Equal(tt, c.expect.AuthenticationMethod, gc.AuthenticationMethod())
 			assert.Equal(tt, c.expect.AccessToken, gc.AccessToken())
 			assert.Equal(tt, c.expect.OAuthToken, gc.OAuthToken())
@@ -309,6 +349,53 @@ func Test_NewClientWithAccessToken(t *testing.T) {
 	}
 }
 
+func Test_NewUnauthenticatedClient(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      *gotwi.NewUnauthenticatedClientInput
+		wantErr bool
+		baseURL string
+	}{
+		{
+			name:    "ok",
+			in:      &gotwi.NewUnauthenticatedClientInput{},
+			baseURL: gotwi.DefaultBaseURL,
+		},
+		{
+			name:    "ok: with base url",
+			in:      &gotwi.NewUnauthenticatedClientInput{BaseURL: "https://api.x.com"},
+			baseURL: "https://api.x.com",
+		},
+		{
+			name:    "error: invalid base url",
+			in:      &gotwi.NewUnauthenticatedClientInput{BaseURL: "://api.x.com"},
+			wantErr: true,
+		},
+		{
+			name:    "error: nil",
+			in:      nil,
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			gc, err := gotwi.NewUnauthenticatedClient(c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(gc)
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal(c.baseURL, gc.BaseURL())
+			asst.False(gc.IsReady())
+		})
+	}
+}
+
 func Test_IsReady(t *testing.T) {
 	cases := []struct {
 		name   string
@@ -594,6 +681,103 @@ func Test_CallAPI(t *testing.T) {
 	}
 }
 
+func Test_CallAPI_BaseURL(t *testing.T) {
+	cases := []struct {
+		name     string
+		baseURL  string
+		endpoint string
+		expect   string
+	}{
+		{
+			name:     "ok: default base url",
+			endpoint: "/2/tweets",
+			expect:   "https://api.twitter.com/2/tweets",
+		},
+		{
+			name:     "ok: custom base url",
+			baseURL:  "http://127.0.0.1:8080",
+			endpoint: "/2/tweets",
+			expect:   "http://127.0.0.1:8080/2/tweets",
+		},
+		{
+			name:     "ok: custom base url with path prefix",
+			baseURL:  "https://proxy.example.com/twitter/",
+			endpoint: "/2/tweets",
+			expect:   "https://proxy.example.com/twitter/2/tweets",
+		},
+		{
+			name:     "ok: absolute endpoint is not resolved",
+			baseURL:  "https://api.x.com",
+			endpoint: "https://api.twitter.com/2/tweets",
+			expect:   "https://api.twitter.com/2/tweets",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			var requested string
+			mockClient := newMockClient(func(req *http.Request) *http.Response {
+				requested = req.URL.String()
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{}`)),
+				}
+			})
+
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient:  mockClient,
+				AccessToken: "token",
+				BaseURL:     c.baseURL,
+			})
+			assert.NoError(tt, err)
+
+			err = client.CallAPI(context.Background(), c.endpoint, http.MethodGet, &testParameter{}, &mockAPIResponse{})
+			assert.NoError(tt, err)
+			assert.Equal(tt, c.expect, requested)
+		})
+	}
+}
+
+func Test_resolveURL(t *testing.T) {
+	cases := []struct {
+		name     string
+		baseURL  string
+		endpoint string
+		expect   string
+	}{
+		{
+			name:     "ok: path",
+			baseURL:  "https://api.twitter.com",
+			endpoint: "/2/users/123?user.fields=id",
+			expect:   "https://api.twitter.com/2/users/123?user.fields=id",
+		},
+		{
+			name:     "ok: base url with trailing slash",
+			baseURL:  "https://api.twitter.com/",
+			endpoint: "/2/users",
+			expect:   "https://api.twitter.com/2/users",
+		},
+		{
+			name:     "ok: absolute url",
+			baseURL:  "https://api.twitter.com",
+			endpoint: "https://example.com/2/users",
+			expect:   "https://example.com/2/users",
+		},
+		{
+			name:     "ok: empty endpoint",
+			baseURL:  "https://api.twitter.com",
+			endpoint: "",
+			expect:   "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			assert.Equal(tt, c.expect, gotwi.ExportResolveURL(c.baseURL, c.endpoint))
+		})
+	}
+}
+
 func Test_Exec(t *testing.T) {
 	nonErrReq, _ := http.NewRequestWithContext(context.TODO(), "GET", "https://example.com", nil)
 	errReq := &http.Request{Method: "invalid method"}
@@ -0,0 +1,124 @@
+package gotwi
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/xxiiaaon/gotwi/resources"
+)
+
+const errorCodeTimestampOutOfBounds resources.ErrorCode = 135
+
+// oauth1Signer holds the clock and the nonce source for OAuth 1.0a signing,
+// and the offset of the clock from the API server's clock.
+// The zero value uses the system clock and crypto/rand.
+type oauth1Signer struct {
+	clock       Clock
+	nonceSource NonceSource
+
+	// offset is the server time minus the local time in nanoseconds,
+	// learned after the API rejected a timestamp.
+	offset atomic.Int64
+
+	// learnedFrom is the rejection the offset was last learned from,
+	// so that only a request whose response corrected the clock is retried.
+	learnedFrom atomic.Pointer[resources.Non2XXError]
+}
+
+// Now returns the time of the clock corrected by the learned offset.
+func (s *oauth1Signer) Now() time.Time {
+	now := time.Now()
+	if s.clock != nil {
+		now = s.clock.Now()
+	}
+	return now.Add(time.Duration(s.offset.Load()))
+}
+
+// apply sets the clock and the nonce source of the signer to in.
+func (s *oauth1Signer) apply(in *CreateOAuthSignatureInput) {
+	if s == nil {
+		return
+	}
+	in.Clock = s
+	in.NonceSource = s.nonceSource
+}
+
+// observe learns the clock offset from the Date header of res
+// if the API rejected the timestamp of the request.
+func (s *oauth1Signer) observe(res *http.Response, e *resources.Non2XXError) {
+	if s == nil || res == nil || !timestampOutOfBounds(e) {
+		return
+	}
+
+	date, err := http.ParseTime(res.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+
+	local := time.Now()
+	if s.clock != nil {
+		local = s.clock.Now()
+	}
+	s.offset.Store(int64(date.Sub(local)))
+	s.learnedFrom.Store(e)
+}
+
+// learned reports whether the offset was learned from e, and forgets e.
+func (s *oauth1Signer) learned(e *resources.Non2XXError) bool {
+	return s != nil && e != nil && s.learnedFrom.CompareAndSwap(e, nil)
+}
+
+// timestampOutOfBounds reports whether e is the 401 error the API returns
+// when the OAuth 1.0a timestamp is too far from the server time.
+func timestampOutOfBounds(e *resources.Non2XXError) bool {
+	if e == nil || e.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+
+	for _, ae := range e.APIErrors {
+		if ae.Code == errorCodeTimestampOutOfBounds {
+			return true
+		}
+	}
+
+	return false
+}
+
+type signerHolder interface {
+	oauth1Signer() *oauth1Signer
+}
+
+// signerOf returns the OAuth 1.0a signer of c, or nil if c has none.
+func signerOf(c IClient) *oauth1Signer {
+	if h, ok := c.(signerHolder); ok {
+		return h.oauth1Signer()
+	}
+	return nil
+}
+
+func (c *Client) oauth1Signer() *oauth1Signer {
+	if c == nil {
+		return nil
+	}
+	return &c.signer
+}
+
+// retryAuthError reports whether a request rejected with e should be retried once, because
+// the token source replaced the rejected token or the signer corrected its clock.
+func retryAuthError(ts TokenSource, s *oauth1Signer, e *resources.Non2XXError, token string) bool {
+	if invalidateRejectedToken(ts, e, token) {
+		return true
+	}
+
+	return s.learned(e)
+}
+
+// ClockOffset returns the correction applied to the clock for OAuth 1.0a timestamps.
+// It is learned from the Date header of the API after it rejected a timestamp.
+func (c *Client) ClockOffset() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return time.Duration(c.signer.offset.Load())
+}
@@ -0,0 +1,104 @@
+package gotwi_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/user/userlookup"
+	"github.com/xxiiaaon/gotwi/user/userlookup/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CallAPI_ClockSkew(t *testing.T) {
+	serverTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name         string
+		localTime    time.Time
+		noDateHeader bool
+		wantErr      bool
+		expectCalls  int
+		expectOffset time.Duration
+	}{
+		{
+			name:        "ok: clock in sync",
+			localTime:   serverTime,
+			expectCalls: 1,
+		},
+		{
+			name:         "ok: clock behind",
+			localTime:    serverTime.Add(-time.Hour),
+			expectCalls:  2,
+			expectOffset: time.Hour,
+		},
+		{
+			name:         "ok: clock ahead",
+			localTime:    serverTime.Add(time.Duration(10) * time.Minute),
+			expectCalls:  2,
+			expectOffset: -time.Duration(10) * time.Minute,
+		},
+		{
+			name:         "error: no date header",
+			localTime:    serverTime.Add(-time.Hour),
+			noDateHeader: true,
+			wantErr:      true,
+			expectCalls:  1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			calls := 0
+			client, err := gotwi.NewClient(&gotwi.NewClientInput{
+				HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+					calls++
+
+					hp, err := parseOAuth1Header(req.Header.Get("Authorization"))
+					asst.NoError(err)
+					asst.Equal("fixed-nonce", hp["oauth_nonce"])
+
+					ts, _ := strconv.ParseInt(hp["oauth_timestamp"], 10, 64)
+					if d := serverTime.Sub(time.Unix(ts, 0)); d > time.Duration(5)*time.Minute || d < -time.Duration(5)*time.Minute {
+						header := map[string][]string{"Content-Type": {"application/json"}}
+						if !c.noDateHeader {
+							header["Date"] = []string{serverTime.Format(http.TimeFormat)}
+						}
+						return &http.Response{
+							StatusCode: http.StatusUnauthorized,
+							Header:     header,
+							Body:       io.NopCloser(strings.NewReader(`{"errors":[{"code":135,"message":"Timestamp out of bounds."}]}`)),
+						}
+					}
+
+					return newMockResponse(http.StatusOK, `{}`)
+				}),
+				AuthenticationMethod: gotwi.AuthenMethodOAuth1UserContext,
+				OAuthToken:           "token",
+				OAuthTokenSecret:     "secret",
+				APIKey:               testConsumerKey,
+				APIKeySecret:         testConsumerSecret,
+				Clock:                gotwi.ClockFunc(func() time.Time { return c.localTime }),
+				NonceSource:          gotwi.NonceSourceFunc(func() (string, error) { return "fixed-nonce", nil }),
+			})
+			asst.NoError(err)
+
+			_, err = userlookup.GetMe(context.Background(), client, &types.GetMeInput{})
+			if c.wantErr {
+				asst.Error(err)
+			} else {
+				asst.NoError(err)
+			}
+
+			asst.Equal(c.expectCalls, calls)
+			asst.Equal(c.expectOffset, client.ClockOffset())
+		})
+	}
+}
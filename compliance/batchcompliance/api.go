@@ -8,9 +8,9 @@ import (
 )
 
 const (
-	listJobsEndpoint  = "https://api.twitter.com/2/compliance/jobs"
-	GetJobEndpoint    = "https://api.twitter.com/2/compliance/jobs/:id"
-	createJobEndpoint = "https://api.twitter.com/2/compliance/jobs"
+	listJobsEndpoint  = "/2/compliance/jobs"
+	GetJobEndpoint    = "/2/compliance/jobs/:id"
+	createJobEndpoint = "/2/compliance/jobs"
 )
 
 // Returns a list of recent compliance jobs.
@@ -18,6 +18,9 @@ const (
 func ListJobs(ctx context.Context, c *gotwi.Client, p *types.ListJobsInput) (*types.ListJobsOutput, error) {
 	res := &types.ListJobsOutput{}
 	if err := c.CallAPI(ctx, listJobsEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -29,6 +32,9 @@ func ListJobs(ctx context.Context, c *gotwi.Client, p *types.ListJobsInput) (*ty
 func GetJob(ctx context.Context, c *gotwi.Client, p *types.GetJobInput) (*types.GetJobOutput, error) {
 	res := &types.GetJobOutput{}
 	if err := c.CallAPI(ctx, GetJobEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -43,6 +49,9 @@ func GetJob(ctx context.Context, c *gotwi.Client, p *types.GetJobInput) (*types.
 func CreateJob(ctx context.Context, c *gotwi.Client, p *types.CreateJobInput) (*types.CreateJobOutput, error) {
 	res := &types.CreateJobOutput{}
 	if err := c.CallAPI(ctx, createJobEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -0,0 +1,92 @@
+package gotwi
+
+import "sync/atomic"
+
+// Credentials is a snapshot of the credentials a client authenticates requests with.
+type Credentials struct {
+	AuthenticationMethod AuthenticationMethod
+	AccessToken          string
+	OAuthToken           string
+	OAuthConsumerKey     string
+	SigningKey           string
+}
+
+// CredentialProvider holds the live credentials of a Client, shared with every TypedClient
+// created from it. Every request reads one consistent snapshot without taking a lock,
+// and Rotate replaces all credentials at once. The zero value holds no credentials.
+type CredentialProvider struct {
+	current atomic.Pointer[Credentials]
+}
+
+// Credentials returns the current credentials.
+func (p *CredentialProvider) Credentials() Credentials {
+	if p == nil {
+		return Credentials{}
+	}
+
+	if c := p.current.Load(); c != nil {
+		return *c
+	}
+
+	return Credentials{}
+}
+
+// Rotate replaces all credentials. Requests prepared afterwards use the new credentials.
+func (p *CredentialProvider) Rotate(c Credentials) {
+	p.current.Store(&c)
+}
+
+// update changes some fields of the current credentials without losing a concurrent update.
+func (p *CredentialProvider) update(fn func(c *Credentials)) {
+	for {
+		old := p.current.Load()
+		next := Credentials{}
+		if old != nil {
+			next = *old
+		}
+		fn(&next)
+
+		if p.current.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+type credentialHolder interface {
+	CredentialProvider() *CredentialProvider
+}
+
+// credentialsOf returns one consistent snapshot of the credentials of c.
+func credentialsOf(c IClient) Credentials {
+	if h, ok := c.(credentialHolder); ok {
+		return h.CredentialProvider().Credentials()
+	}
+
+	return Credentials{
+		AuthenticationMethod: c.AuthenticationMethod(),
+		AccessToken:          c.AccessToken(),
+		OAuthToken:           c.OAuthToken(),
+		OAuthConsumerKey:     c.OAuthConsumerKey(),
+		SigningKey:           c.SigningKey(),
+	}
+}
+
+// credentialsReady reports whether cr has everything its authentication method needs.
+func credentialsReady(cr Credentials, ts TokenSource) bool {
+	if !cr.AuthenticationMethod.Valid() {
+		return false
+	}
+
+	switch cr.AuthenticationMethod {
+	case AuthenMethodOAuth1UserContext:
+		if cr.OAuthToken == "" || cr.SigningKey == "" {
+			return false
+		}
+	case AuthenMethodOAuth2BearerToken:
+		if cr.AccessToken == "" && ts == nil {
+			return false
+		}
+	}
+
+	return true
+}
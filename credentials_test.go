@@ -0,0 +1,150 @@
+package gotwi_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/user/userlookup"
+	"github.com/xxiiaaon/gotwi/user/userlookup/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CredentialProvider(t *testing.T) {
+	asst := assert.New(t)
+
+	var p *gotwi.CredentialProvider
+	asst.Equal(gotwi.Credentials{}, p.Credentials())
+
+	p = &gotwi.CredentialProvider{}
+	asst.Equal(gotwi.Credentials{}, p.Credentials())
+
+	cr := gotwi.Credentials{AuthenticationMethod: gotwi.AuthenMethodOAuth2BearerToken, AccessToken: "token"}
+	p.Rotate(cr)
+	asst.Equal(cr, p.Credentials())
+}
+
+func Test_Client_Setters_Concurrent(t *testing.T) {
+	asst := assert.New(t)
+
+	c, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{AccessToken: "token"})
+	asst.NoError(err)
+
+	var wg sync.WaitGroup
+	setters := []func(){
+		func() { c.SetAccessToken("new-token") },
+		func() { c.SetOAuthToken("oauth-token") },
+		func() { c.SetOAuthConsumerKey("consumer-key") },
+		func() { c.SetSigningKey("signing-key") },
+	}
+	for _, set := range setters {
+		wg.Add(1)
+		go func(set func()) {
+			defer wg.Done()
+			set()
+		}(set)
+	}
+	wg.Wait()
+
+	// no update is lost
+	asst.Equal(gotwi.Credentials{
+		AuthenticationMethod: gotwi.AuthenMethodOAuth2BearerToken,
+		AccessToken:          "new-token",
+		OAuthToken:           "oauth-token",
+		OAuthConsumerKey:     "consumer-key",
+		SigningKey:           "signing-key",
+	}, c.CredentialProvider().Credentials())
+}
+
+func Test_TypedClient_SharesCredentials(t *testing.T) {
+	asst := assert.New(t)
+
+	auth := []string{}
+	c, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			auth = append(auth, req.Header.Get("Authorization"))
+			return newMockResponse(http.StatusOK, `{"text":"hello"}`)
+		}),
+		AccessToken: "old-token",
+	})
+	asst.NoError(err)
+
+	tc := gotwi.NewTypedClient[*gotwi.MockResponse](c)
+	asst.Equal(c.CredentialProvider(), tc.CredentialProvider())
+
+	c.CredentialProvider().Rotate(gotwi.Credentials{
+		AuthenticationMethod: gotwi.AuthenMethodOAuth2BearerToken,
+		AccessToken:          "new-token",
+	})
+	asst.Equal("new-token", tc.AccessToken())
+
+	s, err := tc.CallStreamAPI(context.Background(), "/2/tweets/search/stream", http.MethodGet, &types.GetMeInput{})
+	asst.NoError(err)
+	defer s.Stop()
+
+	c.SetAccessToken("newer-token")
+	_, err = userlookup.GetMe(context.Background(), c, &types.GetMeInput{})
+	asst.NoError(err)
+
+	asst.Equal([]string{"Bearer new-token", "Bearer newer-token"}, auth)
+}
+
+func Test_CallAPI_RotateConcurrent(t *testing.T) {
+	asst := assert.New(t)
+
+	secrets := map[string]string{"token-a": "secret-a", "token-b": "secret-b"}
+	var mu sync.Mutex
+	mismatches := 0
+	c, err := gotwi.NewClient(&gotwi.NewClientInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			hp, err := parseOAuth1Header(req.Header.Get("Authorization"))
+			if err == nil {
+				_, err = verifyOAuth1Signature(req, secrets[hp["oauth_token"]])
+			}
+			if err != nil {
+				mu.Lock()
+				mismatches++
+				mu.Unlock()
+			}
+			return newMockResponse(http.StatusOK, `{}`)
+		}),
+		AuthenticationMethod: gotwi.AuthenMethodOAuth1UserContext,
+		OAuthToken:           "token-a",
+		OAuthTokenSecret:     "secret-a",
+		APIKey:               testConsumerKey,
+		APIKeySecret:         testConsumerSecret,
+	})
+	asst.NoError(err)
+
+	rotate := func(token string) {
+		c.CredentialProvider().Rotate(gotwi.Credentials{
+			AuthenticationMethod: gotwi.AuthenMethodOAuth1UserContext,
+			OAuthToken:           token,
+			OAuthConsumerKey:     testConsumerKey,
+			SigningKey:           url.QueryEscape(testConsumerSecret) + "&" + url.QueryEscape(secrets[token]),
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = userlookup.GetMe(context.Background(), c, &types.GetMeInput{})
+		}()
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				rotate("token-b")
+			} else {
+				rotate("token-a")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	asst.Equal(0, mismatches)
+}
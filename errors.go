@@ -3,12 +3,95 @@ package gotwi
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/xxiiaaon/gotwi/internal/gotwierrors"
 	"github.com/xxiiaaon/gotwi/resources"
 )
 
+// Sentinel errors to be matched with errors.Is against the errors returned by gotwi.
+// An error may match several of them, e.g. a duplicate Tweet is also ErrForbidden.
+var (
+	ErrRateLimited      = errors.New("Rate limit exceeded.")
+	ErrUnauthorized     = errors.New("Unauthorized.")
+	ErrForbidden        = errors.New("Forbidden.")
+	ErrNotFound         = errors.New("Not found.")
+	ErrDuplicateStatus  = errors.New("Status is a duplicate.")
+	ErrAlreadyFollowing = errors.New("Already requested to follow the user.")
+	ErrSuspended        = errors.New("Account is suspended.")
+)
+
+// problemTypeBase is the base of the Type URI of the problem details returned by the v2 API.
+const problemTypeBase = "https://api.twitter.com/2/problems/"
+
+// apiErrorMatcher describes the API errors a sentinel error matches.
+type apiErrorMatcher struct {
+	statusCodes  []int
+	errorCodes   []resources.ErrorCode
+	problemTypes []string
+
+	// detail matches a substring of the problem detail, for v2 errors without an error code.
+	detail string
+}
+
+var sentinelMatchers = map[error]apiErrorMatcher{
+	ErrRateLimited: {
+		statusCodes:  []int{http.StatusTooManyRequests},
+		errorCodes:   []resources.ErrorCode{88},
+		problemTypes: []string{"usage-capped"},
+	},
+	ErrUnauthorized: {
+		statusCodes:  []int{http.StatusUnauthorized},
+		errorCodes:   []resources.ErrorCode{32, 89, 135},
+		problemTypes: []string{"unsupported-authentication"},
+	},
+	ErrForbidden: {
+		statusCodes:  []int{http.StatusForbidden},
+		problemTypes: []string{"client-forbidden", "not-authorized-for-resource"},
+	},
+	ErrNotFound: {
+		statusCodes:  []int{http.StatusNotFound},
+		errorCodes:   []resources.ErrorCode{34, 50, 144},
+		problemTypes: []string{"resource-not-found"},
+	},
+	ErrDuplicateStatus: {
+		errorCodes: []resources.ErrorCode{187},
+		detail:     "duplicate content",
+	},
+	ErrAlreadyFollowing: {
+		errorCodes: []resources.ErrorCode{160},
+	},
+	ErrSuspended: {
+		errorCodes: []resources.ErrorCode{63, 64},
+	},
+}
+
+func (m apiErrorMatcher) match(e *resources.Non2XXError) bool {
+	for _, sc := range m.statusCodes {
+		if e.StatusCode == sc {
+			return true
+		}
+	}
+
+	for _, ae := range e.APIErrors {
+		for _, code := range m.errorCodes {
+			if ae.Code == code {
+				return true
+			}
+		}
+	}
+
+	for _, pt := range m.problemTypes {
+		if e.Type == problemTypeBase+pt {
+			return true
+		}
+	}
+
+	return m.detail != "" && strings.Contains(e.Detail, m.detail)
+}
+
 type GotwiError struct {
 	err   error
 	OnAPI bool
@@ -38,6 +121,12 @@ func wrapWithAPIErr(n2xxerr *resources.Non2XXError) *GotwiError {
 	}
 }
 
+// WrapAPIError returns a GotwiError for the Non2XXError returned by IClient.Exec.
+// It is nil if n2xxerr is nil.
+func WrapAPIError(n2xxerr *resources.Non2XXError) *GotwiError {
+	return wrapWithAPIErr(n2xxerr)
+}
+
 func (e *GotwiError) Error() string {
 	if e == nil {
 		return ""
@@ -50,6 +139,20 @@ func (e *GotwiError) Error() string {
 	return gotwierrors.ErrorUndefined
 }
 
+// Is reports whether the API error matches target, one of the sentinel errors such as ErrNotFound.
+func (e *GotwiError) Is(target error) bool {
+	if e == nil || !e.OnAPI {
+		return false
+	}
+
+	m, ok := sentinelMatchers[target]
+	if !ok {
+		return false
+	}
+
+	return m.match(&e.Non2XXError)
+}
+
 func (e *GotwiError) Unwrap() error {
 	if e == nil {
 		return nil
@@ -75,6 +178,12 @@ func non2XXErrorSummary(e *resources.Non2XXError) string {
 	if e.Detail != "" {
 		summary = append(summary, fmt.Sprintf("detail=\"%s\"", e.Detail))
 	}
+	if e.OAuthError != "" {
+		summary = append(summary, fmt.Sprintf("error=\"%s\"", e.OAuthError))
+	}
+	if e.OAuthErrorDescription != "" {
+		summary = append(summary, fmt.Sprintf("errorDescription=\"%s\"", e.OAuthErrorDescription))
+	}
 
 	ercnt := 1
 	for _, er := range e.APIErrors {
@@ -90,3 +199,44 @@ func non2XXErrorSummary(e *resources.Non2XXError) string {
 
 	return strings.Join(summary, " ")
 }
+
+// IsRetryable reports whether err is a rate limit or a temporary server error,
+// so that the same request may succeed later.
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
+	var ge *GotwiError
+	if errors.As(err, &ge) && ge.OnAPI {
+		return temporaryError(&ge.Non2XXError)
+	}
+
+	return false
+}
+
+// RetryAfter returns how long to wait before retrying a request that failed with err
+// because of the rate limit. It returns false if err has no rate limit reset time.
+func RetryAfter(err error) (time.Duration, bool) {
+	var resetAt *time.Time
+
+	var rle *RateLimitExceededError
+	var ge *GotwiError
+	switch {
+	case errors.As(err, &rle):
+		resetAt = &rle.ResetAt
+	case errors.As(err, &ge) && ge.OnAPI && ge.RateLimitInfo != nil:
+		resetAt = ge.RateLimitInfo.ResetAt
+	}
+
+	if resetAt == nil {
+		return 0, false
+	}
+
+	d := time.Until(*resetAt)
+	if d < 0 {
+		d = 0
+	}
+
+	return d, true
+}
@@ -2,6 +2,7 @@ package gotwi_test
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -362,3 +363,206 @@ func Test_GotwiAccessNon2XXErrorFields(t *testing.T) {
 	a.Equal(20, ge.RateLimitInfo.Remaining)
 	a.Equal(resetAt, *ge.RateLimitInfo.ResetAt)
 }
+
+func Test_GotwiError_Is(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expect   []error
+		unexpect []error
+	}{
+		{
+			name:     "429",
+			err:      gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 429}),
+			expect:   []error{gotwi.ErrRateLimited},
+			unexpect: []error{gotwi.ErrUnauthorized, gotwi.ErrForbidden, gotwi.ErrNotFound},
+		},
+		{
+			name:   "usage capped problem",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 429, Type: "https://api.twitter.com/2/problems/usage-capped"}),
+			expect: []error{gotwi.ErrRateLimited},
+		},
+		{
+			name:     "401",
+			err:      gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 401}),
+			expect:   []error{gotwi.ErrUnauthorized},
+			unexpect: []error{gotwi.ErrForbidden, gotwi.ErrRateLimited},
+		},
+		{
+			name:   "invalid token code",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 403, APIErrors: []resources.ErrorInformation{{Code: 89}}}),
+			expect: []error{gotwi.ErrUnauthorized, gotwi.ErrForbidden},
+		},
+		{
+			name:     "not found",
+			err:      gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 404}),
+			expect:   []error{gotwi.ErrNotFound},
+			unexpect: []error{gotwi.ErrForbidden},
+		},
+		{
+			name:   "resource not found problem",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 400, Type: "https://api.twitter.com/2/problems/resource-not-found"}),
+			expect: []error{gotwi.ErrNotFound},
+		},
+		{
+			name:     "duplicate status code",
+			err:      gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 403, APIErrors: []resources.ErrorInformation{{Code: 187}}}),
+			expect:   []error{gotwi.ErrDuplicateStatus, gotwi.ErrForbidden},
+			unexpect: []error{gotwi.ErrAlreadyFollowing, gotwi.ErrSuspended},
+		},
+		{
+			name:   "duplicate status v2 detail",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 403, Detail: "You are not allowed to create a Tweet with duplicate content."}),
+			expect: []error{gotwi.ErrDuplicateStatus, gotwi.ErrForbidden},
+		},
+		{
+			name:     "already following",
+			err:      gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 403, APIErrors: []resources.ErrorInformation{{Code: 160}}}),
+			expect:   []error{gotwi.ErrAlreadyFollowing, gotwi.ErrForbidden},
+			unexpect: []error{gotwi.ErrDuplicateStatus},
+		},
+		{
+			name:   "suspended 63",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 403, APIErrors: []resources.ErrorInformation{{Code: 63}}}),
+			expect: []error{gotwi.ErrSuspended},
+		},
+		{
+			name:   "suspended 64",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 403, APIErrors: []resources.ErrorInformation{{Code: 64}}}),
+			expect: []error{gotwi.ErrSuspended},
+		},
+		{
+			name:   "wrapped by caller",
+			err:    fmt.Errorf("lookup: %w", gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 404})),
+			expect: []error{gotwi.ErrNotFound},
+		},
+		{
+			name:     "rate limiter",
+			err:      gotwi.ExportWrapErr(&gotwi.RateLimitExceededError{Endpoint: "GET /2/tweets", ResetAt: time.Now()}),
+			expect:   []error{gotwi.ErrRateLimited},
+			unexpect: []error{gotwi.ErrNotFound},
+		},
+		{
+			name:     "not an api error",
+			err:      gotwi.ExportWrapErr(errors.New("error test")),
+			unexpect: []error{gotwi.ErrRateLimited, gotwi.ErrUnauthorized, gotwi.ErrForbidden, gotwi.ErrNotFound},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			for _, e := range c.expect {
+				asst.ErrorIs(c.err, e)
+			}
+			for _, e := range c.unexpect {
+				asst.False(errors.Is(c.err, e), e.Error())
+			}
+		})
+	}
+}
+
+func Test_IsRetryable(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		expect bool
+	}{
+		{
+			name:   "429",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 429}),
+			expect: true,
+		},
+		{
+			name:   "503",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 503}),
+			expect: true,
+		},
+		{
+			name:   "over capacity",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 504, APIErrors: []resources.ErrorInformation{{Code: 130}}}),
+			expect: true,
+		},
+		{
+			name:   "rate limiter",
+			err:    gotwi.ExportWrapErr(&gotwi.RateLimitExceededError{ResetAt: time.Now()}),
+			expect: true,
+		},
+		{
+			name:   "400",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 400}),
+			expect: false,
+		},
+		{
+			name:   "not an api error",
+			err:    errors.New("error test"),
+			expect: false,
+		},
+		{
+			name:   "nil",
+			err:    nil,
+			expect: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			assert.Equal(tt, c.expect, gotwi.IsRetryable(c.err))
+		})
+	}
+}
+
+func Test_RetryAfter(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	cases := []struct {
+		name   string
+		err    error
+		wantOK bool
+		min    time.Duration
+		max    time.Duration
+	}{
+		{
+			name:   "429 with reset",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 429, RateLimitInfo: &util.RateLimitInformation{ResetAt: &future}}),
+			wantOK: true,
+			min:    time.Duration(59) * time.Minute,
+			max:    time.Hour,
+		},
+		{
+			name:   "reset in the past",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 429, RateLimitInfo: &util.RateLimitInformation{ResetAt: &past}}),
+			wantOK: true,
+		},
+		{
+			name:   "rate limiter",
+			err:    gotwi.ExportWrapErr(&gotwi.RateLimitExceededError{ResetAt: future}),
+			wantOK: true,
+			min:    time.Duration(59) * time.Minute,
+			max:    time.Hour,
+		},
+		{
+			name:   "429 without reset",
+			err:    gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: 429}),
+			wantOK: false,
+		},
+		{
+			name:   "not an api error",
+			err:    errors.New("error test"),
+			wantOK: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			d, ok := gotwi.RetryAfter(c.err)
+			asst.Equal(c.wantOK, ok)
+			asst.GreaterOrEqual(d, c.min)
+			asst.LessOrEqual(d, c.max)
+		})
+	}
+}
@@ -1,18 +1,31 @@
 package gotwi
 
+import (
+	"net/http"
+	"time"
+)
+
 type MockResponse struct {
 	Text string `json:"text"`
 }
 
 func (c *Client) SetDebugMode(d bool) {
-	c.debug = d
+	c.debugLogger = nil
+	if d {
+		c.debugLogger = newDebugLogger()
+	}
 }
 
 func (m *MockResponse) HasPartialError() bool { return true }
 
+func (l *RateLimiter) SetNow(now func() time.Time) {
+	l.now = now
+}
+
 var (
 	ExportNewRequest            = newRequest
 	ExportResolveNon2XXResponse = resolveNon2XXResponse
+	ExportResolveURL            = resolveURL
 
 	ExportGenerateOAthNonce     = generateOAthNonce
 	ExportEndpointBase          = endpointBase
@@ -24,5 +37,8 @@ var (
 	ExportWrapWithAPIErr     = wrapWithAPIErr
 	ExportNon2XXErrorSummary = non2XXErrorSummary
 
-	ExportNewStreamClient = newStreamClient[*MockResponse]
+	ExportNewStreamClient = func(res *http.Response) (*StreamClient[*MockResponse], error) {
+		return newStreamClient[*MockResponse](res, 0)
+	}
+	ExportNewStreamClientWithMaxMessageSize = newStreamClient[*MockResponse]
 )
@@ -0,0 +1,184 @@
+package gotwi
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/xxiiaaon/gotwi/internal/util"
+	"github.com/xxiiaaon/gotwi/resources"
+)
+
+// RequestInfo identifies a request for Instrumentation.
+type RequestInfo struct {
+	Method string
+
+	// Endpoint is the endpoint template, e.g. "/2/users/:id/tweets".
+	Endpoint string
+
+	// Stream is true for requests sent by TypedClient.ExecStream.
+	Stream bool
+}
+
+// RequestResult is the outcome of a request reported to Instrumentation.
+type RequestResult struct {
+	// StatusCode is 0 if no response was received.
+	StatusCode int
+
+	// Latency is the time until the response headers were received.
+	Latency time.Duration
+
+	// ErrorCodes are the codes of the API errors of a response with a status other than 2XX series.
+	ErrorCodes []resources.ErrorCode
+
+	// RateLimit is nil if the response has no x-rate-limit-* headers.
+	RateLimit *RateLimitInformation
+
+	// Err is the transport error, if any.
+	Err error
+}
+
+// Instrumentation receives metrics and tracing events from Client, TypedClient and StreamClient.
+// Implementations must be safe for concurrent use.
+type Instrumentation interface {
+	// RequestStarted is called before a request is sent. The returned context is
+	// attached to the request, so it can carry a trace span to the transport and
+	// to RequestFinished and StreamMessage.
+	RequestStarted(ctx context.Context, info RequestInfo) context.Context
+
+	// RequestFinished is called once the response headers were received or the request failed.
+	RequestFinished(ctx context.Context, info RequestInfo, result RequestResult)
+
+	// StreamMessage is called for every line read by StreamClient.Receive, with its size in bytes.
+	StreamMessage(ctx context.Context, info RequestInfo, bytes int)
+}
+
+// NopInstrumentation is an Instrumentation that does nothing. It is the default.
+type NopInstrumentation struct{}
+
+func (NopInstrumentation) RequestStarted(ctx context.Context, _ RequestInfo) context.Context {
+	return ctx
+}
+
+func (NopInstrumentation) RequestFinished(context.Context, RequestInfo, RequestResult) {}
+
+func (NopInstrumentation) StreamMessage(context.Context, RequestInfo, int) {}
+
+// InstrumentationFuncs adapts plain functions to Instrumentation, which makes it easy to
+// bridge to a metrics or tracing library such as OpenTelemetry. Nil functions are skipped.
+//
+//	ins := &gotwi.InstrumentationFuncs{
+//		OnRequestStarted: func(ctx context.Context, info gotwi.RequestInfo) context.Context {
+//			ctx, _ = tracer.Start(ctx, info.Method+" "+info.Endpoint)
+//			return ctx
+//		},
+//		OnRequestFinished: func(ctx context.Context, info gotwi.RequestInfo, r gotwi.RequestResult) {
+//			latency.Record(ctx, r.Latency.Seconds(), metric.WithAttributes(
+//				attribute.String("endpoint", info.Endpoint),
+//				attribute.Int("status", r.StatusCode)))
+//			trace.SpanFromContext(ctx).End()
+//		},
+//	}
+type InstrumentationFuncs struct {
+	OnRequestStarted  func(ctx context.Context, info RequestInfo) context.Context
+	OnRequestFinished func(ctx context.Context, info RequestInfo, result RequestResult)
+	OnStreamMessage   func(ctx context.Context, info RequestInfo, bytes int)
+}
+
+func (f *InstrumentationFuncs) RequestStarted(ctx context.Context, info RequestInfo) context.Context {
+	if f == nil || f.OnRequestStarted == nil {
+		return ctx
+	}
+
+	if c := f.OnRequestStarted(ctx, info); c != nil {
+		return c
+	}
+	return ctx
+}
+
+func (f *InstrumentationFuncs) RequestFinished(ctx context.Context, info RequestInfo, result RequestResult) {
+	if f == nil || f.OnRequestFinished == nil {
+		return
+	}
+	f.OnRequestFinished(ctx, info, result)
+}
+
+func (f *InstrumentationFuncs) StreamMessage(ctx context.Context, info RequestInfo, bytes int) {
+	if f == nil || f.OnStreamMessage == nil {
+		return
+	}
+	f.OnStreamMessage(ctx, info, bytes)
+}
+
+func newRequestInfo(req *http.Request, stream bool) RequestInfo {
+	return RequestInfo{
+		Method:   req.Method,
+		Endpoint: requestEndpoint(req),
+		Stream:   stream,
+	}
+}
+
+// instrumentMiddleware reports every request to ins.
+func instrumentMiddleware(ins Instrumentation, stream bool) Middleware {
+	return func(next ExecFunc) ExecFunc {
+		return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+			info := newRequestInfo(req, stream)
+			ctx := ins.RequestStarted(req.Context(), info)
+			req = req.WithContext(ctx)
+
+			start := time.Now()
+			res, non200err, err := next(req)
+
+			result := RequestResult{
+				Latency: time.Since(start),
+				Err:     err,
+			}
+			if res != nil {
+				result.StatusCode = res.StatusCode
+				result.RateLimit = responseRateLimit(res)
+			}
+			if non200err != nil {
+				if result.StatusCode == 0 {
+					result.StatusCode = non200err.StatusCode
+				}
+				for _, ae := range non200err.APIErrors {
+					if ae.Code > 0 {
+						result.ErrorCodes = append(result.ErrorCodes, ae.Code)
+					}
+				}
+			}
+
+			ins.RequestFinished(ctx, info, result)
+			return res, non200err, err
+		}
+	}
+}
+
+// responseRateLimit returns the rate limit information of res, or nil if it has none.
+func responseRateLimit(res *http.Response) *RateLimitInformation {
+	if len(util.HeaderValues(util.RATE_LIMIT_LIMIT_HEADER_KEY, res.Header)) == 0 {
+		return nil
+	}
+
+	rli, err := util.GetRateLimitInformation(res)
+	if err != nil {
+		return nil
+	}
+
+	return rli
+}
+
+// buildMiddlewares returns the user middlewares followed by the built-in ones.
+// The built-in middlewares are the innermost, so that they observe the request as it is sent.
+func buildMiddlewares(mws []Middleware, ins Instrumentation, l *slog.Logger, stream bool) []Middleware {
+	chain := make([]Middleware, 0, len(mws)+2)
+	chain = append(chain, mws...)
+	if ins != nil {
+		chain = append(chain, instrumentMiddleware(ins, stream))
+	}
+	if l != nil {
+		chain = append(chain, logMiddleware(l))
+	}
+	return chain
+}
@@ -0,0 +1,178 @@
+package gotwi_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type recordedInstrumentation struct {
+	mu       sync.Mutex
+	started  []gotwi.RequestInfo
+	finished []gotwi.RequestResult
+	spans    []any
+	messages []int
+}
+
+func (r *recordedInstrumentation) funcs() *gotwi.InstrumentationFuncs {
+	return &gotwi.InstrumentationFuncs{
+		OnRequestStarted: func(ctx context.Context, info gotwi.RequestInfo) context.Context {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.started = append(r.started, info)
+			return context.WithValue(ctx, spanKey{}, "span")
+		},
+		OnRequestFinished: func(ctx context.Context, info gotwi.RequestInfo, result gotwi.RequestResult) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.finished = append(r.finished, result)
+			r.spans = append(r.spans, ctx.Value(spanKey{}))
+		},
+		OnStreamMessage: func(ctx context.Context, info gotwi.RequestInfo, bytes int) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.messages = append(r.messages, bytes)
+			r.spans = append(r.spans, ctx.Value(spanKey{}))
+		},
+	}
+}
+
+func Test_Instrumentation(t *testing.T) {
+	cases := []struct {
+		name             string
+		mock             mockResponse
+		wantErr          bool
+		expectStatusCode int
+		expectErrorCodes []resources.ErrorCode
+		expectRemaining  int
+		hasRateLimit     bool
+	}{
+		{
+			name: "ok",
+			mock: mockResponse{
+				statusCode: http.StatusOK,
+				header: map[string][]string{
+					"Content-Type":           {"application/json"},
+					"X-Rate-Limit-Limit":     {"900"},
+					"X-Rate-Limit-Remaining": {"12"},
+				},
+				body: `{}`,
+			},
+			expectStatusCode: http.StatusOK,
+			hasRateLimit:     true,
+			expectRemaining:  12,
+		},
+		{
+			name: "error: api error",
+			mock: mockResponse{
+				statusCode: http.StatusForbidden,
+				header:     map[string][]string{"Content-Type": {"application/json"}},
+				body:       `{"errors":[{"message":"duplicate","code":187}]}`,
+			},
+			wantErr:          true,
+			expectStatusCode: http.StatusForbidden,
+			expectErrorCodes: []resources.ErrorCode{187},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			rec := &recordedInstrumentation{}
+			var transportSpan any
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+					transportSpan = req.Context().Value(spanKey{})
+					return &http.Response{
+						StatusCode: c.mock.statusCode,
+						Header:     c.mock.header,
+						Body:       io.NopCloser(strings.NewReader(c.mock.body)),
+					}
+				}),
+				AccessToken:     "token",
+				Instrumentation: rec.funcs(),
+			})
+			asst.NoError(err)
+
+			err = client.CallAPI(context.Background(), "/2/users/:id/tweets", http.MethodGet, &testParameter{}, &gotwi.MockResponse{})
+			if c.wantErr {
+				asst.Error(err)
+			} else {
+				asst.NoError(err)
+			}
+
+			asst.Equal("span", transportSpan)
+			asst.Equal([]gotwi.RequestInfo{{Method: "GET", Endpoint: "/2/users/:id/tweets"}}, rec.started)
+			asst.Len(rec.finished, 1)
+			asst.Equal([]any{"span"}, rec.spans)
+
+			r := rec.finished[0]
+			asst.Equal(c.expectStatusCode, r.StatusCode)
+			asst.Equal(c.expectErrorCodes, r.ErrorCodes)
+			asst.NoError(r.Err)
+			if c.hasRateLimit {
+				asst.NotNil(r.RateLimit)
+				asst.Equal(c.expectRemaining, r.RateLimit.Remaining)
+			} else {
+				asst.Nil(r.RateLimit)
+			}
+		})
+	}
+}
+
+func Test_Instrumentation_Stream(t *testing.T) {
+	asst := assert.New(t)
+
+	rec := &recordedInstrumentation{}
+	client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("{\"text\":\"1\"}\n{\"text\":\"22\"}\n")),
+				Request:    req,
+			}
+		}),
+		AccessToken:     "token",
+		Instrumentation: rec.funcs(),
+	})
+	asst.NoError(err)
+
+	tc := gotwi.NewTypedClient[*gotwi.MockResponse](client)
+	s, err := tc.CallStreamAPI(context.Background(), "/2/tweets/sample/stream", http.MethodGet, &testParameter{})
+	asst.NoError(err)
+
+	for s.Receive() {
+	}
+	s.Stop()
+
+	asst.Equal([]gotwi.RequestInfo{{Method: "GET", Endpoint: "/2/tweets/sample/stream", Stream: true}}, rec.started)
+	asst.Equal([]int{12, 13}, rec.messages)
+	asst.Equal([]any{"span", "span", "span"}, rec.spans)
+}
+
+func Test_NopInstrumentation(t *testing.T) {
+	asst := assert.New(t)
+
+	ctx := context.Background()
+	ins := gotwi.NopInstrumentation{}
+	asst.Equal(ctx, ins.RequestStarted(ctx, gotwi.RequestInfo{}))
+	ins.RequestFinished(ctx, gotwi.RequestInfo{}, gotwi.RequestResult{})
+	ins.StreamMessage(ctx, gotwi.RequestInfo{}, 1)
+
+	var funcs *gotwi.InstrumentationFuncs
+	asst.Equal(ctx, funcs.RequestStarted(ctx, gotwi.RequestInfo{}))
+	asst.Equal(ctx, (&gotwi.InstrumentationFuncs{}).RequestStarted(ctx, gotwi.RequestInfo{}))
+
+	client := &gotwi.Client{}
+	asst.Equal(gotwi.NopInstrumentation{}, client.Instrumentation())
+}
@@ -8,10 +8,10 @@ import (
 )
 
 const (
-	listFollowersEndpoint = "https://api.twitter.com/2/lists/:id/followers"
-	listFollowedEndpoint  = "https://api.twitter.com/2/users/:id/followed_lists"
-	createEndpoint        = "https://api.twitter.com/2/users/:id/followed_lists"
-	deleteEndpoint        = "https://api.twitter.com/2/users/:id/followed_lists/:list_id"
+	listFollowersEndpoint = "/2/lists/:id/followers"
+	listFollowedEndpoint  = "/2/users/:id/followed_lists"
+	createEndpoint        = "/2/users/:id/followed_lists"
+	deleteEndpoint        = "/2/users/:id/followed_lists/:list_id"
 )
 
 // Returns a list of users who are followers of the specified List.
@@ -19,6 +19,9 @@ const (
 func ListFollowers(ctx context.Context, c *gotwi.Client, p *types.ListFollowersInput) (*types.ListFollowersOutput, error) {
 	res := &types.ListFollowersOutput{}
 	if err := c.CallAPI(ctx, listFollowersEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -30,6 +33,9 @@ func ListFollowers(ctx context.Context, c *gotwi.Client, p *types.ListFollowersI
 func ListFollowed(ctx context.Context, c *gotwi.Client, p *types.ListFollowedInput) (*types.ListFollowedOutput, error) {
 	res := &types.ListFollowedOutput{}
 	if err := c.CallAPI(ctx, listFollowedEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -41,6 +47,9 @@ func ListFollowed(ctx context.Context, c *gotwi.Client, p *types.ListFollowedInp
 func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.CreateOutput, error) {
 	res := &types.CreateOutput{}
 	if err := c.CallAPI(ctx, createEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -52,6 +61,9 @@ func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.
 func Delete(ctx context.Context, c *gotwi.Client, p *types.DeleteInput) (*types.DeleteOutput, error) {
 	res := &types.DeleteOutput{}
 	if err := c.CallAPI(ctx, deleteEndpoint, "DELETE", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
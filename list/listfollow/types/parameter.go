@@ -51,6 +51,10 @@ func (p *ListFollowersInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListFollowersInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListFollowersInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
@@ -128,6 +132,10 @@ func (p *ListFollowedInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListFollowedInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListFollowedInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
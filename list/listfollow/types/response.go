@@ -15,6 +15,17 @@ func (r *ListFollowersOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListFollowersOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListFollowersOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type ListFollowedOutput struct {
 	Data     []resources.List `json:"data"`
 	Includes struct {
@@ -28,6 +39,17 @@ func (r *ListFollowedOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListFollowedOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListFollowedOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type CreateOutput struct {
 	Data struct {
 		Following bool `json:"following"`
@@ -8,8 +8,8 @@ import (
 )
 
 const (
-	getEndpoint       = "https://api.twitter.com/2/lists/:id"
-	listOwnedEndpoint = "https://api.twitter.com/2/users/:id/owned_lists"
+	getEndpoint       = "/2/lists/:id"
+	listOwnedEndpoint = "/2/users/:id/owned_lists"
 )
 
 // Returns the details of a specified List.
@@ -17,6 +17,9 @@ const (
 func Get(ctx context.Context, c *gotwi.Client, p *types.GetInput) (*types.GetOutput, error) {
 	res := &types.GetOutput{}
 	if err := c.CallAPI(ctx, getEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -28,6 +31,9 @@ func Get(ctx context.Context, c *gotwi.Client, p *types.GetInput) (*types.GetOut
 func ListOwned(ctx context.Context, c *gotwi.Client, p *types.ListOwnedInput) (*types.ListOwnedOutput, error) {
 	res := &types.ListOwnedOutput{}
 	if err := c.CallAPI(ctx, listOwnedEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -103,6 +103,10 @@ func (p *ListOwnedInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListOwnedInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListOwnedInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
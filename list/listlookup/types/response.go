@@ -26,3 +26,14 @@ type ListOwnedOutput struct {
 func (r *ListOwnedOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
+
+func (r *ListOwnedOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListOwnedOutput) ResultCount() int {
+	return len(r.Data)
+}
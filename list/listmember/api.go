@@ -8,10 +8,10 @@ import (
 )
 
 const (
-	listMembershipsEndpoint = "https://api.twitter.com/2/users/:id/list_memberships"
-	listEndpoint            = "https://api.twitter.com/2/lists/:id/members"
-	createEndpoint          = "https://api.twitter.com/2/lists/:id/members"
-	deleteEndpoint          = "https://api.twitter.com/2/lists/:id/members/:user_id"
+	listMembershipsEndpoint = "/2/users/:id/list_memberships"
+	listEndpoint            = "/2/lists/:id/members"
+	createEndpoint          = "/2/lists/:id/members"
+	deleteEndpoint          = "/2/lists/:id/members/:user_id"
 )
 
 // Returns all Lists a specified user is a member of.
@@ -19,6 +19,9 @@ const (
 func ListMemberships(ctx context.Context, c *gotwi.Client, p *types.ListMembershipsInput) (*types.ListMembershipsOutput, error) {
 	res := &types.ListMembershipsOutput{}
 	if err := c.CallAPI(ctx, listMembershipsEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -30,6 +33,9 @@ func ListMemberships(ctx context.Context, c *gotwi.Client, p *types.ListMembersh
 func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.ListOutput, error) {
 	res := &types.ListOutput{}
 	if err := c.CallAPI(ctx, listEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -41,6 +47,9 @@ func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.List
 func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.CreateOutput, error) {
 	res := &types.CreateOutput{}
 	if err := c.CallAPI(ctx, createEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -52,6 +61,9 @@ func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.
 func Delete(ctx context.Context, c *gotwi.Client, p *types.DeleteInput) (*types.DeleteOutput, error) {
 	res := &types.DeleteOutput{}
 	if err := c.CallAPI(ctx, deleteEndpoint, "DELETE", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -51,6 +51,10 @@ func (p *ListMembershipsInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListMembershipsInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListMembershipsInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
@@ -120,6 +124,10 @@ func (p *ListInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 var listQueryParameters = map[string]struct{}{
 	"expansions":       {},
 	"list.fields":      {},
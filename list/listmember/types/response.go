@@ -15,6 +15,17 @@ func (r *ListMembershipsOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListMembershipsOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListMembershipsOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type ListOutput struct {
 	Data     []resources.User `json:"data"`
 	Includes struct {
@@ -28,6 +39,17 @@ func (r *ListOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type CreateOutput struct {
 	Data struct {
 		IsMember bool `json:"is_member"`
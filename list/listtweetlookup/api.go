@@ -7,13 +7,16 @@ import (
 	"github.com/xxiiaaon/gotwi/list/listtweetlookup/types"
 )
 
-const listEndpoint = "https://api.twitter.com/2/lists/:id/tweets"
+const listEndpoint = "/2/lists/:id/tweets"
 
 // Returns a list of Tweets from the specified List.
 // https://developer.twitter.com/en/docs/twitter-api/lists/list-tweets/api-reference/get-lists-id-tweets
 func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.ListOutput, error) {
 	res := &types.ListOutput{}
 	if err := c.CallAPI(ctx, listEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
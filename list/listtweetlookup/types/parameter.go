@@ -50,6 +50,10 @@ func (p *ListInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
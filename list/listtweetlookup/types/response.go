@@ -15,3 +15,14 @@ type ListOutput struct {
 func (r *ListOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
+
+func (r *ListOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListOutput) ResultCount() int {
+	return len(r.Data)
+}
@@ -8,9 +8,9 @@ import (
 )
 
 const (
-	createEndpoint = "https://api.twitter.com/2/lists"
-	updateEndpoint = "https://api.twitter.com/2/lists/:id"
-	deleteEndpoint = "https://api.twitter.com/2/lists/:id"
+	createEndpoint = "/2/lists"
+	updateEndpoint = "/2/lists/:id"
+	deleteEndpoint = "/2/lists/:id"
 )
 
 // Enables the authenticated user to create a List.
@@ -18,6 +18,9 @@ const (
 func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.CreateOutput, error) {
 	res := &types.CreateOutput{}
 	if err := c.CallAPI(ctx, createEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -29,6 +32,9 @@ func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.
 func Update(ctx context.Context, c *gotwi.Client, p *types.UpdateInput) (*types.UpdateOutput, error) {
 	res := &types.UpdateOutput{}
 	if err := c.CallAPI(ctx, updateEndpoint, "PUT", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -40,6 +46,9 @@ func Update(ctx context.Context, c *gotwi.Client, p *types.UpdateInput) (*types.
 func Delete(ctx context.Context, c *gotwi.Client, p *types.DeleteInput) (*types.DeleteOutput, error) {
 	res := &types.DeleteOutput{}
 	if err := c.CallAPI(ctx, deleteEndpoint, "DELETE", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -0,0 +1,195 @@
+package gotwi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xxiiaaon/gotwi/internal/util"
+	"github.com/xxiiaaon/gotwi/resources"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are never written to logs.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// sensitiveQueryParameters are never written to logs, e.g. the tokens of the OAuth 1.0a flow.
+var sensitiveQueryParameters = []string{
+	"oauth_token", "oauth_token_secret", "oauth_verifier", "oauth_signature",
+	"access_token", "refresh_token", "code", "code_verifier",
+}
+
+// newDebugLogger returns the logger used when NewClientInput.Debug is true and no Logger is set.
+func newDebugLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// redactHeaders returns a copy of h in which credentials are replaced.
+func redactHeaders(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+
+	r := h.Clone()
+	for _, k := range sensitiveHeaders {
+		if _, ok := r[k]; ok {
+			r[k] = []string{redacted}
+		}
+	}
+
+	return r
+}
+
+// redactURL returns u as a string in which credentials in the query are replaced.
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	if u.RawQuery == "" {
+		return u.String()
+	}
+
+	q := u.Query()
+	for _, k := range sensitiveQueryParameters {
+		if _, ok := q[k]; ok {
+			q[k] = []string{redacted}
+		}
+	}
+
+	r := *u
+	r.RawQuery = q.Encode()
+	return r.String()
+}
+
+// redactError returns the message of err, in which the URL of a *url.Error is redacted.
+func redactError(err error) string {
+	var ue *url.Error
+	if !errors.As(err, &ue) {
+		return err.Error()
+	}
+
+	u, perr := url.Parse(ue.URL)
+	if perr != nil {
+		return err.Error()
+	}
+	return strings.Replace(err.Error(), ue.URL, redactURL(u), 1)
+}
+
+// requestEndpoint returns the endpoint template of the request, e.g. "/2/users/:id/tweets".
+func requestEndpoint(req *http.Request) string {
+	if tmpl, ok := req.Context().Value(endpointContextKey{}).(string); ok && tmpl != "" {
+		return tmpl
+	}
+
+	return req.URL.Path
+}
+
+// logMiddleware writes one record per request to l.
+// Successful requests are logged at Debug, responses with a status other than 2XX at Warn,
+// and transport errors at Error. The redacted URL and headers are added at Debug.
+func logMiddleware(l *slog.Logger) Middleware {
+	return func(next ExecFunc) ExecFunc {
+		return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+			start := time.Now()
+			res, non200err, err := next(req)
+
+			ctx := req.Context()
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("endpoint", requestEndpoint(req)),
+				slog.Duration("latency", time.Since(start)),
+			}
+
+			if l.Enabled(ctx, slog.LevelDebug) {
+				attrs = append(attrs,
+					slog.String("url", redactURL(req.URL)),
+					slog.Any("request_header", redactHeaders(req.Header)))
+			}
+
+			if err != nil {
+				attrs = append(attrs, slog.String("error", redactError(err)))
+				l.LogAttrs(ctx, slog.LevelError, "gotwi: request failed", attrs...)
+				return res, non200err, err
+			}
+
+			if res != nil {
+				attrs = append(attrs, slog.Int("status", res.StatusCode))
+				attrs = append(attrs, rateLimitAttrs(res.Header)...)
+				if l.Enabled(ctx, slog.LevelDebug) {
+					attrs = append(attrs, slog.Any("response_header", redactHeaders(res.Header)))
+				}
+			}
+
+			if non200err != nil {
+				attrs = append(attrs, non2XXErrorAttrs(non200err)...)
+				l.LogAttrs(ctx, slog.LevelWarn, "gotwi: API returned an error", attrs...)
+				return res, non200err, err
+			}
+
+			l.LogAttrs(ctx, slog.LevelDebug, "gotwi: request completed", attrs...)
+			return res, non200err, err
+		}
+	}
+}
+
+func rateLimitAttrs(h http.Header) []slog.Attr {
+	attrs := []slog.Attr{}
+	keys := map[string]string{
+		util.RATE_LIMIT_LIMIT_HEADER_KEY:     "rate_limit_limit",
+		util.RATE_LIMIT_REMAINING_HEADER_KEY: "rate_limit_remaining",
+		util.RATE_LIMIT_RESET_HEADER_KEY:     "rate_limit_reset",
+	}
+	for _, k := range []string{util.RATE_LIMIT_LIMIT_HEADER_KEY, util.RATE_LIMIT_REMAINING_HEADER_KEY, util.RATE_LIMIT_RESET_HEADER_KEY} {
+		if vs := util.HeaderValues(k, h); len(vs) > 0 {
+			attrs = append(attrs, slog.String(keys[k], vs[0]))
+		}
+	}
+
+	return attrs
+}
+
+func non2XXErrorAttrs(e *resources.Non2XXError) []slog.Attr {
+	attrs := []slog.Attr{}
+	if e.Title != "" {
+		attrs = append(attrs, slog.String("title", e.Title))
+	}
+	if e.Detail != "" {
+		attrs = append(attrs, slog.String("detail", e.Detail))
+	}
+	if e.Type != "" {
+		attrs = append(attrs, slog.String("type", e.Type))
+	}
+
+	codes := []int{}
+	messages := []string{}
+	for _, ae := range e.APIErrors {
+		if ae.Code > 0 {
+			codes = append(codes, int(ae.Code))
+		}
+		if ae.Message != "" {
+			messages = append(messages, ae.Message)
+		}
+	}
+	if len(codes) > 0 {
+		attrs = append(attrs, slog.Any("error_codes", codes))
+	}
+	if len(messages) > 0 {
+		attrs = append(attrs, slog.String("error_messages", strings.Join(messages, "; ")))
+	}
+
+	return attrs
+}
+
+// logResponseBody writes the body of a 2XX response at Debug.
+func logResponseBody(ctx context.Context, l *slog.Logger, req *http.Request, body []byte) {
+	l.LogAttrs(ctx, slog.LevelDebug, "gotwi: response body",
+		slog.String("method", req.Method),
+		slog.String("endpoint", requestEndpoint(req)),
+		slog.String("body", string(body)))
+}
@@ -0,0 +1,239 @@
+package gotwi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeLogRecords(tt *testing.T, b *bytes.Buffer) []map[string]any {
+	records := []map[string]any{}
+	dec := json.NewDecoder(b)
+	for dec.More() {
+		r := map[string]any{}
+		if err := dec.Decode(&r); err != nil {
+			tt.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func Test_Logger(t *testing.T) {
+	cases := []struct {
+		name         string
+		level        slog.Level
+		mock         mockResponse
+		wantErr      bool
+		expectLevels []string
+		expectAttrs  map[string]any
+		expectBody   bool
+	}{
+		{
+			name:  "ok: success is logged at debug",
+			level: slog.LevelDebug,
+			mock: mockResponse{
+				statusCode: http.StatusOK,
+				header: map[string][]string{
+					"Content-Type":           {"application/json"},
+					"X-Rate-Limit-Limit":     {"900"},
+					"X-Rate-Limit-Remaining": {"899"},
+					"X-Rate-Limit-Reset":     {"100000000"},
+				},
+				body: `{"text":"secret body"}`,
+			},
+			expectLevels: []string{"DEBUG", "DEBUG"},
+			expectAttrs: map[string]any{
+				"method":               "GET",
+				"endpoint":             "/2/users/:id/tweets",
+				"status":               float64(200),
+				"rate_limit_limit":     "900",
+				"rate_limit_remaining": "899",
+				"rate_limit_reset":     "100000000",
+			},
+			expectBody: true,
+		},
+		{
+			name:  "ok: success is not logged at info",
+			level: slog.LevelInfo,
+			mock: mockResponse{
+				statusCode: http.StatusOK,
+				body:       `{"text":"secret body"}`,
+			},
+			expectLevels: []string{},
+		},
+		{
+			name:  "ok: api error",
+			level: slog.LevelInfo,
+			mock: mockResponse{
+				statusCode: http.StatusForbidden,
+				body:       `{"title":"Forbidden","errors":[{"message":"Status is a duplicate.","code":187}]}`,
+			},
+			wantErr:      true,
+			expectLevels: []string{"WARN"},
+			expectAttrs: map[string]any{
+				"endpoint":    "/2/users/:id/tweets",
+				"status":      float64(403),
+				"title":       "Forbidden",
+				"error_codes": []any{float64(187)},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			buf := new(bytes.Buffer)
+			logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: c.level}))
+
+			calls := 0
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient:  newSequentialMockClient([]mockResponse{c.mock}, &calls),
+				AccessToken: "secret-token",
+				Logger:      logger,
+			})
+			asst.NoError(err)
+
+			err = client.CallAPI(context.Background(), "/2/users/:id/tweets", http.MethodGet, &testParameter{}, &gotwi.MockResponse{})
+			if c.wantErr {
+				asst.Error(err)
+			} else {
+				asst.NoError(err)
+			}
+
+			asst.NotContains(buf.String(), "Bearer")
+			asst.Equal(c.expectBody, strings.Contains(buf.String(), "secret body"))
+
+			records := decodeLogRecords(tt, buf)
+			levels := []string{}
+			for _, r := range records {
+				levels = append(levels, r["level"].(string))
+			}
+			asst.Equal(c.expectLevels, levels)
+
+			if len(records) == 0 {
+				return
+			}
+			for k, v := range c.expectAttrs {
+				asst.Equal(v, records[0][k], k)
+			}
+			asst.Contains(records[0], "latency")
+		})
+	}
+}
+
+func Test_Logger_RedactAuthorization(t *testing.T) {
+	asst := assert.New(t)
+
+	buf := new(bytes.Buffer)
+	client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{}`)),
+			}
+		}),
+		AccessToken: "secret-token",
+		Logger:      slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	asst.NoError(err)
+
+	asst.NoError(client.CallAPI(context.Background(), "/2/tweets", http.MethodGet, &testParameter{}, &gotwi.MockResponse{}))
+
+	records := decodeLogRecords(t, buf)
+	asst.NotEmpty(records)
+	header, ok := records[0]["request_header"].(map[string]any)
+	asst.True(ok)
+	asst.Equal([]any{"[REDACTED]"}, header["Authorization"])
+	asst.NotContains(buf.String(), "Bearer")
+}
+
+func Test_Logger_RedactQuery(t *testing.T) {
+	cases := []struct {
+		name      string
+		roundTrip func(req *http.Request) (*http.Response, error)
+		wantErr   bool
+	}{
+		{
+			name: "ok: url attribute",
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+			},
+		},
+		{
+			name: "error: transport error",
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			buf := new(bytes.Buffer)
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient:  &http.Client{Transport: roundTripErrFunc(c.roundTrip)},
+				AccessToken: "secret-token",
+				Logger:      slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+			})
+			asst.NoError(err)
+
+			req, err := http.NewRequest(http.MethodPost, "https://api.twitter.com/oauth/access_token?oauth_token=secret-oauth-token&oauth_verifier=secret-verifier&x=1", nil)
+			asst.NoError(err)
+			_, err = client.Exec(req, &gotwi.MockResponse{})
+			if c.wantErr {
+				asst.Error(err)
+			} else {
+				asst.NoError(err)
+			}
+
+			asst.NotContains(buf.String(), "secret-oauth-token")
+			asst.NotContains(buf.String(), "secret-verifier")
+			asst.Contains(buf.String(), "x=1")
+		})
+	}
+}
+
+// roundTripErrFunc is a RoundTripper that can also fail.
+type roundTripErrFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripErrFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func Test_Logger_Debug(t *testing.T) {
+	asst := assert.New(t)
+
+	c, err := gotwi.NewClient(&gotwi.NewClientInput{
+		AuthenticationMethod: gotwi.AuthenMethodOAuth1UserContext,
+		OAuthToken:           "token",
+		OAuthTokenSecret:     "secret",
+		APIKey:               testConsumerKey,
+		APIKeySecret:         testConsumerSecret,
+		Debug:                true,
+	})
+	asst.NoError(err)
+
+	// the debug logger is built once, not per request
+	l := c.Logger()
+	asst.NotNil(l)
+	asst.Same(l, c.Logger())
+	asst.True(l.Enabled(context.Background(), slog.LevelDebug))
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	c.SetLogger(logger)
+	asst.Same(logger, c.Logger())
+}
@@ -0,0 +1,77 @@
+package gotwi
+
+import (
+	"net/http"
+
+	"github.com/xxiiaaon/gotwi/resources"
+)
+
+// ExecFunc sends a prepared request to the Twitter API.
+// For a response with a status other than 2XX series, the body has already been
+// consumed and the decoded error is returned as *resources.Non2XXError.
+// For a 2XX response, the body has not been read yet.
+type ExecFunc func(req *http.Request) (*http.Response, *resources.Non2XXError, error)
+
+// Middleware wraps the ExecFunc used by Client.Exec and TypedClient.ExecStream.
+// It can inspect or modify the request, the response and the error,
+// or return a response without calling next.
+type Middleware func(next ExecFunc) ExecFunc
+
+// chainMiddlewares wraps h so that the first middleware is the outermost one.
+func chainMiddlewares(h ExecFunc, mws []Middleware) ExecFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] == nil {
+			continue
+		}
+		h = mws[i](h)
+	}
+
+	return h
+}
+
+// execHTTP returns the innermost ExecFunc that sends the request with hc. It feeds the
+// rate limit headers to t and l, and lets s learn the clock offset from a rejected timestamp.
+func execHTTP(hc *http.Client, t *rateLimitTracker, l *RateLimiter, s *oauth1Signer) ExecFunc {
+	return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+		res, err := hc.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		observeRateLimit(t, l, req, res)
+
+		if _, ok := okCodes[res.StatusCode]; ok {
+			return res, nil, nil
+		}
+
+		defer res.Body.Close()
+		non200err, err := resolveNon2XXResponse(res)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.observe(res, non200err)
+
+		return res, non200err, nil
+	}
+}
+
+// Use appends middlewares to the client. It is safe to call while requests are in
+// flight, which keep the middlewares they started with. TypedClients created
+// afterwards share them.
+func (c *Client) Use(mws ...Middleware) {
+	c.middlewaresMu.Lock()
+	defer c.middlewaresMu.Unlock()
+
+	// copy on write, so that the slices returned by Middlewares are never modified
+	next := make([]Middleware, 0, len(c.middlewares)+len(mws))
+	next = append(append(next, c.middlewares...), mws...)
+	c.middlewares = next
+}
+
+// Middlewares returns the middlewares of the client. The slice must not be modified.
+func (c *Client) Middlewares() []Middleware {
+	c.middlewaresMu.RLock()
+	defer c.middlewaresMu.RUnlock()
+
+	return c.middlewares
+}
@@ -0,0 +1,235 @@
+package gotwi_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Middleware(t *testing.T) {
+	var order []string
+	recorder := func(name string) gotwi.Middleware {
+		return func(next gotwi.ExecFunc) gotwi.ExecFunc {
+			return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+				order = append(order, name+":before")
+				res, non200err, err := next(req)
+				order = append(order, name+":after")
+				return res, non200err, err
+			}
+		}
+	}
+	injectHeader := func(next gotwi.ExecFunc) gotwi.ExecFunc {
+		return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+			req.Header.Set("X-Test", "injected")
+			return next(req)
+		}
+	}
+	shortCircuit := func(next gotwi.ExecFunc) gotwi.ExecFunc {
+		return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"text":"cached"}`)),
+			}, nil, nil
+		}
+	}
+	faultInjection := func(next gotwi.ExecFunc) gotwi.ExecFunc {
+		return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+			return &http.Response{
+				Status:     "503 Service Unavailable",
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Content-Type": {"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader("injected fault")),
+			}, nil, nil
+		}
+	}
+	nilResponse := func(next gotwi.ExecFunc) gotwi.ExecFunc {
+		return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+			return nil, nil, nil
+		}
+	}
+
+	cases := []struct {
+		name             string
+		middlewares      []gotwi.Middleware
+		statusCode       int
+		wantErr          bool
+		expectStatusCode int
+		expectCalls      int
+		expectOrder      []string
+		expectHeader     string
+		expectText       string
+	}{
+		{
+			name:        "ok: order",
+			middlewares: []gotwi.Middleware{recorder("1"), recorder("2"), nil},
+			statusCode:  http.StatusOK,
+			expectCalls: 1,
+			expectOrder: []string{"1:before", "2:before", "2:after", "1:after"},
+			expectText:  "live",
+		},
+		{
+			name:         "ok: inject header",
+			middlewares:  []gotwi.Middleware{injectHeader},
+			statusCode:   http.StatusOK,
+			expectCalls:  1,
+			expectHeader: "injected",
+			expectText:   "live",
+		},
+		{
+			name:        "ok: short circuit",
+			middlewares: []gotwi.Middleware{shortCircuit},
+			statusCode:  http.StatusOK,
+			expectCalls: 0,
+			expectText:  "cached",
+		},
+		{
+			name:             "error: fault injection",
+			middlewares:      []gotwi.Middleware{faultInjection},
+			statusCode:       http.StatusOK,
+			wantErr:          true,
+			expectStatusCode: http.StatusServiceUnavailable,
+			expectCalls:      0,
+		},
+		{
+			name:             "error: not 200 response",
+			middlewares:      []gotwi.Middleware{recorder("1")},
+			statusCode:       http.StatusNotFound,
+			wantErr:          true,
+			expectStatusCode: http.StatusNotFound,
+			expectCalls:      1,
+			expectOrder:      []string{"1:before", "1:after"},
+		},
+		{
+			name:        "error: nil response",
+			middlewares: []gotwi.Middleware{nilResponse},
+			statusCode:  http.StatusOK,
+			wantErr:     true,
+			expectCalls: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+			order = nil
+
+			calls := 0
+			header := ""
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+					calls++
+					header = req.Header.Get("X-Test")
+					return &http.Response{
+						StatusCode: c.statusCode,
+						Header:     http.Header{"Content-Type": {"application/json"}},
+						Body:       io.NopCloser(strings.NewReader(`{"text":"live"}`)),
+					}
+				}),
+				AccessToken: "token",
+				Middlewares: c.middlewares,
+			})
+			asst.NoError(err)
+
+			res := &gotwi.MockResponse{}
+			err = client.CallAPI(context.Background(), "/2/tweets", http.MethodGet, &testParameter{}, res)
+			if c.wantErr {
+				asst.Error(err)
+				if c.expectStatusCode > 0 {
+					var ge *gotwi.GotwiError
+					asst.True(errors.As(err, &ge))
+					asst.Equal(c.expectStatusCode, ge.StatusCode)
+				}
+			} else {
+				asst.NoError(err)
+				asst.Equal(c.expectText, res.Text)
+			}
+
+			asst.Equal(c.expectCalls, calls)
+			asst.Equal(c.expectOrder, order)
+			asst.Equal(c.expectHeader, header)
+		})
+	}
+}
+
+func Test_Middleware_TypedClient(t *testing.T) {
+	asst := assert.New(t)
+
+	var seen []string
+	client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"text":"stream"}`)),
+			}
+		}),
+		AccessToken: "token",
+	})
+	asst.NoError(err)
+
+	client.Use(func(next gotwi.ExecFunc) gotwi.ExecFunc {
+		return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+			seen = append(seen, req.URL.Path)
+			return next(req)
+		}
+	})
+
+	tc := gotwi.NewTypedClient[*gotwi.MockResponse](client)
+	s, err := tc.CallStreamAPI(context.Background(), "/2/tweets/sample/stream", http.MethodGet, &testParameter{})
+	asst.NoError(err)
+	defer s.Stop()
+
+	asst.Equal([]string{"/2/tweets/sample/stream"}, seen)
+}
+
+func Test_Middleware_UseConcurrently(t *testing.T) {
+	asst := assert.New(t)
+
+	client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"text":"live"}`)),
+			}
+		}),
+		AccessToken: "token",
+	})
+	asst.NoError(err)
+
+	var calls atomic.Int64
+	counter := func(next gotwi.ExecFunc) gotwi.ExecFunc {
+		return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+			calls.Add(1)
+			return next(req)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.Use(counter)
+		}()
+		go func() {
+			defer wg.Done()
+			asst.NoError(client.CallAPI(context.Background(), "/2/tweets", http.MethodGet, &testParameter{}, &gotwi.MockResponse{}))
+		}()
+	}
+	wg.Wait()
+
+	asst.Len(client.Middlewares(), 10)
+
+	calls.Store(0)
+	asst.NoError(client.CallAPI(context.Background(), "/2/tweets", http.MethodGet, &testParameter{}, &gotwi.MockResponse{}))
+	asst.Equal(int64(10), calls.Load())
+}
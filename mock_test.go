@@ -24,6 +24,15 @@ func newMockClient(fn RoundTripFunc) *http.Client {
 	}
 }
 
+func newMockResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		Status:     http.StatusText(statusCode),
+		StatusCode: statusCode,
+		Header:     map[string][]string{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
 type mockInput struct {
 	ResponseStatusCode int
 	ResponseHeader     map[string][]string
@@ -18,6 +18,26 @@ const (
 	OAuthSignatureMethodHMACSHA1 = "HMAC-SHA1"
 )
 
+// Clock returns the current time used for the OAuth 1.0a timestamp.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to use a function as a Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time { return f() }
+
+// NonceSource generates the OAuth 1.0a nonce. Nonces must be unique per request.
+type NonceSource interface {
+	Nonce() (string, error)
+}
+
+// NonceSourceFunc is an adapter to use a function as a NonceSource.
+type NonceSourceFunc func() (string, error)
+
+func (f NonceSourceFunc) Nonce() (string, error) { return f() }
+
 type Endpoint string
 
 type EndpointInfo struct {
@@ -33,6 +53,10 @@ type CreateOAuthSignatureInput struct {
 	OAuthToken       string
 	SigningKey       string
 	ParameterMap     map[string]string
+
+	// Clock and NonceSource default to the system clock and crypto/rand.
+	Clock       Clock
+	NonceSource NonceSource
 }
 
 type CreateOAuthSignatureOutput struct {
@@ -48,13 +72,21 @@ func CreateOAuthSignature(in *CreateOAuthSignatureInput) (*CreateOAuthSignatureO
 		OAuthSignatureMethod: OAuthSignatureMethodHMACSHA1,
 		OAuthVersion:         OAuthVersion10,
 	}
-	nonce, err := generateOAthNonce()
+	generateNonce := generateOAthNonce
+	if in.NonceSource != nil {
+		generateNonce = in.NonceSource.Nonce
+	}
+	nonce, err := generateNonce()
 	if err != nil {
 		return nil, err
 	}
 	out.OAuthNonce = nonce
 
-	ts := fmt.Sprintf("%d", time.Now().Unix())
+	now := time.Now
+	if in.Clock != nil {
+		now = in.Clock.Now
+	}
+	ts := fmt.Sprintf("%d", now().Unix())
 	out.OAuthTimestamp = ts
 	endpointBase := endpointBase(in.RawEndpoint)
 
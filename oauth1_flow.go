@@ -0,0 +1,303 @@
+package gotwi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/xxiiaaon/gotwi/internal/gotwierrors"
+)
+
+// The endpoints of the OAuth 1.0a three-legged flow, resolved against the base URL.
+// https://developer.twitter.com/en/docs/authentication/oauth-1-0a/obtaining-user-access-tokens
+const (
+	OAuth1RequestTokenPath = "/oauth/request_token"
+	OAuth1AuthorizePath    = "/oauth/authorize"
+	OAuth1AuthenticatePath = "/oauth/authenticate"
+	OAuth1AccessTokenPath  = "/oauth/access_token"
+
+	OAuth1InvalidateTokenPath = "/1.1/oauth/invalidate_token"
+
+	// OAuth1CallbackOOB is the callback URL for the PIN-based flow.
+	OAuth1CallbackOOB = "oob"
+)
+
+type NewOAuth1ConfigInput struct {
+	HTTPClient *http.Client
+
+	// APIKey and APIKeySecret default to the GOTWI_API_KEY and GOTWI_API_KEY_SECRET env.
+	APIKey       string
+	APIKeySecret string
+
+	BaseURL string
+	Logger  *slog.Logger
+
+	// Clock and NonceSource default to the system clock and crypto/rand.
+	Clock       Clock
+	NonceSource NonceSource
+}
+
+// OAuth1Config obtains OAuth 1.0a user access tokens with the three-legged flow:
+// RequestToken, redirecting the user to AuthorizeURL, then AccessToken with the verifier.
+type OAuth1Config struct {
+	client *Client
+}
+
+func NewOAuth1Config(in *NewOAuth1ConfigInput) (*OAuth1Config, error) {
+	if in == nil {
+		return nil, fmt.Errorf("NewOAuth1ConfigInput is nil.")
+	}
+
+	if !ValidBaseURL(in.BaseURL) {
+		return nil, fmt.Errorf("BaseURL is invalid.")
+	}
+
+	c := &Client{
+		Client:               defaultHTTPClient,
+		apiKeyOverride:       in.APIKey,
+		apiKeySecretOverride: in.APIKeySecret,
+		baseURL:              in.BaseURL,
+		logger:               in.Logger,
+	}
+
+	c.credentials.Rotate(Credentials{AuthenticationMethod: AuthenMethodOAuth1UserContext})
+	c.signer.clock = in.Clock
+	c.signer.nonceSource = in.NonceSource
+
+	if in.HTTPClient != nil {
+		c.Client = in.HTTPClient
+	}
+
+	if c.APIKey() == "" || c.APIKeySecret() == "" {
+		return nil, fmt.Errorf("env '%s' and '%s' is required.", APIKeyEnvName, APIKeySecretEnvName)
+	}
+
+	return &OAuth1Config{client: c}, nil
+}
+
+func (c *OAuth1Config) BaseURL() string {
+	return c.client.BaseURL()
+}
+
+type OAuth1RequestTokenInput struct {
+	// CallbackURL is where the user is redirected after authorizing the app.
+	// Use OAuth1CallbackOOB for the PIN-based flow.
+	CallbackURL string
+
+	// AccessType overrides the access level of the app, "read" or "write". Optional.
+	AccessType string
+}
+
+type OAuth1RequestToken struct {
+	OAuthToken             string
+	OAuthTokenSecret       string
+	OAuthCallbackConfirmed bool
+}
+
+// RequestToken obtains a request token to start the flow with.
+func (c *OAuth1Config) RequestToken(ctx context.Context, in *OAuth1RequestTokenInput) (*OAuth1RequestToken, error) {
+	if in == nil {
+		return nil, fmt.Errorf("OAuth1RequestTokenInput is nil.")
+	}
+
+	if in.CallbackURL == "" {
+		return nil, fmt.Errorf("CallbackURL is required.")
+	}
+
+	params := map[string]string{"oauth_callback": in.CallbackURL}
+	if in.AccessType != "" {
+		params["x_auth_access_type"] = in.AccessType
+	}
+
+	v, err := c.post(ctx, OAuth1RequestTokenPath, params, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OAuth1RequestToken{
+		OAuthToken:             v.Get("oauth_token"),
+		OAuthTokenSecret:       v.Get("oauth_token_secret"),
+		OAuthCallbackConfirmed: v.Get("oauth_callback_confirmed") == "true",
+	}
+
+	if out.OAuthToken == "" || out.OAuthTokenSecret == "" {
+		return nil, wrapErr(fmt.Errorf("oauth_token or oauth_token_secret is empty"))
+	}
+
+	if !out.OAuthCallbackConfirmed {
+		return nil, wrapErr(fmt.Errorf("oauth_callback_confirmed is not true"))
+	}
+
+	return out, nil
+}
+
+type OAuth1AuthorizeURLInput struct {
+	OAuthToken string
+
+	// Authenticate uses oauth/authenticate, which redirects users who already
+	// authorized the app without asking them again ("Sign in with Twitter").
+	Authenticate bool
+
+	ForceLogin bool
+	ScreenName string
+}
+
+// AuthorizeURL returns the URL to redirect the user to for authorizing the request token.
+func (c *OAuth1Config) AuthorizeURL(in *OAuth1AuthorizeURLInput) (string, error) {
+	if in == nil {
+		return "", fmt.Errorf("OAuth1AuthorizeURLInput is nil.")
+	}
+
+	if in.OAuthToken == "" {
+		return "", fmt.Errorf("OAuthToken is required.")
+	}
+
+	path := OAuth1AuthorizePath
+	if in.Authenticate {
+		path = OAuth1AuthenticatePath
+	}
+
+	q := url.Values{}
+	q.Set("oauth_token", in.OAuthToken)
+	if in.ForceLogin {
+		q.Set("force_login", "true")
+	}
+	if in.ScreenName != "" {
+		q.Set("screen_name", in.ScreenName)
+	}
+
+	return resolveURL(c.BaseURL(), path) + "?" + q.Encode(), nil
+}
+
+type OAuth1AccessTokenInput struct {
+	// OAuthToken and OAuthTokenSecret are the request token.
+	OAuthToken       string
+	OAuthTokenSecret string
+
+	// OAuthVerifier is passed to the callback URL, or is the PIN for the PIN-based flow.
+	OAuthVerifier string
+}
+
+// OAuth1AccessToken is a user access token to be used as
+// NewClientInput.OAuthToken and NewClientInput.OAuthTokenSecret.
+type OAuth1AccessToken struct {
+	OAuthToken       string
+	OAuthTokenSecret string
+	UserID           string
+	ScreenName       string
+}
+
+// AccessToken exchanges the authorized request token for a user access token.
+func (c *OAuth1Config) AccessToken(ctx context.Context, in *OAuth1AccessTokenInput) (*OAuth1AccessToken, error) {
+	if in == nil {
+		return nil, fmt.Errorf("OAuth1AccessTokenInput is nil.")
+	}
+
+	if in.OAuthToken == "" || in.OAuthTokenSecret == "" {
+		return nil, fmt.Errorf("OAuthToken and OAuthTokenSecret is required.")
+	}
+
+	if in.OAuthVerifier == "" {
+		return nil, fmt.Errorf("OAuthVerifier is required.")
+	}
+
+	params := map[string]string{"oauth_verifier": in.OAuthVerifier}
+	v, err := c.post(ctx, OAuth1AccessTokenPath, params, in.OAuthToken, in.OAuthTokenSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OAuth1AccessToken{
+		OAuthToken:       v.Get("oauth_token"),
+		OAuthTokenSecret: v.Get("oauth_token_secret"),
+		UserID:           v.Get("user_id"),
+		ScreenName:       v.Get("screen_name"),
+	}
+
+	if out.OAuthToken == "" || out.OAuthTokenSecret == "" {
+		return nil, wrapErr(fmt.Errorf("oauth_token or oauth_token_secret is empty"))
+	}
+
+	return out, nil
+}
+
+// post sends a signed request with params in the query string to an OAuth 1.0a endpoint,
+// and decodes the form encoded response.
+func (c *OAuth1Config) post(ctx context.Context, endpoint string, params map[string]string, oauthToken, oauthTokenSecret string) (url.Values, error) {
+	q := url.Values{}
+	for k, v := range params {
+		q.Set(k, v)
+	}
+
+	ctx = withEndpoint(ctx, endpoint)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, resolveURL(c.BaseURL(), endpoint)+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	signingKey := fmt.Sprintf("%s&%s", url.QueryEscape(c.client.APIKeySecret()), url.QueryEscape(oauthTokenSecret))
+	req, err = signOAuth1Request(req, params, c.client.APIKey(), oauthToken, signingKey, &c.client.signer)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	res, non200err, err := c.client.do(req)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	if non200err != nil {
+		return nil, wrapWithAPIErr(non200err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	v, err := url.ParseQuery(string(b))
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	return v, nil
+}
+
+// InvalidateOAuth1Token invalidates the OAuth 1.0a user access token of c.
+// The client can no longer be used afterwards.
+func InvalidateOAuth1Token(ctx context.Context, c *Client) (*InvalidateTokenResponse, error) {
+	if !c.IsReady() {
+		return nil, wrapErr(fmt.Errorf(gotwierrors.ErrorClientNotReady))
+	}
+
+	if c.AuthenticationMethod() != AuthenMethodOAuth1UserContext {
+		return nil, wrapErr(fmt.Errorf("AuthenticationMethod must be '%s'.", AuthenMethodOAuth1UserContext))
+	}
+
+	ctx = withEndpoint(ctx, OAuth1InvalidateTokenPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, resolveURL(c.BaseURL(), OAuth1InvalidateTokenPath), nil)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	req, err = setOAuth1Header(req, nil, c)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	res := InvalidateTokenResponse{}
+	not200err, err := c.Exec(req, &res)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	if not200err != nil {
+		return nil, wrapWithAPIErr(not200err)
+	}
+
+	return &res, nil
+}
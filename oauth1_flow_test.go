@@ -0,0 +1,508 @@
+package gotwi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testConsumerKey    = "consumer-key"
+	testConsumerSecret = "consumer-secret"
+)
+
+// parseOAuth1Header returns the parameters of an OAuth 1.0a Authorization header.
+func parseOAuth1Header(h string) (map[string]string, error) {
+	if !strings.HasPrefix(h, "OAuth ") {
+		return nil, errors.New("not an OAuth header")
+	}
+
+	params := map[string]string{}
+	for _, kv := range strings.Split(strings.TrimPrefix(h, "OAuth "), ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid parameter '%s'", kv)
+		}
+		uv, err := url.QueryUnescape(strings.Trim(v, `"`))
+		if err != nil {
+			return nil, err
+		}
+		params[k] = uv
+	}
+	return params, nil
+}
+
+// verifyOAuth1Signature checks the signature of req like the Twitter API does.
+// It works on both the server side and the client side.
+func verifyOAuth1Signature(req *http.Request, tokenSecret string) (map[string]string, error) {
+	hp, err := parseOAuth1Header(req.Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+
+	qv := url.Values{}
+	for k, v := range req.URL.Query() {
+		qv[k] = v
+	}
+	for k, v := range hp {
+		if k != "oauth_signature" {
+			qv.Set(k, v)
+		}
+	}
+	ps := strings.ReplaceAll(qv.Encode(), "+", "%20")
+
+	scheme := req.URL.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	endpoint := scheme + "://" + req.Host + req.URL.Path
+	base := gotwi.ExportCreateSignatureBase(req.Method, endpoint, ps)
+	sig, err := gotwi.ExportCalculateSignature(base, url.QueryEscape(testConsumerSecret)+"&"+url.QueryEscape(tokenSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	if sig != hp["oauth_signature"] {
+		return nil, errors.New("signature mismatch")
+	}
+
+	return hp, nil
+}
+
+// newFakeOAuth1Server serves the OAuth 1.0a endpoints with the request token
+// "request-token" and the verifier "verifier".
+func newFakeOAuth1Server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unauthorized := func() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errors":[{"code":32,"message":"Could not authenticate you."}]}`)
+		}
+
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		switch r.URL.Path {
+		case gotwi.OAuth1RequestTokenPath:
+			hp, err := verifyOAuth1Signature(r, "")
+			if err != nil || hp["oauth_consumer_key"] != testConsumerKey {
+				unauthorized()
+				return
+			}
+			if _, ok := hp["oauth_token"]; ok {
+				unauthorized()
+				return
+			}
+
+			cb := r.URL.Query().Get("oauth_callback")
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprintf(w, "oauth_token=request-token&oauth_token_secret=request-secret&oauth_callback_confirmed=%t", cb != "")
+		case gotwi.OAuth1AccessTokenPath:
+			hp, err := verifyOAuth1Signature(r, "request-secret")
+			if err != nil || hp["oauth_token"] != "request-token" || r.URL.Query().Get("oauth_verifier") != "verifier" {
+				unauthorized()
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "oauth_token=access-token&oauth_token_secret=access-secret&user_id=12345&screen_name=gotwi")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newTestOAuth1Config(t *testing.T, baseURL string) *gotwi.OAuth1Config {
+	cfg, err := gotwi.NewOAuth1Config(&gotwi.NewOAuth1ConfigInput{
+		APIKey:       testConsumerKey,
+		APIKeySecret: testConsumerSecret,
+		BaseURL:      baseURL,
+	})
+	assert.NoError(t, err)
+	return cfg
+}
+
+func Test_NewOAuth1Config(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      *gotwi.NewOAuth1ConfigInput
+		envKey  string
+		envSec  string
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			in:   &gotwi.NewOAuth1ConfigInput{APIKey: "key", APIKeySecret: "secret"},
+		},
+		{
+			name:   "ok: from env",
+			in:     &gotwi.NewOAuth1ConfigInput{},
+			envKey: "key",
+			envSec: "secret",
+		},
+		{
+			name:    "error: nil input",
+			in:      nil,
+			wantErr: true,
+		},
+		{
+			name:    "error: no api key",
+			in:      &gotwi.NewOAuth1ConfigInput{},
+			wantErr: true,
+		},
+		{
+			name:    "error: invalid base url",
+			in:      &gotwi.NewOAuth1ConfigInput{APIKey: "key", APIKeySecret: "secret", BaseURL: "api.x.com"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			tt.Setenv(gotwi.APIKeyEnvName, c.envKey)
+			tt.Setenv(gotwi.APIKeySecretEnvName, c.envSec)
+			asst := assert.New(tt)
+
+			cfg, err := gotwi.NewOAuth1Config(c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(cfg)
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal(gotwi.DefaultBaseURL, cfg.BaseURL())
+		})
+	}
+}
+
+func Test_OAuth1Config_Flow(t *testing.T) {
+	asst := assert.New(t)
+
+	srv := newFakeOAuth1Server()
+	defer srv.Close()
+	cfg := newTestOAuth1Config(t, srv.URL)
+
+	rt, err := cfg.RequestToken(context.Background(), &gotwi.OAuth1RequestTokenInput{
+		CallbackURL: "https://example.com/callback?from=gotwi",
+	})
+	asst.NoError(err)
+	asst.Equal(&gotwi.OAuth1RequestToken{
+		OAuthToken:             "request-token",
+		OAuthTokenSecret:       "request-secret",
+		OAuthCallbackConfirmed: true,
+	}, rt)
+
+	u, err := cfg.AuthorizeURL(&gotwi.OAuth1AuthorizeURLInput{OAuthToken: rt.OAuthToken})
+	asst.NoError(err)
+	asst.Equal(srv.URL+"/oauth/authorize?oauth_token=request-token", u)
+
+	at, err := cfg.AccessToken(context.Background(), &gotwi.OAuth1AccessTokenInput{
+		OAuthToken:       rt.OAuthToken,
+		OAuthTokenSecret: rt.OAuthTokenSecret,
+		OAuthVerifier:    "verifier",
+	})
+	asst.NoError(err)
+	asst.Equal(&gotwi.OAuth1AccessToken{
+		OAuthToken:       "access-token",
+		OAuthTokenSecret: "access-secret",
+		UserID:           "12345",
+		ScreenName:       "gotwi",
+	}, at)
+}
+
+func Test_OAuth1Config_RequestToken(t *testing.T) {
+	srv := newFakeOAuth1Server()
+	defer srv.Close()
+
+	cases := []struct {
+		name       string
+		secret     string
+		in         *gotwi.OAuth1RequestTokenInput
+		wantErr    bool
+		wantAPIErr bool
+	}{
+		{
+			name:   "ok: pin based",
+			secret: testConsumerSecret,
+			in:     &gotwi.OAuth1RequestTokenInput{CallbackURL: gotwi.OAuth1CallbackOOB, AccessType: "read"},
+		},
+		{
+			name:       "error: invalid signature",
+			secret:     "wrong-secret",
+			in:         &gotwi.OAuth1RequestTokenInput{CallbackURL: gotwi.OAuth1CallbackOOB},
+			wantErr:    true,
+			wantAPIErr: true,
+		},
+		{
+			name:    "error: nil input",
+			secret:  testConsumerSecret,
+			in:      nil,
+			wantErr: true,
+		},
+		{
+			name:    "error: no callback",
+			secret:  testConsumerSecret,
+			in:      &gotwi.OAuth1RequestTokenInput{},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			cfg, err := gotwi.NewOAuth1Config(&gotwi.NewOAuth1ConfigInput{
+				APIKey:       testConsumerKey,
+				APIKeySecret: c.secret,
+				BaseURL:      srv.URL,
+			})
+			asst.NoError(err)
+
+			rt, err := cfg.RequestToken(context.Background(), c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(rt)
+
+				var ge *gotwi.GotwiError
+				asst.Equal(c.wantAPIErr, errors.As(err, &ge) && ge.OnAPI)
+				if c.wantAPIErr {
+					asst.Equal(http.StatusUnauthorized, ge.StatusCode)
+				}
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal("request-token", rt.OAuthToken)
+		})
+	}
+}
+
+func Test_OAuth1Config_AuthorizeURL(t *testing.T) {
+	cfg := newTestOAuth1Config(t, "")
+
+	cases := []struct {
+		name    string
+		in      *gotwi.OAuth1AuthorizeURLInput
+		wantErr bool
+		expect  string
+	}{
+		{
+			name:   "ok: authorize",
+			in:     &gotwi.OAuth1AuthorizeURLInput{OAuthToken: "token"},
+			expect: "https://api.twitter.com/oauth/authorize?oauth_token=token",
+		},
+		{
+			name:   "ok: authenticate",
+			in:     &gotwi.OAuth1AuthorizeURLInput{OAuthToken: "token", Authenticate: true, ForceLogin: true, ScreenName: "gotwi"},
+			expect: "https://api.twitter.com/oauth/authenticate?force_login=true&oauth_token=token&screen_name=gotwi",
+		},
+		{
+			name:    "error: nil input",
+			in:      nil,
+			wantErr: true,
+		},
+		{
+			name:    "error: no token",
+			in:      &gotwi.OAuth1AuthorizeURLInput{},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			u, err := cfg.AuthorizeURL(c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Empty(u)
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal(c.expect, u)
+		})
+	}
+}
+
+func Test_OAuth1Config_AccessToken(t *testing.T) {
+	srv := newFakeOAuth1Server()
+	defer srv.Close()
+	cfg := newTestOAuth1Config(t, srv.URL)
+
+	cases := []struct {
+		name       string
+		in         *gotwi.OAuth1AccessTokenInput
+		wantErr    bool
+		wantAPIErr bool
+	}{
+		{
+			name: "ok",
+			in:   &gotwi.OAuth1AccessTokenInput{OAuthToken: "request-token", OAuthTokenSecret: "request-secret", OAuthVerifier: "verifier"},
+		},
+		{
+			name:       "error: wrong verifier",
+			in:         &gotwi.OAuth1AccessTokenInput{OAuthToken: "request-token", OAuthTokenSecret: "request-secret", OAuthVerifier: "wrong"},
+			wantErr:    true,
+			wantAPIErr: true,
+		},
+		{
+			name:       "error: wrong token secret",
+			in:         &gotwi.OAuth1AccessTokenInput{OAuthToken: "request-token", OAuthTokenSecret: "wrong", OAuthVerifier: "verifier"},
+			wantErr:    true,
+			wantAPIErr: true,
+		},
+		{
+			name:    "error: nil input",
+			in:      nil,
+			wantErr: true,
+		},
+		{
+			name:    "error: no request token",
+			in:      &gotwi.OAuth1AccessTokenInput{OAuthVerifier: "verifier"},
+			wantErr: true,
+		},
+		{
+			name:    "error: no verifier",
+			in:      &gotwi.OAuth1AccessTokenInput{OAuthToken: "request-token", OAuthTokenSecret: "request-secret"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			at, err := cfg.AccessToken(context.Background(), c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(at)
+
+				var ge *gotwi.GotwiError
+				asst.Equal(c.wantAPIErr, errors.As(err, &ge) && ge.OnAPI)
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal("12345", at.UserID)
+			asst.Equal("gotwi", at.ScreenName)
+		})
+	}
+}
+
+func Test_InvalidateOAuth1Token(t *testing.T) {
+	cases := []struct {
+		name        string
+		client      func(hc *http.Client) (*gotwi.Client, error)
+		statusCode  int
+		body        string
+		wantErr     bool
+		wantAPIErr  bool
+		expectCalls int
+	}{
+		{
+			name: "ok",
+			client: func(hc *http.Client) (*gotwi.Client, error) {
+				return gotwi.NewClient(&gotwi.NewClientInput{
+					HTTPClient:           hc,
+					AuthenticationMethod: gotwi.AuthenMethodOAuth1UserContext,
+					OAuthToken:           "user-token",
+					OAuthTokenSecret:     "user-secret",
+					APIKey:               testConsumerKey,
+					APIKeySecret:         testConsumerSecret,
+				})
+			},
+			statusCode:  http.StatusOK,
+			body:        `{"access_token":"user-token"}`,
+			expectCalls: 1,
+		},
+		{
+			name: "error: api error",
+			client: func(hc *http.Client) (*gotwi.Client, error) {
+				return gotwi.NewClient(&gotwi.NewClientInput{
+					HTTPClient:           hc,
+					AuthenticationMethod: gotwi.AuthenMethodOAuth1UserContext,
+					OAuthToken:           "user-token",
+					OAuthTokenSecret:     "user-secret",
+					APIKey:               testConsumerKey,
+					APIKeySecret:         testConsumerSecret,
+				})
+			},
+			statusCode:  http.StatusUnauthorized,
+			body:        `{"errors":[{"code":89,"message":"Invalid or expired token."}]}`,
+			wantErr:     true,
+			wantAPIErr:  true,
+			expectCalls: 1,
+		},
+		{
+			name: "error: bearer token client",
+			client: func(hc *http.Client) (*gotwi.Client, error) {
+				return gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+					HTTPClient:  hc,
+					AccessToken: "token",
+				})
+			},
+			wantErr:     true,
+			expectCalls: 0,
+		},
+		{
+			name: "error: nil client",
+			client: func(hc *http.Client) (*gotwi.Client, error) {
+				return nil, nil
+			},
+			wantErr:     true,
+			expectCalls: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			calls := 0
+			client, err := c.client(newMockClient(func(req *http.Request) *http.Response {
+				calls++
+				asst.Equal(http.MethodPost, req.Method)
+				asst.Equal(gotwi.DefaultBaseURL+gotwi.OAuth1InvalidateTokenPath, req.URL.String())
+
+				hp, err := parseOAuth1Header(req.Header.Get("Authorization"))
+				asst.NoError(err)
+				asst.Equal("user-token", hp["oauth_token"])
+
+				return &http.Response{
+					StatusCode: c.statusCode,
+					Header:     map[string][]string{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(c.body)),
+				}
+			}))
+			asst.NoError(err)
+
+			res, err := gotwi.InvalidateOAuth1Token(context.Background(), client)
+			asst.Equal(c.expectCalls, calls)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(res)
+
+				var ge *gotwi.GotwiError
+				asst.True(errors.As(err, &ge))
+				asst.Equal(c.wantAPIErr, ge.OnAPI)
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal("user-token", res.AccessToken)
+		})
+	}
+}
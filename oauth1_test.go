@@ -2,6 +2,7 @@ package gotwi_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/xxiiaaon/gotwi"
 	"github.com/stretchr/testify/assert"
@@ -113,6 +114,29 @@ func Test_CreateOAuthSignature(t *testing.T) {
 	}
 }
 
+func Test_CreateOAuthSignature_KnownVector(t *testing.T) {
+	// https://developer.twitter.com/en/docs/authentication/oauth-1-0a/creating-a-signature
+	asst := assert.New(t)
+
+	out, err := gotwi.CreateOAuthSignature(&gotwi.CreateOAuthSignatureInput{
+		HTTPMethod:       "POST",
+		RawEndpoint:      "https://api.twitter.com/1.1/statuses/update.json?include_entities=true",
+		OAuthConsumerKey: "xvz1evFS4wEEPTGEFPHBog",
+		OAuthToken:       "370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb",
+		SigningKey:       "kAcSOqF21Fu85e7zjz7ZN2U4ZRhfV3WpwPAoE3Z7kBw&LswwdoUaIvS8ltyTt5jkRh4J50vUPVVHtR2YPi5kE",
+		ParameterMap: map[string]string{
+			"include_entities": "true",
+			"status":           "Hello Ladies + Gentlemen, a signed OAuth request!",
+		},
+		Clock:       gotwi.ClockFunc(func() time.Time { return time.Unix(1318622958, 0) }),
+		NonceSource: gotwi.NonceSourceFunc(func() (string, error) { return "kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg", nil }),
+	})
+	asst.NoError(err)
+	asst.Equal("kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg", out.OAuthNonce)
+	asst.Equal("1318622958", out.OAuthTimestamp)
+	asst.Equal("hCtSmYh+iHYCEqBWrE7C7hYmtUk=", out.OAuthSignature)
+}
+
 func Test_generateOAthNonce(t *testing.T) {
 	cases := []struct {
 		name    string
@@ -7,7 +7,13 @@ import (
 	"strings"
 )
 
-const OAuth2TokenEndpoint = "https://api.twitter.com/oauth2/token"
+const (
+	OAuth2TokenPath           = "/oauth2/token"
+	OAuth2InvalidateTokenPath = "/oauth2/invalidate_token"
+
+	// Deprecated: GenerateBearerToken resolves OAuth2TokenPath against IClient.BaseURL.
+	OAuth2TokenEndpoint = DefaultBaseURL + OAuth2TokenPath
+)
 
 type OAuth2TokenResponse struct {
 	TokenType   string `json:"token_type"`
@@ -21,7 +27,7 @@ func GenerateBearerToken(c IClient, apiKey, apiKeySecret string) (string, error)
 	uv.Add("grant_type", "client_credentials")
 	body := strings.NewReader(uv.Encode())
 
-	req, err := http.NewRequest("POST", OAuth2TokenEndpoint, body)
+	req, err := http.NewRequest("POST", resolveURL(baseURLOf(c), OAuth2TokenPath), body)
 	if err != nil {
 		return "", err
 	}
@@ -45,3 +51,42 @@ func GenerateBearerToken(c IClient, apiKey, apiKeySecret string) (string, error)
 
 	return o2r.AccessToken, nil
 }
+
+// InvalidateTokenResponse is the result of invalidating a token.
+// AccessToken is the token that was invalidated.
+type InvalidateTokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+func (r InvalidateTokenResponse) HasPartialError() bool { return false }
+
+// InvalidateBearerToken invalidates an app-only bearer token issued by GenerateBearerToken.
+func InvalidateBearerToken(c IClient, apiKey, apiKeySecret, bearerToken string) (*InvalidateTokenResponse, error) {
+	if bearerToken == "" {
+		return nil, wrapErr(fmt.Errorf("bearer token is empty"))
+	}
+
+	uv := url.Values{}
+	uv.Add("access_token", bearerToken)
+	body := strings.NewReader(uv.Encode())
+
+	req, err := http.NewRequest("POST", resolveURL(baseURLOf(c), OAuth2InvalidateTokenPath), body)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=UTF-8")
+	req.SetBasicAuth(apiKey, apiKeySecret)
+
+	res := InvalidateTokenResponse{}
+	not200err, err := c.Exec(req, &res)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	if not200err != nil {
+		return nil, wrapWithAPIErr(not200err)
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,7 @@
+package oauth2
+
+import "time"
+
+func (s *RefreshingTokenSource) SetNow(now func() time.Time) {
+	s.now = now
+}
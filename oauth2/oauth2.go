@@ -0,0 +1,367 @@
+// Package oauth2 implements the OAuth 2.0 Authorization Code with PKCE flow of the Twitter API.
+//
+// https://developer.twitter.com/en/docs/authentication/oauth-2-0/authorization-code
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/internal/util"
+)
+
+const (
+	// DefaultAuthorizeURL is the page the user is redirected to for granting access to the app.
+	DefaultAuthorizeURL = "https://twitter.com/i/oauth2/authorize"
+
+	// TokenPath is the endpoint that issues access tokens. It is resolved against the base URL.
+	TokenPath = "/2/oauth2/token"
+
+	// RevokePath is the endpoint that revokes access and refresh tokens.
+	RevokePath = "/2/oauth2/revoke"
+)
+
+var defaultHTTPClient = &http.Client{
+	Timeout: time.Duration(30) * time.Second,
+}
+
+type NewConfigInput struct {
+	HTTPClient *http.Client
+	ClientID   string
+
+	// ClientSecret is required for confidential clients.
+	// Leave it empty for public clients such as native and single page apps.
+	ClientSecret string
+
+	RedirectURL string
+	Scopes      []Scope
+
+	// AuthorizeURL defaults to DefaultAuthorizeURL.
+	AuthorizeURL string
+
+	// BaseURL defaults to gotwi.DefaultBaseURL.
+	BaseURL string
+
+	// Middlewares, Logger and Instrumentation apply to the requests to the token
+	// and revoke endpoints, as with gotwi.NewClientInput.
+	Middlewares     []gotwi.Middleware
+	Logger          *slog.Logger
+	Instrumentation gotwi.Instrumentation
+}
+
+// Config is the client registration of an app that runs the Authorization Code with PKCE flow.
+type Config struct {
+	client       *gotwi.Client
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []Scope
+	authorizeURL string
+}
+
+func NewConfig(in *NewConfigInput) (*Config, error) {
+	if in == nil {
+		return nil, fmt.Errorf("NewConfigInput is nil.")
+	}
+
+	if in.ClientID == "" {
+		return nil, fmt.Errorf("ClientID is required.")
+	}
+
+	if in.RedirectURL == "" {
+		return nil, fmt.Errorf("RedirectURL is required.")
+	}
+
+	hc := in.HTTPClient
+	if hc == nil {
+		hc = defaultHTTPClient
+	}
+
+	if !gotwi.ValidBaseURL(in.BaseURL) {
+		return nil, fmt.Errorf("BaseURL '%s' is invalid.", in.BaseURL)
+	}
+
+	client, err := gotwi.NewUnauthenticatedClient(&gotwi.NewUnauthenticatedClientInput{
+		HTTPClient:      hc,
+		BaseURL:         in.BaseURL,
+		Middlewares:     in.Middlewares,
+		Logger:          in.Logger,
+		Instrumentation: in.Instrumentation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	au := in.AuthorizeURL
+	if au == "" {
+		au = DefaultAuthorizeURL
+	}
+	if !validURL(au) {
+		return nil, fmt.Errorf("AuthorizeURL '%s' is invalid.", au)
+	}
+
+	return &Config{
+		client:       client,
+		clientID:     in.ClientID,
+		clientSecret: in.ClientSecret,
+		redirectURL:  in.RedirectURL,
+		scopes:       append([]Scope{}, in.Scopes...),
+		authorizeURL: au,
+	}, nil
+}
+
+func validURL(v string) bool {
+	u, err := url.Parse(v)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (c *Config) ClientID() string {
+	return c.clientID
+}
+
+func (c *Config) RedirectURL() string {
+	return c.redirectURL
+}
+
+func (c *Config) Scopes() []Scope {
+	return append([]Scope{}, c.scopes...)
+}
+
+// IsPublic reports whether the config has no client secret.
+func (c *Config) IsPublic() bool {
+	return c.clientSecret == ""
+}
+
+func (c *Config) BaseURL() string {
+	return c.client.BaseURL()
+}
+
+type AuthorizeURLInput struct {
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod CodeChallengeMethod
+}
+
+// AuthorizeURL returns the URL to redirect the user to for granting access to the app.
+func (c *Config) AuthorizeURL(in *AuthorizeURLInput) (string, error) {
+	if in == nil {
+		return "", fmt.Errorf("AuthorizeURLInput is nil.")
+	}
+
+	if in.State == "" {
+		return "", fmt.Errorf("State is required.")
+	}
+
+	if in.CodeChallenge == "" {
+		return "", fmt.Errorf("CodeChallenge is required.")
+	}
+
+	method := in.CodeChallengeMethod
+	if method == "" {
+		method = CodeChallengeMethodS256
+	}
+	if !method.Valid() {
+		return "", fmt.Errorf("CodeChallengeMethod '%s' is invalid.", method)
+	}
+
+	u, err := url.Parse(c.authorizeURL)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.clientID)
+	q.Set("redirect_uri", c.redirectURL)
+	q.Set("scope", joinScopes(c.scopes))
+	q.Set("state", in.State)
+	q.Set("code_challenge", in.CodeChallenge)
+	q.Set("code_challenge_method", string(method))
+	u.RawQuery = strings.ReplaceAll(q.Encode(), "+", "%20")
+
+	return u.String(), nil
+}
+
+// Token is an access token issued by the token endpoint.
+type Token struct {
+	TokenType    string `json:"token_type"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+
+	// Expiry is computed from ExpiresIn when the token is issued.
+	// It is zero if the token does not expire.
+	Expiry time.Time `json:"expiry"`
+}
+
+func (t Token) HasPartialError() bool { return false }
+
+// Scopes returns the scopes granted to the token.
+func (t *Token) Scopes() []Scope {
+	fs := strings.Fields(t.Scope)
+	scopes := make([]Scope, 0, len(fs))
+	for _, s := range fs {
+		scopes = append(scopes, Scope(s))
+	}
+	return scopes
+}
+
+type ExchangeInput struct {
+	// Code is the authorization code passed to the redirect URL.
+	Code string
+
+	// CodeVerifier is the verifier the code challenge of the authorize URL was derived from.
+	CodeVerifier string
+}
+
+// Exchange exchanges an authorization code for an access token,
+// and a refresh token if the offline.access scope was granted.
+func (c *Config) Exchange(ctx context.Context, in *ExchangeInput) (*Token, error) {
+	if in == nil {
+		return nil, fmt.Errorf("ExchangeInput is nil.")
+	}
+
+	if in.Code == "" {
+		return nil, fmt.Errorf("Code is required.")
+	}
+
+	if err := validCodeVerifier(in.CodeVerifier); err != nil {
+		return nil, err
+	}
+
+	uv := url.Values{}
+	uv.Set("grant_type", "authorization_code")
+	uv.Set("code", in.Code)
+	uv.Set("redirect_uri", c.redirectURL)
+	uv.Set("code_verifier", in.CodeVerifier)
+
+	return c.requestToken(ctx, uv)
+}
+
+type RefreshInput struct {
+	RefreshToken string
+}
+
+// Refresh issues a new access token with a refresh token. The token endpoint
+// rotates the refresh token, so the one in the returned token must be used next time.
+func (c *Config) Refresh(ctx context.Context, in *RefreshInput) (*Token, error) {
+	if in == nil {
+		return nil, fmt.Errorf("RefreshInput is nil.")
+	}
+
+	if in.RefreshToken == "" {
+		return nil, fmt.Errorf("RefreshToken is required.")
+	}
+
+	uv := url.Values{}
+	uv.Set("grant_type", "refresh_token")
+	uv.Set("refresh_token", in.RefreshToken)
+
+	return c.requestToken(ctx, uv)
+}
+
+// TokenTypeHint tells the revoke endpoint which kind of token is revoked.
+type TokenTypeHint string
+
+const (
+	TokenTypeHintAccessToken  TokenTypeHint = "access_token"
+	TokenTypeHintRefreshToken TokenTypeHint = "refresh_token"
+)
+
+type RevokeInput struct {
+	Token string
+
+	// TokenTypeHint defaults to TokenTypeHintAccessToken.
+	TokenTypeHint TokenTypeHint
+}
+
+type RevokeOutput struct {
+	Revoked bool `json:"revoked"`
+}
+
+func (r RevokeOutput) HasPartialError() bool { return false }
+
+// Revoke revokes an access token or a refresh token, e.g. when a user disconnects the app.
+func (c *Config) Revoke(ctx context.Context, in *RevokeInput) (*RevokeOutput, error) {
+	if in == nil {
+		return nil, fmt.Errorf("RevokeInput is nil.")
+	}
+
+	if in.Token == "" {
+		return nil, fmt.Errorf("Token is required.")
+	}
+
+	hint := in.TokenTypeHint
+	if hint == "" {
+		hint = TokenTypeHintAccessToken
+	}
+	if hint != TokenTypeHintAccessToken && hint != TokenTypeHintRefreshToken {
+		return nil, fmt.Errorf("TokenTypeHint '%s' is invalid.", hint)
+	}
+
+	uv := url.Values{}
+	uv.Set("token", in.Token)
+	uv.Set("token_type_hint", string(hint))
+
+	out := &RevokeOutput{}
+	if err := c.postForm(ctx, RevokePath, uv, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// requestToken posts form to the token endpoint and decodes the issued token.
+func (c *Config) requestToken(ctx context.Context, form url.Values) (*Token, error) {
+	t := &Token{}
+	if err := c.postForm(ctx, TokenPath, form, t); err != nil {
+		return nil, err
+	}
+
+	if t.AccessToken == "" {
+		return nil, fmt.Errorf("access_token is empty")
+	}
+
+	if t.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+
+	return t, nil
+}
+
+// postForm sends form to the endpoint, authenticating the client with HTTP Basic auth
+// for confidential clients and with the client_id parameter for public clients.
+func (c *Config) postForm(ctx context.Context, endpoint string, form url.Values, out util.Response) error {
+	form.Set("client_id", c.clientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.client.ResolveURL(endpoint), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=UTF-8")
+	if !c.IsPublic() {
+		req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+	}
+
+	not200err, err := c.client.Exec(req, out)
+	if err != nil {
+		return err
+	}
+
+	if not200err != nil {
+		return gotwi.WrapAPIError(not200err)
+	}
+
+	return nil
+}
@@ -0,0 +1,457 @@
+package oauth2_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/oauth2"
+	"github.com/xxiiaaon/gotwi/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+const testVerifier = "dBjftJeZ4CVP-mJ92K1Pmd0VvFZ9DM0kOa1KCg8gvHM"
+
+type RoundTripFunc func(req *http.Request) *http.Response
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func newMockClient(fn RoundTripFunc) *http.Client {
+	return &http.Client{
+		Transport: fn,
+	}
+}
+
+func newJSONResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		Status:     http.StatusText(statusCode),
+		StatusCode: statusCode,
+		Header:     map[string][]string{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func Test_NewConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      *oauth2.NewConfigInput
+		wantErr bool
+		public  bool
+		baseURL string
+	}{
+		{
+			name:    "ok: confidential client",
+			in:      &oauth2.NewConfigInput{ClientID: "id", ClientSecret: "secret", RedirectURL: "https://example.com/callback"},
+			baseURL: gotwi.DefaultBaseURL,
+		},
+		{
+			name:    "ok: public client",
+			in:      &oauth2.NewConfigInput{ClientID: "id", RedirectURL: "https://example.com/callback"},
+			public:  true,
+			baseURL: gotwi.DefaultBaseURL,
+		},
+		{
+			name:    "ok: base url",
+			in:      &oauth2.NewConfigInput{ClientID: "id", RedirectURL: "https://example.com/callback", BaseURL: "https://api.x.com"},
+			public:  true,
+			baseURL: "https://api.x.com",
+		},
+		{
+			name:    "error: nil input",
+			in:      nil,
+			wantErr: true,
+		},
+		{
+			name:    "error: no client id",
+			in:      &oauth2.NewConfigInput{RedirectURL: "https://example.com/callback"},
+			wantErr: true,
+		},
+		{
+			name:    "error: no redirect url",
+			in:      &oauth2.NewConfigInput{ClientID: "id"},
+			wantErr: true,
+		},
+		{
+			name:    "error: invalid base url",
+			in:      &oauth2.NewConfigInput{ClientID: "id", RedirectURL: "https://example.com/callback", BaseURL: "api.x.com"},
+			wantErr: true,
+		},
+		{
+			name:    "error: base url with query",
+			in:      &oauth2.NewConfigInput{ClientID: "id", RedirectURL: "https://example.com/callback", BaseURL: "https://api.x.com?a=b"},
+			wantErr: true,
+		},
+		{
+			name:    "error: invalid authorize url",
+			in:      &oauth2.NewConfigInput{ClientID: "id", RedirectURL: "https://example.com/callback", AuthorizeURL: "ftp://x.com"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			cfg, err := oauth2.NewConfig(c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(cfg)
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal(c.public, cfg.IsPublic())
+			asst.Equal(c.baseURL, cfg.BaseURL())
+			asst.Equal(c.in.ClientID, cfg.ClientID())
+		})
+	}
+}
+
+func Test_Config_AuthorizeURL(t *testing.T) {
+	cfg, err := oauth2.NewConfig(&oauth2.NewConfigInput{
+		ClientID:    "client-id",
+		RedirectURL: "https://example.com/callback",
+		Scopes:      []oauth2.Scope{oauth2.ScopeTweetRead, oauth2.ScopeUsersRead, oauth2.ScopeOfflineAccess},
+	})
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		in      *oauth2.AuthorizeURLInput
+		wantErr bool
+		expect  url.Values
+	}{
+		{
+			name: "ok: S256",
+			in:   &oauth2.AuthorizeURLInput{State: "state", CodeChallenge: "challenge", CodeChallengeMethod: oauth2.CodeChallengeMethodS256},
+			expect: url.Values{
+				"response_type":         {"code"},
+				"client_id":             {"client-id"},
+				"redirect_uri":          {"https://example.com/callback"},
+				"scope":                 {"tweet.read users.read offline.access"},
+				"state":                 {"state"},
+				"code_challenge":        {"challenge"},
+				"code_challenge_method": {"S256"},
+			},
+		},
+		{
+			name: "ok: default method is S256",
+			in:   &oauth2.AuthorizeURLInput{State: "state", CodeChallenge: "challenge"},
+			expect: url.Values{
+				"response_type":         {"code"},
+				"client_id":             {"client-id"},
+				"redirect_uri":          {"https://example.com/callback"},
+				"scope":                 {"tweet.read users.read offline.access"},
+				"state":                 {"state"},
+				"code_challenge":        {"challenge"},
+				"code_challenge_method": {"S256"},
+			},
+		},
+		{
+			name: "ok: plain",
+			in:   &oauth2.AuthorizeURLInput{State: "state", CodeChallenge: "challenge", CodeChallengeMethod: oauth2.CodeChallengeMethodPlain},
+			expect: url.Values{
+				"response_type":         {"code"},
+				"client_id":             {"client-id"},
+				"redirect_uri":          {"https://example.com/callback"},
+				"scope":                 {"tweet.read users.read offline.access"},
+				"state":                 {"state"},
+				"code_challenge":        {"challenge"},
+				"code_challenge_method": {"plain"},
+			},
+		},
+		{
+			name:    "error: nil input",
+			in:      nil,
+			wantErr: true,
+		},
+		{
+			name:    "error: no state",
+			in:      &oauth2.AuthorizeURLInput{CodeChallenge: "challenge"},
+			wantErr: true,
+		},
+		{
+			name:    "error: no code challenge",
+			in:      &oauth2.AuthorizeURLInput{State: "state"},
+			wantErr: true,
+		},
+		{
+			name:    "error: invalid method",
+			in:      &oauth2.AuthorizeURLInput{State: "state", CodeChallenge: "challenge", CodeChallengeMethod: "S512"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			s, err := cfg.AuthorizeURL(c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Empty(s)
+				return
+			}
+
+			asst.NoError(err)
+			asst.True(strings.HasPrefix(s, oauth2.DefaultAuthorizeURL+"?"))
+			asst.NotContains(s, "+")
+
+			u, err := url.Parse(s)
+			asst.NoError(err)
+			asst.Equal(c.expect, u.Query())
+		})
+	}
+}
+
+func Test_Config_Exchange(t *testing.T) {
+	cases := []struct {
+		name       string
+		secret     string
+		baseURL    string
+		expectURL  string
+		in         *oauth2.ExchangeInput
+		statusCode int
+		body       string
+		wantErr    bool
+		wantAPIErr bool
+		expect     *oauth2.Token
+	}{
+		{
+			name:       "ok: confidential client",
+			secret:     "secret",
+			in:         &oauth2.ExchangeInput{Code: "code", CodeVerifier: testVerifier},
+			statusCode: http.StatusOK,
+			body:       `{"token_type":"bearer","expires_in":7200,"access_token":"access","scope":"tweet.read offline.access","refresh_token":"refresh"}`,
+			expect: &oauth2.Token{
+				TokenType:    "bearer",
+				AccessToken:  "access",
+				RefreshToken: "refresh",
+				ExpiresIn:    7200,
+				Scope:        "tweet.read offline.access",
+			},
+		},
+		{
+			name:       "ok: public client",
+			in:         &oauth2.ExchangeInput{Code: "code", CodeVerifier: testVerifier},
+			statusCode: http.StatusOK,
+			body:       `{"token_type":"bearer","expires_in":7200,"access_token":"access","scope":"tweet.read"}`,
+			expect: &oauth2.Token{
+				TokenType:   "bearer",
+				AccessToken: "access",
+				ExpiresIn:   7200,
+				Scope:       "tweet.read",
+			},
+		},
+		{
+			name:       "ok: base url with trailing slash",
+			baseURL:    "https://api.example.com/",
+			expectURL:  "https://api.example.com" + oauth2.TokenPath,
+			in:         &oauth2.ExchangeInput{Code: "code", CodeVerifier: testVerifier},
+			statusCode: http.StatusOK,
+			body:       `{"token_type":"bearer","expires_in":7200,"access_token":"access"}`,
+			expect: &oauth2.Token{
+				TokenType:   "bearer",
+				AccessToken: "access",
+				ExpiresIn:   7200,
+			},
+		},
+		{
+			name:       "error: invalid code",
+			in:         &oauth2.ExchangeInput{Code: "code", CodeVerifier: testVerifier},
+			statusCode: http.StatusBadRequest,
+			body:       `{"error":"invalid_request","error_description":"Value passed for the authorization code was invalid."}`,
+			wantErr:    true,
+			wantAPIErr: true,
+		},
+		{
+			name:       "error: empty access token",
+			in:         &oauth2.ExchangeInput{Code: "code", CodeVerifier: testVerifier},
+			statusCode: http.StatusOK,
+			body:       `{"token_type":"bearer"}`,
+			wantErr:    true,
+		},
+		{
+			name:    "error: nil input",
+			in:      nil,
+			wantErr: true,
+		},
+		{
+			name:    "error: no code",
+			in:      &oauth2.ExchangeInput{CodeVerifier: testVerifier},
+			wantErr: true,
+		},
+		{
+			name:    "error: invalid code verifier",
+			in:      &oauth2.ExchangeInput{Code: "code", CodeVerifier: "short"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			var got *http.Request
+			var form url.Values
+			cfg, err := oauth2.NewConfig(&oauth2.NewConfigInput{
+				HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+					got = req
+					b, _ := io.ReadAll(req.Body)
+					form, _ = url.ParseQuery(string(b))
+					return newJSONResponse(c.statusCode, c.body)
+				}),
+				ClientID:     "client-id",
+				ClientSecret: c.secret,
+				RedirectURL:  "https://example.com/callback",
+				BaseURL:      c.baseURL,
+				Middlewares: []gotwi.Middleware{func(next gotwi.ExecFunc) gotwi.ExecFunc {
+					return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+						req.Header.Set("X-Test", "middleware")
+						return next(req)
+					}
+				}},
+			})
+			asst.NoError(err)
+
+			tok, err := cfg.Exchange(context.Background(), c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(tok)
+
+				var ge *gotwi.GotwiError
+				asst.Equal(c.wantAPIErr, errors.As(err, &ge))
+				if c.wantAPIErr {
+					asst.True(ge.OnAPI)
+					asst.Equal("invalid_request", ge.OAuthError)
+				}
+				return
+			}
+
+			asst.NoError(err)
+			asst.False(tok.Expiry.IsZero())
+			tok.Expiry = c.expect.Expiry
+			asst.Equal(c.expect, tok)
+
+			asst.Equal(http.MethodPost, got.Method)
+			expectURL := gotwi.DefaultBaseURL + oauth2.TokenPath
+			if c.expectURL != "" {
+				expectURL = c.expectURL
+			}
+			asst.Equal(expectURL, got.URL.String())
+			asst.Equal("middleware", got.Header.Get("X-Test"))
+			asst.Equal("authorization_code", form.Get("grant_type"))
+			asst.Equal("code", form.Get("code"))
+			asst.Equal(testVerifier, form.Get("code_verifier"))
+			asst.Equal("https://example.com/callback", form.Get("redirect_uri"))
+			asst.Equal("client-id", form.Get("client_id"))
+
+			user, pass, ok := got.BasicAuth()
+			asst.Equal(c.secret != "", ok)
+			if ok {
+				asst.Equal("client-id", user)
+				asst.Equal(c.secret, pass)
+			}
+		})
+	}
+}
+
+func Test_Config_Revoke(t *testing.T) {
+	cases := []struct {
+		name       string
+		secret     string
+		in         *oauth2.RevokeInput
+		statusCode int
+		body       string
+		wantErr    bool
+		wantAPIErr bool
+		expectHint string
+	}{
+		{
+			name:       "ok: access token",
+			in:         &oauth2.RevokeInput{Token: "access"},
+			statusCode: http.StatusOK,
+			body:       `{"revoked":true}`,
+			expectHint: "access_token",
+		},
+		{
+			name:       "ok: refresh token of confidential client",
+			secret:     "secret",
+			in:         &oauth2.RevokeInput{Token: "refresh", TokenTypeHint: oauth2.TokenTypeHintRefreshToken},
+			statusCode: http.StatusOK,
+			body:       `{"revoked":true}`,
+			expectHint: "refresh_token",
+		},
+		{
+			name:       "error: api error",
+			in:         &oauth2.RevokeInput{Token: "access"},
+			statusCode: http.StatusBadRequest,
+			body:       `{"error":"invalid_request","error_description":"Missing required parameter [token]."}`,
+			wantErr:    true,
+			wantAPIErr: true,
+		},
+		{
+			name:    "error: nil input",
+			in:      nil,
+			wantErr: true,
+		},
+		{
+			name:    "error: no token",
+			in:      &oauth2.RevokeInput{},
+			wantErr: true,
+		},
+		{
+			name:    "error: invalid hint",
+			in:      &oauth2.RevokeInput{Token: "access", TokenTypeHint: "id_token"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			var got *http.Request
+			var form url.Values
+			cfg, err := oauth2.NewConfig(&oauth2.NewConfigInput{
+				HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+					got = req
+					b, _ := io.ReadAll(req.Body)
+					form, _ = url.ParseQuery(string(b))
+					return newJSONResponse(c.statusCode, c.body)
+				}),
+				ClientID:     "client-id",
+				ClientSecret: c.secret,
+				RedirectURL:  "https://example.com/callback",
+			})
+			asst.NoError(err)
+
+			out, err := cfg.Revoke(context.Background(), c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(out)
+
+				var ge *gotwi.GotwiError
+				asst.Equal(c.wantAPIErr, errors.As(err, &ge))
+				return
+			}
+
+			asst.NoError(err)
+			asst.True(out.Revoked)
+			asst.Equal(gotwi.DefaultBaseURL+oauth2.RevokePath, got.URL.String())
+			asst.Equal(c.in.Token, form.Get("token"))
+			asst.Equal(c.expectHint, form.Get("token_type_hint"))
+			asst.Equal("client-id", form.Get("client_id"))
+
+			_, _, ok := got.BasicAuth()
+			asst.Equal(c.secret != "", ok)
+		})
+	}
+}
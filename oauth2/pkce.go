@@ -0,0 +1,86 @@
+package oauth2
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// CodeChallengeMethod is the method used to derive a code challenge from a code verifier.
+type CodeChallengeMethod string
+
+const (
+	CodeChallengeMethodS256  CodeChallengeMethod = "S256"
+	CodeChallengeMethodPlain CodeChallengeMethod = "plain"
+)
+
+func (m CodeChallengeMethod) Valid() bool {
+	return m == CodeChallengeMethodS256 || m == CodeChallengeMethodPlain
+}
+
+const (
+	codeVerifierMinLength = 43
+	codeVerifierMaxLength = 128
+)
+
+// GenerateCodeVerifier returns a random code verifier of 43 characters.
+// https://datatracker.ietf.org/doc/html/rfc7636#section-4.1
+func GenerateCodeVerifier() (string, error) {
+	return randomString(32)
+}
+
+// GenerateState returns a random value for the state parameter of the authorize URL.
+func GenerateState() (string, error) {
+	return randomString(16)
+}
+
+// CodeChallenge derives the code challenge for verifier with method.
+func CodeChallenge(verifier string, method CodeChallengeMethod) (string, error) {
+	if err := validCodeVerifier(verifier); err != nil {
+		return "", err
+	}
+
+	switch method {
+	case CodeChallengeMethodS256:
+		sum := sha256.Sum256([]byte(verifier))
+		return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+	case CodeChallengeMethodPlain:
+		return verifier, nil
+	}
+
+	return "", fmt.Errorf("CodeChallengeMethod '%s' is invalid.", method)
+}
+
+func validCodeVerifier(v string) error {
+	if len(v) < codeVerifierMinLength || len(v) > codeVerifierMaxLength {
+		return fmt.Errorf("CodeVerifier must be %d to %d characters long.", codeVerifierMinLength, codeVerifierMaxLength)
+	}
+
+	for _, r := range v {
+		if !isUnreserved(r) {
+			return fmt.Errorf("CodeVerifier contains an invalid character '%c'.", r)
+		}
+	}
+
+	return nil
+}
+
+// isUnreserved reports whether r is an unreserved character of RFC 3986.
+func isUnreserved(r rune) bool {
+	switch {
+	case 'A' <= r && r <= 'Z', 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+		return true
+	case r == '-', r == '.', r == '_', r == '~':
+		return true
+	}
+	return false
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,89 @@
+package oauth2_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi/oauth2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GenerateCodeVerifier(t *testing.T) {
+	asst := assert.New(t)
+
+	v1, err := oauth2.GenerateCodeVerifier()
+	asst.NoError(err)
+	asst.Len(v1, 43)
+
+	v2, err := oauth2.GenerateCodeVerifier()
+	asst.NoError(err)
+	asst.NotEqual(v1, v2)
+
+	_, err = oauth2.CodeChallenge(v1, oauth2.CodeChallengeMethodS256)
+	asst.NoError(err)
+}
+
+func Test_CodeChallenge(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mJ92K1Pmd0VvFZ9DM0kOa1KCg8gvHM"
+
+	cases := []struct {
+		name     string
+		verifier string
+		method   oauth2.CodeChallengeMethod
+		wantErr  bool
+		expect   string
+	}{
+		{
+			name:     "ok: S256",
+			verifier: verifier,
+			method:   oauth2.CodeChallengeMethodS256,
+			expect:   "XCL2DYIxkc6OWC88rL0tDcOHLtDISzWiYZrMVkUs7yQ",
+		},
+		{
+			name:     "ok: plain",
+			verifier: verifier,
+			method:   oauth2.CodeChallengeMethodPlain,
+			expect:   verifier,
+		},
+		{
+			name:     "error: unknown method",
+			verifier: verifier,
+			method:   "S512",
+			wantErr:  true,
+		},
+		{
+			name:     "error: too short verifier",
+			verifier: "short",
+			method:   oauth2.CodeChallengeMethodS256,
+			wantErr:  true,
+		},
+		{
+			name:     "error: too long verifier",
+			verifier: strings.Repeat("a", 129),
+			method:   oauth2.CodeChallengeMethodS256,
+			wantErr:  true,
+		},
+		{
+			name:     "error: invalid character",
+			verifier: strings.Repeat("a", 42) + "+",
+			method:   oauth2.CodeChallengeMethodS256,
+			wantErr:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			cc, err := oauth2.CodeChallenge(c.verifier, c.method)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Empty(cc)
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal(c.expect, cc)
+		})
+	}
+}
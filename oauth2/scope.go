@@ -0,0 +1,45 @@
+package oauth2
+
+import "strings"
+
+// Scope is a permission requested in the OAuth 2.0 Authorization Code with PKCE flow.
+// https://developer.twitter.com/en/docs/authentication/oauth-2-0/authorization-code
+type Scope string
+
+const (
+	ScopeTweetRead          Scope = "tweet.read"
+	ScopeTweetWrite         Scope = "tweet.write"
+	ScopeTweetModerateWrite Scope = "tweet.moderate.write"
+	ScopeUsersRead          Scope = "users.read"
+	ScopeFollowsRead        Scope = "follows.read"
+	ScopeFollowsWrite       Scope = "follows.write"
+	ScopeOfflineAccess      Scope = "offline.access"
+	ScopeSpaceRead          Scope = "space.read"
+	ScopeMuteRead           Scope = "mute.read"
+	ScopeMuteWrite          Scope = "mute.write"
+	ScopeLikeRead           Scope = "like.read"
+	ScopeLikeWrite          Scope = "like.write"
+	ScopeListRead           Scope = "list.read"
+	ScopeListWrite          Scope = "list.write"
+	ScopeBlockRead          Scope = "block.read"
+	ScopeBlockWrite         Scope = "block.write"
+	ScopeBookmarkRead       Scope = "bookmark.read"
+	ScopeBookmarkWrite      Scope = "bookmark.write"
+	ScopeDMRead             Scope = "dm.read"
+	ScopeDMWrite            Scope = "dm.write"
+)
+
+func (s Scope) String() string {
+	return string(s)
+}
+
+func joinScopes(scopes []Scope) string {
+	ss := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		if s == "" {
+			continue
+		}
+		ss = append(ss, s.String())
+	}
+	return strings.Join(ss, " ")
+}
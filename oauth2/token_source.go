@@ -0,0 +1,152 @@
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+)
+
+const defaultRefreshBefore = time.Duration(1) * time.Minute
+
+var (
+	_ gotwi.TokenSource      = (*RefreshingTokenSource)(nil)
+	_ gotwi.TokenInvalidator = (*RefreshingTokenSource)(nil)
+)
+
+type NewRefreshingTokenSourceInput struct {
+	Config *Config
+
+	// Token is the token to start with. It needs a refresh token
+	// unless it does not expire.
+	Token *Token
+
+	// RefreshBefore is how long before its expiry the token is refreshed. Default is 1 minute.
+	RefreshBefore time.Duration
+
+	// OnRefresh is called with every newly issued token, e.g. to persist the rotated refresh token.
+	// If it returns an error, Token returns the error, but the new token is used from then on.
+	OnRefresh func(ctx context.Context, t *Token) error
+}
+
+// RefreshingTokenSource is a gotwi.TokenSource that refreshes the access token with
+// the refresh token before it expires, or after the API rejected it with 401 Unauthorized.
+// It is safe for concurrent use. Only one refresh runs at a time, and reads of
+// a valid token do not take a lock.
+type RefreshingTokenSource struct {
+	config        *Config
+	refreshBefore time.Duration
+	onRefresh     func(ctx context.Context, t *Token) error
+	now           func() time.Time
+
+	mu      sync.Mutex
+	current atomic.Pointer[sourceToken]
+}
+
+type sourceToken struct {
+	token       Token
+	invalidated bool
+}
+
+func NewRefreshingTokenSource(in *NewRefreshingTokenSourceInput) (*RefreshingTokenSource, error) {
+	if in == nil {
+		return nil, fmt.Errorf("NewRefreshingTokenSourceInput is nil.")
+	}
+
+	if in.Config == nil {
+		return nil, fmt.Errorf("Config is nil.")
+	}
+
+	if in.Token == nil || (in.Token.AccessToken == "" && in.Token.RefreshToken == "") {
+		return nil, fmt.Errorf("Token must have an access token or a refresh token.")
+	}
+
+	rb := in.RefreshBefore
+	if rb <= 0 {
+		rb = defaultRefreshBefore
+	}
+
+	s := &RefreshingTokenSource{
+		config:        in.Config,
+		refreshBefore: rb,
+		onRefresh:     in.OnRefresh,
+		now:           time.Now,
+	}
+	s.current.Store(&sourceToken{token: *in.Token})
+
+	return s, nil
+}
+
+// Token returns a valid access token, refreshing it if needed.
+func (s *RefreshingTokenSource) Token(ctx context.Context) (string, error) {
+	if st := s.current.Load(); s.valid(st) {
+		return st.token.AccessToken, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// another goroutine may have refreshed it while waiting for the lock
+	st := s.current.Load()
+	if s.valid(st) {
+		return st.token.AccessToken, nil
+	}
+
+	if st.token.RefreshToken == "" {
+		return "", fmt.Errorf("The access token has expired and there is no refresh token.")
+	}
+
+	t, err := s.config.Refresh(ctx, &RefreshInput{RefreshToken: st.token.RefreshToken})
+	if err != nil {
+		return "", err
+	}
+
+	if t.RefreshToken == "" {
+		t.RefreshToken = st.token.RefreshToken
+	}
+	s.current.Store(&sourceToken{token: *t})
+
+	if s.onRefresh != nil {
+		nt := *t
+		if err := s.onRefresh(ctx, &nt); err != nil {
+			return "", err
+		}
+	}
+
+	return t.AccessToken, nil
+}
+
+// InvalidateToken makes the next Token call refresh the token if token is still the current one.
+func (s *RefreshingTokenSource) InvalidateToken(token string) {
+	for {
+		st := s.current.Load()
+		if st.invalidated || st.token.AccessToken != token {
+			return
+		}
+
+		if s.current.CompareAndSwap(st, &sourceToken{token: st.token, invalidated: true}) {
+			return
+		}
+	}
+}
+
+// CurrentToken returns a copy of the latest token, including the rotated refresh token.
+func (s *RefreshingTokenSource) CurrentToken() *Token {
+	t := s.current.Load().token
+	return &t
+}
+
+func (s *RefreshingTokenSource) valid(st *sourceToken) bool {
+	if st.invalidated || st.token.AccessToken == "" {
+		return false
+	}
+
+	if st.token.Expiry.IsZero() {
+		return true
+	}
+
+	return s.now().Add(s.refreshBefore).Before(st.token.Expiry)
+}
@@ -0,0 +1,249 @@
+package oauth2_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xxiiaaon/gotwi/oauth2"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRefreshConfig returns a config whose token endpoint issues "access-N" and "refresh-N"
+// for the N-th refresh and records the refresh tokens it received.
+func newRefreshConfig(t *testing.T, calls *int32, received *[]string) *oauth2.Config {
+	var mu sync.Mutex
+	cfg, err := oauth2.NewConfig(&oauth2.NewConfigInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			b, _ := io.ReadAll(req.Body)
+			form, _ := url.ParseQuery(string(b))
+			if form.Get("grant_type") != "refresh_token" {
+				return newJSONResponse(http.StatusBadRequest, `{"error":"invalid_request"}`)
+			}
+
+			mu.Lock()
+			*received = append(*received, form.Get("refresh_token"))
+			mu.Unlock()
+
+			n := atomic.AddInt32(calls, 1)
+			return newJSONResponse(http.StatusOK, fmt.Sprintf(
+				`{"token_type":"bearer","expires_in":7200,"access_token":"access-%d","refresh_token":"refresh-%d"}`, n, n))
+		}),
+		ClientID:    "client-id",
+		RedirectURL: "https://example.com/callback",
+	})
+	assert.NoError(t, err)
+	return cfg
+}
+
+func Test_NewRefreshingTokenSource(t *testing.T) {
+	cfg, err := oauth2.NewConfig(&oauth2.NewConfigInput{ClientID: "id", RedirectURL: "https://example.com/callback"})
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		in      *oauth2.NewRefreshingTokenSourceInput
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			in:   &oauth2.NewRefreshingTokenSourceInput{Config: cfg, Token: &oauth2.Token{AccessToken: "access"}},
+		},
+		{
+			name: "ok: refresh token only",
+			in:   &oauth2.NewRefreshingTokenSourceInput{Config: cfg, Token: &oauth2.Token{RefreshToken: "refresh"}},
+		},
+		{
+			name:    "error: nil input",
+			in:      nil,
+			wantErr: true,
+		},
+		{
+			name:    "error: nil config",
+			in:      &oauth2.NewRefreshingTokenSourceInput{Token: &oauth2.Token{AccessToken: "access"}},
+			wantErr: true,
+		},
+		{
+			name:    "error: nil token",
+			in:      &oauth2.NewRefreshingTokenSourceInput{Config: cfg},
+			wantErr: true,
+		},
+		{
+			name:    "error: empty token",
+			in:      &oauth2.NewRefreshingTokenSourceInput{Config: cfg, Token: &oauth2.Token{}},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			s, err := oauth2.NewRefreshingTokenSource(c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(s)
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal(c.in.Token, s.CurrentToken())
+		})
+	}
+}
+
+func Test_RefreshingTokenSource_Token(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name          string
+		token         *oauth2.Token
+		invalidate    string
+		onRefreshErr  error
+		wantErr       bool
+		expect        string
+		expectCalls   int32
+		expectRefresh string
+	}{
+		{
+			name:   "ok: valid token",
+			token:  &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: now.Add(time.Hour)},
+			expect: "access",
+		},
+		{
+			name:   "ok: token without expiry",
+			token:  &oauth2.Token{AccessToken: "access"},
+			expect: "access",
+		},
+		{
+			name:          "ok: expired token",
+			token:         &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: now.Add(-time.Hour)},
+			expect:        "access-1",
+			expectCalls:   1,
+			expectRefresh: "refresh-1",
+		},
+		{
+			name:          "ok: token about to expire",
+			token:         &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: now.Add(time.Duration(30) * time.Second)},
+			expect:        "access-1",
+			expectCalls:   1,
+			expectRefresh: "refresh-1",
+		},
+		{
+			name:          "ok: invalidated token",
+			token:         &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: now.Add(time.Hour)},
+			invalidate:    "access",
+			expect:        "access-1",
+			expectCalls:   1,
+			expectRefresh: "refresh-1",
+		},
+		{
+			name:       "ok: invalidated token is stale",
+			token:      &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: now.Add(time.Hour)},
+			invalidate: "old-access",
+			expect:     "access",
+		},
+		{
+			name:    "error: no refresh token",
+			token:   &oauth2.Token{AccessToken: "access", Expiry: now.Add(-time.Hour)},
+			wantErr: true,
+		},
+		{
+			name:          "error: on refresh failed",
+			token:         &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: now.Add(-time.Hour)},
+			onRefreshErr:  errors.New("persist failed"),
+			wantErr:       true,
+			expectCalls:   1,
+			expectRefresh: "refresh-1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			var calls int32
+			received := []string{}
+			persisted := []string{}
+			s, err := oauth2.NewRefreshingTokenSource(&oauth2.NewRefreshingTokenSourceInput{
+				Config: newRefreshConfig(tt, &calls, &received),
+				Token:  c.token,
+				OnRefresh: func(ctx context.Context, t *oauth2.Token) error {
+					persisted = append(persisted, t.RefreshToken)
+					return c.onRefreshErr
+				},
+			})
+			asst.NoError(err)
+			s.SetNow(func() time.Time { return now })
+
+			if c.invalidate != "" {
+				s.InvalidateToken(c.invalidate)
+			}
+
+			tok, err := s.Token(context.Background())
+			if c.wantErr {
+				asst.Error(err)
+				asst.Empty(tok)
+			} else {
+				asst.NoError(err)
+				asst.Equal(c.expect, tok)
+			}
+
+			asst.Equal(c.expectCalls, calls)
+			if c.expectCalls > 0 {
+				asst.Equal([]string{"refresh"}, received)
+				asst.Equal([]string{c.expectRefresh}, persisted)
+				asst.Equal(c.expectRefresh, s.CurrentToken().RefreshToken)
+			}
+		})
+	}
+}
+
+func Test_RefreshingTokenSource_Concurrent(t *testing.T) {
+	asst := assert.New(t)
+
+	var calls int32
+	received := []string{}
+	s, err := oauth2.NewRefreshingTokenSource(&oauth2.NewRefreshingTokenSourceInput{
+		Config: newRefreshConfig(t, &calls, &received),
+		Token:  &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)},
+	})
+	asst.NoError(err)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 20)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = s.Token(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	asst.Equal(int32(1), calls)
+	for _, tok := range tokens {
+		asst.Equal("access-1", tok)
+	}
+
+	// concurrent 401s for the same token refresh it only once
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.InvalidateToken("access-1")
+			_, _ = s.Token(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	asst.Equal(int32(2), calls)
+	asst.Equal([]string{"refresh", "refresh-1"}, received)
+}
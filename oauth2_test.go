@@ -1,6 +1,11 @@
 package gotwi_test
 
 import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/xxiiaaon/gotwi"
@@ -85,3 +90,84 @@ func Test_GenerateBearerToken(t *testing.T) {
 		})
 	}
 }
+
+func Test_InvalidateBearerToken(t *testing.T) {
+	cases := []struct {
+		name        string
+		token       string
+		statusCode  int
+		body        string
+		wantErr     bool
+		wantAPIErr  bool
+		expectCalls int
+	}{
+		{
+			name:        "ok",
+			token:       "bearer-token",
+			statusCode:  http.StatusOK,
+			body:        `{"access_token":"bearer-token"}`,
+			expectCalls: 1,
+		},
+		{
+			name:        "error: api error",
+			token:       "bearer-token",
+			statusCode:  http.StatusForbidden,
+			body:        `{"errors":[{"code":348,"message":"Client application is not permitted to to invalidate this token."}]}`,
+			wantErr:     true,
+			wantAPIErr:  true,
+			expectCalls: 1,
+		},
+		{
+			name:        "error: empty token",
+			token:       "",
+			wantErr:     true,
+			expectCalls: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			calls := 0
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+					calls++
+					asst.Equal(gotwi.DefaultBaseURL+gotwi.OAuth2InvalidateTokenPath, req.URL.String())
+
+					user, pass, ok := req.BasicAuth()
+					asst.True(ok)
+					asst.Equal("key", user)
+					asst.Equal("sec", pass)
+
+					b, _ := io.ReadAll(req.Body)
+					form, _ := url.ParseQuery(string(b))
+					asst.Equal(c.token, form.Get("access_token"))
+
+					return &http.Response{
+						StatusCode: c.statusCode,
+						Header:     map[string][]string{"Content-Type": {"application/json"}},
+						Body:       io.NopCloser(strings.NewReader(c.body)),
+					}
+				}),
+				AccessToken: "token",
+			})
+			asst.NoError(err)
+
+			res, err := gotwi.InvalidateBearerToken(client, "key", "sec", c.token)
+			asst.Equal(c.expectCalls, calls)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(res)
+
+				var ge *gotwi.GotwiError
+				asst.True(errors.As(err, &ge))
+				asst.Equal(c.wantAPIErr, ge.OnAPI)
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal(c.token, res.AccessToken)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package gotwi
+
+import (
+	"context"
+	"iter"
+
+	"github.com/xxiiaaon/gotwi/internal/util"
+)
+
+// PaginationParameters is implemented by the input of every paginated endpoint,
+// e.g. searchtweet/types.ListRecentInput.
+type PaginationParameters interface {
+	util.Parameters
+	SetPaginationToken(token string)
+}
+
+// PaginationResponse is implemented by the output of every paginated endpoint,
+// e.g. searchtweet/types.ListRecentOutput.
+type PaginationResponse interface {
+	util.Response
+	NextPaginationToken() string
+	ResultCount() int
+}
+
+type PagesOption struct {
+	// MaxPages stops the iteration after this many pages. Zero means no limit.
+	MaxPages int
+
+	// MaxItems stops requesting pages once at least this many items have been returned.
+	// It is not an exact cap: pages are not split, so the items of the last page may
+	// exceed it by up to a page. Set the max_results of the input to fetch fewer items
+	// per page. Zero means no limit.
+	MaxItems int
+}
+
+// Pages returns an iterator that calls fn with p and follows the next token of each
+// page until there are no more pages, a limit in opt is reached, or an error occurs.
+// An error (including the cancellation of ctx) is yielded once and ends the iteration.
+// A *PartialErrors is yielded together with its page and does not end the iteration.
+// The pagination token of p is overwritten while iterating.
+//
+//	for out, err := range gotwi.Pages(ctx, c, p, searchtweet.ListRecent, nil) {
+//		if err != nil {
+//			return err
+//		}
+//		// use out.Data
+//	}
+func Pages[P PaginationParameters, R PaginationResponse](ctx context.Context, c *Client, p P, fn func(context.Context, *Client, P) (R, error), opt *PagesOption) iter.Seq2[R, error] {
+	if opt == nil {
+		opt = &PagesOption{}
+	}
+
+	return func(yield func(R, error) bool) {
+		var zero R
+		pages, items := 0, 0
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, wrapErr(err))
+				return
+			}
+
+			out, err := fn(ctx, c, p)
+			if err != nil && !IsPartialErrors(err) {
+				yield(zero, err)
+				return
+			}
+
+			if !yield(out, err) {
+				return
+			}
+
+			pages++
+			items += out.ResultCount()
+			if opt.MaxPages > 0 && pages >= opt.MaxPages {
+				return
+			}
+			if opt.MaxItems > 0 && items >= opt.MaxItems {
+				return
+			}
+
+			next := out.NextPaginationToken()
+			if next == "" {
+				return
+			}
+			p.SetPaginationToken(next)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package gotwi_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/tweet/searchtweet"
+	"github.com/xxiiaaon/gotwi/tweet/searchtweet/types"
+	"github.com/xxiiaaon/gotwi/tweet/timeline"
+	timelinetypes "github.com/xxiiaaon/gotwi/tweet/timeline/types"
+	"github.com/xxiiaaon/gotwi/user/follow"
+	followtypes "github.com/xxiiaaon/gotwi/user/follow/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPagesMockClient(pages int, tokens *[]string) *http.Client {
+	return newMockClient(func(req *http.Request) *http.Response {
+		q := req.URL.Query()
+		token := q.Get("next_token")
+		if token == "" {
+			token = q.Get("pagination_token")
+		}
+		*tokens = append(*tokens, token)
+		n := len(*tokens)
+
+		meta := `"meta":{"result_count":2}`
+		if n < pages {
+			meta = fmt.Sprintf(`"meta":{"result_count":2,"next_token":"token%d"}`, n)
+		}
+		body := fmt.Sprintf(`{"data":[{"id":"%d-1"},{"id":"%d-2"}],%s}`, n, n, meta)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	})
+}
+
+func Test_Pages(t *testing.T) {
+	cases := []struct {
+		name         string
+		pages        int
+		opt          *gotwi.PagesOption
+		expectTokens []string
+		expectItems  int
+	}{
+		{
+			name:         "ok: all pages",
+			pages:        3,
+			expectTokens: []string{"", "token1", "token2"},
+			expectItems:  6,
+		},
+		{
+			name:         "ok: single page",
+			pages:        1,
+			expectTokens: []string{""},
+			expectItems:  2,
+		},
+		{
+			name:         "ok: max pages",
+			pages:        5,
+			opt:          &gotwi.PagesOption{MaxPages: 2},
+			expectTokens: []string{"", "token1"},
+			expectItems:  4,
+		},
+		{
+			name:         "ok: max items",
+			pages:        5,
+			opt:          &gotwi.PagesOption{MaxItems: 3},
+			expectTokens: []string{"", "token1"},
+			expectItems:  4,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			tokens := []string{}
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient:  newPagesMockClient(c.pages, &tokens),
+				AccessToken: "token",
+			})
+			asst.NoError(err)
+
+			items := 0
+			p := &types.ListRecentInput{Query: "gotwi"}
+			for out, err := range gotwi.Pages(context.Background(), client, p, searchtweet.ListRecent, c.opt) {
+				asst.NoError(err)
+				items += len(out.Data)
+			}
+
+			asst.Equal(c.expectTokens, tokens)
+			asst.Equal(c.expectItems, items)
+		})
+	}
+}
+
+// pageTokens iterates 3 pages of fn and returns the requested tokens and the number of items.
+func pageTokens[P gotwi.PaginationParameters, R gotwi.PaginationResponse](t *testing.T, p P, fn func(context.Context, *gotwi.Client, P) (R, error)) ([]string, int) {
+	tokens := []string{}
+	client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient:  newPagesMockClient(3, &tokens),
+		AccessToken: "token",
+	})
+	assert.NoError(t, err)
+
+	items := 0
+	for out, err := range gotwi.Pages(context.Background(), client, p, fn, nil) {
+		assert.NoError(t, err)
+		items += out.ResultCount()
+	}
+
+	return tokens, items
+}
+
+// Test_Pages_Types checks that the inputs and outputs of other endpoints, which
+// use pagination_token instead of next_token, work with Pages.
+func Test_Pages_Types(t *testing.T) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T) ([]string, int)
+	}{
+		{
+			name: "timeline.ListTweets",
+			run: func(t *testing.T) ([]string, int) {
+				return pageTokens(t, &timelinetypes.ListTweetsInput{ID: "1"}, timeline.ListTweets)
+			},
+		},
+		{
+			name: "follow.ListFollowers",
+			run: func(t *testing.T) ([]string, int) {
+				return pageTokens(t, &followtypes.ListFollowersInput{ID: "1"}, follow.ListFollowers)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			tokens, items := c.run(tt)
+			asst.Equal([]string{"", "token1", "token2"}, tokens)
+			asst.Equal(6, items)
+		})
+	}
+}
+
+func Test_Pages_Break(t *testing.T) {
+	asst := assert.New(t)
+
+	tokens := []string{}
+	client, _ := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient:  newPagesMockClient(5, &tokens),
+		AccessToken: "token",
+	})
+
+	for range gotwi.Pages(context.Background(), client, &types.ListRecentInput{Query: "gotwi"}, searchtweet.ListRecent, nil) {
+		break
+	}
+
+	asst.Len(tokens, 1)
+}
+
+func Test_Pages_ContextCanceled(t *testing.T) {
+	asst := assert.New(t)
+
+	tokens := []string{}
+	client, _ := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient:  newPagesMockClient(5, &tokens),
+		AccessToken: "token",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var errs []error
+	for _, err := range gotwi.Pages(ctx, client, &types.ListRecentInput{Query: "gotwi"}, searchtweet.ListRecent, nil) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		cancel()
+	}
+
+	asst.Len(tokens, 1)
+	asst.Len(errs, 1)
+	asst.ErrorIs(errs[0], context.Canceled)
+}
+
+func Test_Pages_Error(t *testing.T) {
+	asst := assert.New(t)
+
+	calls := 0
+	client, _ := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient:  newSequentialMockClient([]mockResponse{{statusCode: http.StatusBadRequest, body: `{}`}}, &calls),
+		AccessToken: "token",
+	})
+
+	n := 0
+	for out, err := range gotwi.Pages(context.Background(), client, &types.ListRecentInput{Query: "gotwi"}, searchtweet.ListRecent, nil) {
+		asst.Error(err)
+		asst.Nil(out)
+		n++
+	}
+
+	asst.Equal(1, n)
+	asst.Equal(1, calls)
+}
+
+func Test_Pages_PartialErrors(t *testing.T) {
+	asst := assert.New(t)
+
+	calls := 0
+	client, _ := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newSequentialMockClient([]mockResponse{
+			{statusCode: http.StatusOK, body: `{"data":[{"id":"1"}],"errors":[{"resource_id":"2","title":"Not Found Error"}],"meta":{"result_count":1,"next_token":"token1"}}`},
+			{statusCode: http.StatusOK, body: `{"data":[{"id":"3"}],"meta":{"result_count":1}}`},
+		}, &calls),
+		AccessToken:         "token",
+		ReturnPartialErrors: true,
+	})
+
+	errs := []bool{}
+	for out, err := range gotwi.Pages(context.Background(), client, &types.ListRecentInput{Query: "gotwi"}, searchtweet.ListRecent, nil) {
+		asst.NotNil(out)
+		errs = append(errs, gotwi.IsPartialErrors(err))
+	}
+
+	asst.Equal([]bool{true, false}, errs)
+	asst.Equal(2, calls)
+}
@@ -0,0 +1,242 @@
+package gotwi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/xxiiaaon/gotwi/resources"
+)
+
+// PartialErrorCategory classifies a resources.PartialError by its problem type.
+type PartialErrorCategory string
+
+const (
+	PartialErrorNotFound            PartialErrorCategory = "resource-not-found"
+	PartialErrorAuthorization       PartialErrorCategory = "not-authorized-for-resource"
+	PartialErrorResourceUnavailable PartialErrorCategory = "resource-unavailable"
+	PartialErrorOther               PartialErrorCategory = "other"
+)
+
+// partialErrorTitles is used when a partial error has no Type URI.
+var partialErrorTitles = map[string]PartialErrorCategory{
+	"Not Found Error":      PartialErrorNotFound,
+	"Authorization Error":  PartialErrorAuthorization,
+	"Forbidden":            PartialErrorAuthorization,
+	"Resource Unavailable": PartialErrorResourceUnavailable,
+}
+
+// CategoryOf returns the category of a partial error returned in the Errors field of an output.
+func CategoryOf(pe resources.PartialError) PartialErrorCategory {
+	if pe.Type != nil {
+		switch PartialErrorCategory(*pe.Type) {
+		case problemTypeBase + PartialErrorNotFound:
+			return PartialErrorNotFound
+		case problemTypeBase + PartialErrorAuthorization:
+			return PartialErrorAuthorization
+		case problemTypeBase + PartialErrorResourceUnavailable:
+			return PartialErrorResourceUnavailable
+		}
+	}
+
+	if pe.Title != nil {
+		if cat, ok := partialErrorTitles[*pe.Title]; ok {
+			return cat
+		}
+	}
+
+	return PartialErrorOther
+}
+
+// PartialErrors is returned by CallAPI, together with the data, when the API
+// succeeded for only some of the requested resources and the client was created
+// with ReturnPartialErrors. Match it with errors.As.
+//
+//	out, err := tweetlookup.List(ctx, c, p)
+//	var pe *gotwi.PartialErrors
+//	if errors.As(err, &pe) {
+//		found, missing := pe.Split(p.IDs, idsOf(out.Data))
+//		// out.Data contains the found Tweets
+//	} else if err != nil {
+//		return err
+//	}
+type PartialErrors struct {
+	errors     []resources.PartialError
+	byResource map[string][]int
+}
+
+// NewPartialErrors indexes errs by resource ID. It is also useful for the
+// Errors field of outputs received without ReturnPartialErrors.
+func NewPartialErrors(errs []resources.PartialError) *PartialErrors {
+	pe := &PartialErrors{
+		errors:     errs,
+		byResource: map[string][]int{},
+	}
+
+	for i, e := range errs {
+		if id, ok := resourceIDOf(e); ok {
+			pe.byResource[id] = append(pe.byResource[id], i)
+		}
+	}
+
+	return pe
+}
+
+// resourceIDOf returns the resource ID of e. Errors for lookups by user name carry
+// the user name in Value instead of ResourceID.
+func resourceIDOf(e resources.PartialError) (string, bool) {
+	if e.ResourceID != nil && *e.ResourceID != "" {
+		return *e.ResourceID, true
+	}
+	if e.Value != nil && *e.Value != "" {
+		return *e.Value, true
+	}
+	return "", false
+}
+
+func (p *PartialErrors) Error() string {
+	if p == nil || len(p.errors) == 0 {
+		return "no partial errors"
+	}
+
+	first := p.errors[0]
+	detail := ""
+	if first.Detail != nil {
+		detail = *first.Detail
+	} else if first.Title != nil {
+		detail = *first.Title
+	}
+
+	if len(p.errors) == 1 {
+		return fmt.Sprintf("partial error: %s: %s", CategoryOf(first), detail)
+	}
+	return fmt.Sprintf("%d partial errors, first: %s: %s", len(p.errors), CategoryOf(first), detail)
+}
+
+// Errors returns all the partial errors in the order of the response.
+func (p *PartialErrors) Errors() []resources.PartialError {
+	if p == nil {
+		return nil
+	}
+	return p.errors
+}
+
+func (p *PartialErrors) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.errors)
+}
+
+// ResourceIDs returns the IDs of the resources that have at least one partial error.
+func (p *PartialErrors) ResourceIDs() []string {
+	if p == nil {
+		return nil
+	}
+
+	ids := []string{}
+	seen := map[string]struct{}{}
+	for _, e := range p.errors {
+		id, ok := resourceIDOf(e)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// ByResourceID returns the partial errors of the resource.
+func (p *PartialErrors) ByResourceID(id string) []resources.PartialError {
+	if p == nil {
+		return nil
+	}
+
+	idx := p.byResource[id]
+	if len(idx) == 0 {
+		return nil
+	}
+
+	errs := make([]resources.PartialError, 0, len(idx))
+	for _, i := range idx {
+		errs = append(errs, p.errors[i])
+	}
+	return errs
+}
+
+// Category returns the category of the first partial error of the resource.
+// ok is false if the resource has no partial error.
+func (p *PartialErrors) Category(id string) (PartialErrorCategory, bool) {
+	errs := p.ByResourceID(id)
+	if len(errs) == 0 {
+		return "", false
+	}
+	return CategoryOf(errs[0]), true
+}
+
+// ByCategory returns the partial errors of the category.
+func (p *PartialErrors) ByCategory(cat PartialErrorCategory) []resources.PartialError {
+	if p == nil {
+		return nil
+	}
+
+	errs := []resources.PartialError{}
+	for _, e := range p.errors {
+		if CategoryOf(e) == cat {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+// Split splits the requested IDs into those in returned, the IDs of the data, and the
+// others. A missing ID does not always have a partial error, in which case Category
+// reports false for it. The order of requested is kept.
+func (p *PartialErrors) Split(requested, returned []string) (found, missing []string) {
+	inData := make(map[string]struct{}, len(returned))
+	for _, id := range returned {
+		inData[id] = struct{}{}
+	}
+
+	found, missing = []string{}, []string{}
+	for _, id := range requested {
+		if _, ok := inData[id]; ok {
+			found = append(found, id)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing
+}
+
+// Has reports whether the resource has a partial error.
+func (p *PartialErrors) Has(id string) bool {
+	if p == nil {
+		return false
+	}
+	_, ok := p.byResource[id]
+	return ok
+}
+
+// Found returns the requested IDs that are in returned.
+func (p *PartialErrors) Found(requested, returned []string) []string {
+	found, _ := p.Split(requested, returned)
+	return found
+}
+
+// Missing returns the requested IDs that are not in returned.
+func (p *PartialErrors) Missing(requested, returned []string) []string {
+	_, missing := p.Split(requested, returned)
+	return missing
+}
+
+// IsPartialErrors reports whether err is a *PartialErrors, in which case the
+// output returned with err holds the data of the resources found.
+func IsPartialErrors(err error) bool {
+	var pe *PartialErrors
+	return errors.As(err, &pe)
+}
@@ -0,0 +1,207 @@
+package gotwi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/resources"
+	"github.com/xxiiaaon/gotwi/tweet/tweetlookup"
+	"github.com/xxiiaaon/gotwi/tweet/tweetlookup/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const partialErrorsBody = `{
+	"data": [{"id": "1", "text": "found"}],
+	"errors": [
+		{"value": "2", "detail": "Could not find tweet with ids: [2].", "title": "Not Found Error", "resource_type": "tweet", "parameter": "ids", "resource_id": "2", "type": "https://api.twitter.com/2/problems/resource-not-found"},
+		{"value": "3", "detail": "Sorry, you are not authorized to see the Tweet with ids: [3].", "title": "Authorization Error", "resource_type": "tweet", "parameter": "ids", "resource_id": "3", "type": "https://api.twitter.com/2/problems/not-authorized-for-resource"}
+	]
+}`
+
+func Test_CategoryOf(t *testing.T) {
+	cases := []struct {
+		name   string
+		pe     resources.PartialError
+		expect gotwi.PartialErrorCategory
+	}{
+		{
+			name:   "not found",
+			pe:     resources.PartialError{Type: gotwi.String("https://api.twitter.com/2/problems/resource-not-found")},
+			expect: gotwi.PartialErrorNotFound,
+		},
+		{
+			name:   "authorization",
+			pe:     resources.PartialError{Type: gotwi.String("https://api.twitter.com/2/problems/not-authorized-for-resource")},
+			expect: gotwi.PartialErrorAuthorization,
+		},
+		{
+			name:   "resource unavailable",
+			pe:     resources.PartialError{Type: gotwi.String("https://api.twitter.com/2/problems/resource-unavailable")},
+			expect: gotwi.PartialErrorResourceUnavailable,
+		},
+		{
+			name:   "title only",
+			pe:     resources.PartialError{Title: gotwi.String("Not Found Error")},
+			expect: gotwi.PartialErrorNotFound,
+		},
+		{
+			name:   "unknown type",
+			pe:     resources.PartialError{Type: gotwi.String("https://api.twitter.com/2/problems/invalid-request")},
+			expect: gotwi.PartialErrorOther,
+		},
+		{
+			name:   "empty",
+			pe:     resources.PartialError{},
+			expect: gotwi.PartialErrorOther,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			assert.Equal(tt, c.expect, gotwi.CategoryOf(c.pe))
+		})
+	}
+}
+
+func Test_PartialErrors(t *testing.T) {
+	asst := assert.New(t)
+
+	pe := gotwi.NewPartialErrors([]resources.PartialError{
+		{ResourceID: gotwi.String("2"), Type: gotwi.String("https://api.twitter.com/2/problems/resource-not-found"), Detail: gotwi.String("Could not find tweet with ids: [2].")},
+		{ResourceID: gotwi.String("3"), Type: gotwi.String("https://api.twitter.com/2/problems/not-authorized-for-resource")},
+		{Value: gotwi.String("suspended_user"), Type: gotwi.String("https://api.twitter.com/2/problems/resource-unavailable")},
+		{Title: gotwi.String("Invalid Request")},
+	})
+
+	asst.Equal(4, pe.Len())
+	asst.Equal([]string{"2", "3", "suspended_user"}, pe.ResourceIDs())
+	asst.Equal("4 partial errors, first: resource-not-found: Could not find tweet with ids: [2].", pe.Error())
+
+	cat, ok := pe.Category("3")
+	asst.True(ok)
+	asst.Equal(gotwi.PartialErrorAuthorization, cat)
+	cat, ok = pe.Category("suspended_user")
+	asst.True(ok)
+	asst.Equal(gotwi.PartialErrorResourceUnavailable, cat)
+	_, ok = pe.Category("1")
+	asst.False(ok)
+
+	asst.Len(pe.ByResourceID("2"), 1)
+	asst.Nil(pe.ByResourceID("1"))
+	asst.Len(pe.ByCategory(gotwi.PartialErrorNotFound), 1)
+	asst.Len(pe.ByCategory(gotwi.PartialErrorOther), 1)
+
+	// 4 is absent from the data without a partial error
+	requested, returned := []string{"1", "2", "3", "4"}, []string{"1"}
+	found, missing := pe.Split(requested, returned)
+	asst.Equal([]string{"1"}, found)
+	asst.Equal([]string{"2", "3", "4"}, missing)
+	asst.Equal(found, pe.Found(requested, returned))
+	asst.Equal(missing, pe.Missing(requested, returned))
+	_, ok = pe.Category("4")
+	asst.False(ok)
+
+	var nilpe *gotwi.PartialErrors
+	asst.Equal(0, nilpe.Len())
+	asst.False(nilpe.Has("1"))
+	found, missing = nilpe.Split([]string{"1", "2"}, []string{"1"})
+	asst.Equal([]string{"1"}, found)
+	asst.Equal([]string{"2"}, missing)
+}
+
+func Test_CallAPI_PartialErrors(t *testing.T) {
+	cases := []struct {
+		name       string
+		enabled    bool
+		statusCode int
+		body       string
+		wantErr    bool
+		expectPE   bool
+		expectData bool
+	}{
+		{
+			name:       "ok: partial errors are returned with the data",
+			enabled:    true,
+			statusCode: http.StatusOK,
+			body:       partialErrorsBody,
+			wantErr:    true,
+			expectPE:   true,
+			expectData: true,
+		},
+		{
+			name:       "ok: disabled",
+			enabled:    false,
+			statusCode: http.StatusOK,
+			body:       partialErrorsBody,
+			wantErr:    false,
+			expectData: true,
+		},
+		{
+			name:       "ok: no partial errors",
+			enabled:    true,
+			statusCode: http.StatusOK,
+			body:       `{"data": [{"id": "1", "text": "found"}]}`,
+			wantErr:    false,
+			expectData: true,
+		},
+		{
+			name:       "error: not 200 response",
+			enabled:    true,
+			statusCode: http.StatusBadRequest,
+			body:       `{"title": "Invalid Request"}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+					return newMockResponse(c.statusCode, c.body)
+				}),
+				AccessToken:         "token",
+				ReturnPartialErrors: c.enabled,
+			})
+			asst.NoError(err)
+
+			ids := []string{"1", "2", "3"}
+			out, err := tweetlookup.List(context.Background(), client, &types.ListInput{IDs: ids})
+			if c.wantErr {
+				asst.Error(err)
+			} else {
+				asst.NoError(err)
+			}
+
+			asst.Equal(c.expectPE, gotwi.IsPartialErrors(err))
+			if c.expectPE {
+				var pe *gotwi.PartialErrors
+				asst.True(errors.As(err, &pe))
+				returned := []string{}
+				for _, d := range out.Data {
+					returned = append(returned, gotwi.StringValue(d.ID))
+				}
+				found, missing := pe.Split(ids, returned)
+				asst.Equal([]string{"1"}, found)
+				asst.Equal([]string{"2", "3"}, missing)
+				cat, _ := pe.Category("2")
+				asst.Equal(gotwi.PartialErrorNotFound, cat)
+
+				var ge *gotwi.GotwiError
+				asst.False(errors.As(err, &ge))
+			}
+
+			if c.expectData {
+				asst.NotNil(out)
+				asst.Len(out.Data, 1)
+				asst.Equal("1", gotwi.StringValue(out.Data[0].ID))
+			} else {
+				asst.Nil(out)
+			}
+		})
+	}
+}
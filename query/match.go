@@ -0,0 +1,505 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/resources"
+)
+
+// ErrUnsupportedOperator is returned by NewMatcher for operators that cannot be evaluated
+// from a Tweet and its includes.
+var ErrUnsupportedOperator = errors.New("Operator is not supported by Matcher.")
+
+// Includes are the expanded objects of a response. The Includes of the outputs can be
+// converted to it, e.g. query.Includes(out.Includes).
+type Includes struct {
+	Users  []resources.User
+	Tweets []resources.Tweet
+	Places []resources.Place
+	Media  []resources.Media
+	Polls  []resources.Poll
+}
+
+// Matcher evaluates a query against Tweets locally, e.g. to test a rule before creating it,
+// to filter stored Tweets, or to find the rules that matched a recorded stream message.
+//
+// Operators on users, media and places look the objects up in the Includes, so the Tweets
+// must have been requested with the matching expansions: author_id for from: and is:verified,
+// in_reply_to_user_id for to:, referenced_tweets.id and referenced_tweets.id.author_id for
+// retweets_of:, attachments.media_keys for has:images and has:videos, and geo.place_id for
+// place:, place_country:, bounding_box: and point_radius:. A Tweet whose objects are missing
+// does not match.
+//
+// Keywords and phrases are matched case-insensitively against the words of the text, split at
+// punctuation and symbols, like the API does. sample: and operators other than the constants of
+// Operator are not supported, and is:nullcast never matches.
+type Matcher struct {
+	expr Expr
+}
+
+// NewMatcher returns a Matcher of e. It returns ErrUnsupportedOperator if e contains an operator
+// that cannot be evaluated.
+func NewMatcher(e Expr) (*Matcher, error) {
+	if e == nil {
+		return nil, fmt.Errorf("Query is required.")
+	}
+
+	if err := checkMatchable(e); err != nil {
+		return nil, err
+	}
+
+	return &Matcher{expr: e}, nil
+}
+
+// Compile parses a query and returns its Matcher.
+func Compile(s string) (*Matcher, error) {
+	e, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMatcher(e)
+}
+
+// Expr returns the expression of the Matcher.
+func (m *Matcher) Expr() Expr {
+	return m.expr
+}
+
+// Match reports whether the Tweet matches. inc may be nil.
+func (m *Matcher) Match(t *resources.Tweet, inc *Includes) bool {
+	if t == nil {
+		return false
+	}
+
+	return match(m.expr, newTweetContext(t, inc))
+}
+
+// RuleMatcher finds the filtered stream rules that match a Tweet, like the MatchingRules
+// of the SearchStream output.
+type RuleMatcher struct {
+	rules    []resources.FilterdStreamRule
+	matchers []*Matcher
+}
+
+// NewRuleMatcher compiles the values of the rules. The error names the first rule
+// that cannot be parsed or evaluated.
+func NewRuleMatcher(rules []resources.FilterdStreamRule) (*RuleMatcher, error) {
+	rm := &RuleMatcher{
+		rules:    rules,
+		matchers: make([]*Matcher, 0, len(rules)),
+	}
+
+	for _, r := range rules {
+		m, err := Compile(gotwi.StringValue(r.Value))
+		if err != nil {
+			return nil, fmt.Errorf("rule %s (%s): %w", gotwi.StringValue(r.ID), gotwi.StringValue(r.Value), err)
+		}
+		rm.matchers = append(rm.matchers, m)
+	}
+
+	return rm, nil
+}
+
+// Match returns the rules that match the Tweet, in the order of the rules.
+func (rm *RuleMatcher) Match(t *resources.Tweet, inc *Includes) []resources.FilterdStreamRule {
+	matched := []resources.FilterdStreamRule{}
+	if t == nil {
+		return matched
+	}
+
+	tc := newTweetContext(t, inc)
+	for i, m := range rm.matchers {
+		if match(m.expr, tc) {
+			matched = append(matched, rm.rules[i])
+		}
+	}
+
+	return matched
+}
+
+func checkMatchable(e Expr) error {
+	switch e := e.(type) {
+	case Term:
+		switch e.Operator {
+		case OperatorKeyword, OperatorPhrase, OperatorFrom, OperatorTo, OperatorMention, OperatorHashtag,
+			OperatorCashtag, OperatorURL, OperatorRetweetsOf, OperatorConversationID, OperatorLang,
+			OperatorPlace, OperatorPlaceCountry:
+			return nil
+		case OperatorIs:
+			if _, ok := isFilters[e.Value]; ok {
+				return nil
+			}
+		case OperatorHas:
+			if _, ok := hasFilters[e.Value]; ok {
+				return nil
+			}
+		case OperatorBoundingBox:
+			return validateBoundingBox(e.Value)
+		case OperatorPointRadius:
+			return validatePointRadius(e.Value)
+		}
+		return fmt.Errorf("%s: %w", e, ErrUnsupportedOperator)
+	case AndExpr:
+		return checkMatchableAll(e.Exprs, "AND")
+	case OrExpr:
+		return checkMatchableAll(e.Exprs, "OR")
+	case NotExpr:
+		if e.Expr == nil {
+			return fmt.Errorf("Expression is nil.")
+		}
+		return checkMatchable(e.Expr)
+	case nil:
+		return fmt.Errorf("Expression is nil.")
+	default:
+		return fmt.Errorf("Expression type %T is not supported.", e)
+	}
+}
+
+func checkMatchableAll(exprs []Expr, name string) error {
+	if len(exprs) == 0 {
+		return fmt.Errorf("%s requires at least one expression.", name)
+	}
+
+	for _, e := range exprs {
+		if err := checkMatchable(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func match(e Expr, tc *tweetContext) bool {
+	switch e := e.(type) {
+	case Term:
+		return matchTerm(e, tc)
+	case AndExpr:
+		for _, x := range e.Exprs {
+			if !match(x, tc) {
+				return false
+			}
+		}
+		return true
+	case OrExpr:
+		for _, x := range e.Exprs {
+			if match(x, tc) {
+				return true
+			}
+		}
+		return false
+	case NotExpr:
+		return !match(e.Expr, tc)
+	default:
+		return false
+	}
+}
+
+func matchTerm(t Term, tc *tweetContext) bool {
+	switch t.Operator {
+	case OperatorKeyword, OperatorPhrase:
+		return containsWords(tc.words(), tokenize(t.Value))
+	case OperatorFrom:
+		return tc.isUser(tc.tweet.AuthorID, t.Value)
+	case OperatorTo:
+		return tc.isUser(tc.tweet.InReplyToUserID, t.Value)
+	case OperatorMention:
+		return containsMention(tc.entities().Mentions, t.Value)
+	case OperatorHashtag:
+		return containsTag(tc.entities().HashTags, t.Value)
+	case OperatorCashtag:
+		return containsTag(tc.entities().CashTags, t.Value)
+	case OperatorURL:
+		return tc.hasURL(t.Value)
+	case OperatorRetweetsOf:
+		rt, ok := tc.referenced("retweeted")
+		return ok && tc.isUser(rt.AuthorID, t.Value)
+	case OperatorConversationID:
+		return gotwi.StringValue(tc.tweet.ConversationID) == t.Value
+	case OperatorIs:
+		return tc.is(IsFilter(t.Value))
+	case OperatorHas:
+		return tc.has(HasFilter(t.Value))
+	case OperatorLang:
+		return strings.EqualFold(gotwi.StringValue(tc.tweet.Lang), t.Value)
+	case OperatorPlace:
+		p, ok := tc.place()
+		return ok && (gotwi.StringValue(p.ID) == t.Value ||
+			strings.EqualFold(gotwi.StringValue(p.Name), t.Value) ||
+			strings.Contains(strings.ToLower(gotwi.StringValue(p.FullName)), strings.ToLower(t.Value)))
+	case OperatorPlaceCountry:
+		p, ok := tc.place()
+		return ok && strings.EqualFold(gotwi.StringValue(p.CountryCode), t.Value)
+	case OperatorBoundingBox:
+		f, _ := parseFloats(strings.Fields(t.Value))
+		return tc.within(func(lon, lat float64) bool {
+			return lon >= f[0] && lon <= f[2] && lat >= f[1] && lat <= f[3]
+		})
+	case OperatorPointRadius:
+		return tc.within(pointRadiusContains(t.Value))
+	default:
+		return false
+	}
+}
+
+// tweetContext is a Tweet with its includes indexed.
+type tweetContext struct {
+	tweet  *resources.Tweet
+	users  map[string]*resources.User
+	tweets map[string]*resources.Tweet
+	media  map[string]*resources.Media
+	places map[string]*resources.Place
+	tokens []string
+}
+
+func newTweetContext(t *resources.Tweet, inc *Includes) *tweetContext {
+	tc := &tweetContext{
+		tweet:  t,
+		users:  map[string]*resources.User{},
+		tweets: map[string]*resources.Tweet{},
+		media:  map[string]*resources.Media{},
+		places: map[string]*resources.Place{},
+	}
+	if inc == nil {
+		return tc
+	}
+
+	for i := range inc.Users {
+		tc.users[gotwi.StringValue(inc.Users[i].ID)] = &inc.Users[i]
+	}
+	for i := range inc.Tweets {
+		tc.tweets[gotwi.StringValue(inc.Tweets[i].ID)] = &inc.Tweets[i]
+	}
+	for i := range inc.Media {
+		tc.media[gotwi.StringValue(inc.Media[i].MediaKey)] = &inc.Media[i]
+	}
+	for i := range inc.Places {
+		tc.places[gotwi.StringValue(inc.Places[i].ID)] = &inc.Places[i]
+	}
+
+	return tc
+}
+
+// text returns the full text of long Tweets.
+func (tc *tweetContext) text() string {
+	if tc.tweet.NoteTweet != nil && tc.tweet.NoteTweet.Text != nil {
+		return *tc.tweet.NoteTweet.Text
+	}
+	return gotwi.StringValue(tc.tweet.Text)
+}
+
+func (tc *tweetContext) words() []string {
+	if tc.tokens == nil {
+		tc.tokens = tokenize(tc.text())
+	}
+	return tc.tokens
+}
+
+func (tc *tweetContext) entities() resources.TweetEntities {
+	if nt := tc.tweet.NoteTweet; nt != nil && nt.Text != nil {
+		return resources.TweetEntities{
+			CashTags: nt.Entities.CashTags,
+			HashTags: nt.Entities.HashTags,
+			Mentions: nt.Entities.Mentions,
+			URLs:     nt.Entities.URLs,
+		}
+	}
+	if tc.tweet.Entities != nil {
+		return *tc.tweet.Entities
+	}
+	return resources.TweetEntities{}
+}
+
+// isUser reports whether the user of id has the user name or the ID.
+func (tc *tweetContext) isUser(id *string, user string) bool {
+	if id == nil {
+		return false
+	}
+	if *id == user {
+		return true
+	}
+
+	u, ok := tc.users[*id]
+	return ok && strings.EqualFold(gotwi.StringValue(u.Username), user)
+}
+
+func (tc *tweetContext) referenced(typ string) (*resources.Tweet, bool) {
+	for _, r := range tc.tweet.ReferencedTweets {
+		if gotwi.StringValue(r.Type) != typ {
+			continue
+		}
+		t, ok := tc.tweets[gotwi.StringValue(r.ID)]
+		return t, ok
+	}
+	return nil, false
+}
+
+func (tc *tweetContext) isReferencing(typ string) bool {
+	for _, r := range tc.tweet.ReferencedTweets {
+		if gotwi.StringValue(r.Type) == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (tc *tweetContext) is(f IsFilter) bool {
+	switch f {
+	case IsRetweet:
+		return tc.isReferencing("retweeted")
+	case IsReply:
+		return tc.isReferencing("replied_to")
+	case IsQuote:
+		return tc.isReferencing("quoted")
+	case IsVerified:
+		u, ok := tc.users[gotwi.StringValue(tc.tweet.AuthorID)]
+		return ok && u.Verified != nil && *u.Verified
+	default:
+		return false
+	}
+}
+
+func (tc *tweetContext) has(f HasFilter) bool {
+	e := tc.entities()
+	switch f {
+	case HasHashtags:
+		return len(e.HashTags) > 0
+	case HasCashtags:
+		return len(e.CashTags) > 0
+	case HasMentions:
+		return len(e.Mentions) > 0
+	case HasLinks:
+		return len(e.URLs) > 0
+	case HasMedia:
+		return tc.tweet.Attachments != nil && len(tc.tweet.Attachments.MediaKeys) > 0
+	case HasImages:
+		return tc.hasMediaType("photo")
+	case HasVideos:
+		return tc.hasMediaType("video")
+	case HasGeo:
+		return tc.tweet.Geo != nil && (tc.tweet.Geo.PlaceID != nil || len(tc.tweet.Geo.Coordinates.Coordinates) == 2)
+	default:
+		return false
+	}
+}
+
+func (tc *tweetContext) hasMediaType(typ string) bool {
+	if tc.tweet.Attachments == nil {
+		return false
+	}
+	for _, k := range tc.tweet.Attachments.MediaKeys {
+		if m, ok := tc.media[k]; ok && gotwi.StringValue(m.Type) == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (tc *tweetContext) hasURL(url string) bool {
+	url = strings.ToLower(url)
+	for _, u := range tc.entities().URLs {
+		for _, s := range []*string{u.URL, u.ExpandedURL, u.UnwoundURL, u.DisplayURL} {
+			if s != nil && strings.Contains(strings.ToLower(*s), url) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (tc *tweetContext) place() (*resources.Place, bool) {
+	if tc.tweet.Geo == nil || tc.tweet.Geo.PlaceID == nil {
+		return nil, false
+	}
+	p, ok := tc.places[*tc.tweet.Geo.PlaceID]
+	return p, ok
+}
+
+// within reports whether the exact location of the Tweet, or else every corner of
+// the bounding box of its place, is inside the region.
+func (tc *tweetContext) within(inside func(lon, lat float64) bool) bool {
+	if tc.tweet.Geo == nil {
+		return false
+	}
+
+	if c := tc.tweet.Geo.Coordinates.Coordinates; len(c) == 2 && c[0] != nil && c[1] != nil {
+		return inside(float64(*c[0]), float64(*c[1]))
+	}
+
+	p, ok := tc.place()
+	if !ok || p.Geo == nil {
+		return false
+	}
+	for _, f := range p.Geo.BBox {
+		if f == nil {
+			return false
+		}
+	}
+	west, south, east, north := *p.Geo.BBox[0], *p.Geo.BBox[1], *p.Geo.BBox[2], *p.Geo.BBox[3]
+	return inside(west, south) && inside(west, north) && inside(east, south) && inside(east, north)
+}
+
+func pointRadiusContains(v string) func(lon, lat float64) bool {
+	fs := strings.Fields(v)
+	f, _ := parseFloats(fs[:2])
+	unit := DistanceUnit(fs[2][len(fs[2])-2:])
+	radius, _ := strconv.ParseFloat(strings.TrimSuffix(fs[2], string(unit)), 64)
+	if unit == Kilometers {
+		radius /= 1.609344
+	}
+
+	return func(lon, lat float64) bool {
+		return distanceMiles(f[0], f[1], lon, lat) <= radius
+	}
+}
+
+// tokenize splits text into lower case words at spaces, punctuation and symbols.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) && r != '_'
+	})
+}
+
+// containsWords reports whether words contains sub as consecutive words.
+func containsWords(words, sub []string) bool {
+	if len(sub) == 0 {
+		return false
+	}
+
+	for i := 0; i+len(sub) <= len(words); i++ {
+		ok := true
+		for j := range sub {
+			if words[i+j] != sub[j] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMention(mentions []resources.TweetEntityTag, username string) bool {
+	for _, m := range mentions {
+		if m.Username != nil && strings.EqualFold(*m.Username, username) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTag(tags []resources.TweetEntityTag, tag string) bool {
+	for _, t := range tags {
+		if t.Tag != nil && strings.EqualFold(*t.Tag, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package query_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/query"
+	"github.com/xxiiaaon/gotwi/resources"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testStreamMessage = `{
+	"data": {
+		"id": "100",
+		"text": "RT @TwitterDev: Cats can't stop #caturday. Go-lang rocks! https://t.co/abc",
+		"author_id": "1",
+		"lang": "en",
+		"conversation_id": "100",
+		"in_reply_to_user_id": "3",
+		"referenced_tweets": [{"type": "retweeted", "id": "99"}],
+		"attachments": {"media_keys": ["3_1"]},
+		"geo": {"place_id": "p1"},
+		"entities": {
+			"hashtags": [{"start": 32, "end": 41, "tag": "caturday"}],
+			"mentions": [{"start": 3, "end": 14, "username": "TwitterDev", "id": "2"}],
+			"urls": [{"url": "https://t.co/abc", "expanded_url": "https://example.com/cats", "display_url": "example.com/cats"}]
+		}
+	},
+	"includes": {
+		"users": [
+			{"id": "1", "username": "CatLover", "verified": true},
+			{"id": "2", "username": "TwitterDev"},
+			{"id": "3", "username": "TwitterAPI"}
+		],
+		"tweets": [{"id": "99", "text": "Cats can't stop #caturday", "author_id": "2"}],
+		"media": [{"media_key": "3_1", "type": "photo"}],
+		"places": [{
+			"id": "p1",
+			"full_name": "Boulder, CO",
+			"name": "Boulder",
+			"country_code": "US",
+			"geo": {"type": "Feature", "bbox": [-105.301758, 39.964069, -105.178505, 40.09455]}
+		}]
+	}
+}`
+
+func testTweet(t *testing.T) (*resources.Tweet, *query.Includes) {
+	out := types.SearchStreamOutput{}
+	if err := json.Unmarshal([]byte(testStreamMessage), &out); err != nil {
+		t.Fatal(err)
+	}
+	inc := query.Includes(out.Includes)
+	return &out.Data, &inc
+}
+
+func Test_Matcher_Match(t *testing.T) {
+	cases := []struct {
+		name   string
+		query  string
+		expect bool
+	}{
+		{"keyword", "cats", true},
+		{"keyword is case insensitive", "CATS", true},
+		{"keyword is tokenized", "stop", true},
+		{"keyword matches words only", "cat", false},
+		{"keyword with punctuation", "go-lang", true},
+		{"phrase", `"can't stop"`, true},
+		{"phrase in order", `"stop can't"`, false},
+		{"hashtag", "#caturday", true},
+		{"hashtag is not a keyword match", "#cats", false},
+		{"mention", "@twitterdev", true},
+		{"mention other user", "@CatLover", false},
+		{"from user name", "from:catlover", true},
+		{"from user ID", "from:1", true},
+		{"from other user", "from:TwitterDev", false},
+		{"to", "to:TwitterAPI", true},
+		{"retweets_of", "retweets_of:TwitterDev", true},
+		{"is:retweet", "cats is:retweet", true},
+		{"is:reply", "cats is:reply", false},
+		{"is:verified", "cats is:verified", true},
+		{"is:nullcast never matches", "cats -is:nullcast", true},
+		{"has:links", "cats has:links", true},
+		{"has:images", "cats has:images", true},
+		{"has:videos", "cats has:videos", false},
+		{"has:cashtags", "cats has:cashtags", false},
+		{"has:geo", "cats has:geo", true},
+		{"lang", "cats lang:en", true},
+		{"other lang", "cats lang:ja", false},
+		{"url", `url:"example.com/cats"`, true},
+		{"conversation_id", "conversation_id:100", true},
+		{"place", `place:boulder`, true},
+		{"place full name", `place:"boulder, co"`, true},
+		{"place_country", "place_country:us", true},
+		{"bounding_box containing the place", "bounding_box:[-105.4 39.9 -105.1 40.2]", true},
+		{"bounding_box cutting the place", "bounding_box:[-105.25 39.9 -105.1 40.2]", false},
+		{"point_radius containing the place", "point_radius:[-105.24 40.03 10mi]", true},
+		{"point_radius outside", "point_radius:[-104.99 39.74 20km]", false},
+		{"and", "cats lang:en -is:reply", true},
+		{"negation", "cats -#caturday", false},
+		{"or", "dogs OR #caturday", true},
+		{"group", "(dogs OR birds) has:images", false},
+	}
+
+	tweet, inc := testTweet(t)
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			m, err := query.Compile(c.query)
+			if !asst.NoError(err) {
+				return
+			}
+			asst.Equal(c.expect, m.Match(tweet, inc))
+		})
+	}
+}
+
+func Test_Matcher_MissingIncludes(t *testing.T) {
+	asst := assert.New(t)
+
+	tweet, _ := testTweet(t)
+	for q, expect := range map[string]bool{
+		"from:1":           true,
+		"from:CatLover":    false,
+		"cats has:images":  false,
+		"cats has:media":   true,
+		"place_country:US": false,
+	} {
+		m, err := query.Compile(q)
+		asst.NoError(err)
+		asst.Equal(expect, m.Match(tweet, nil), q)
+	}
+}
+
+func Test_NewMatcher_Unsupported(t *testing.T) {
+	cases := []struct {
+		name        string
+		query       string
+		unsupported bool
+	}{
+		{"sample", "cats sample:10", true},
+		{"unknown operator", "cats context:10.123", true},
+		{"unknown is filter", "cats is:promoted", true},
+		{"invalid bounding box", "bounding_box:[1 2 3]", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			m, err := query.Compile(c.query)
+			asst.Error(err)
+			asst.Nil(m)
+			asst.Equal(c.unsupported, errors.Is(err, query.ErrUnsupportedOperator))
+		})
+	}
+}
+
+func Test_RuleMatcher(t *testing.T) {
+	asst := assert.New(t)
+
+	rules := []resources.FilterdStreamRule{
+		{ID: gotwi.String("1"), Value: gotwi.String("#caturday has:images"), Tag: gotwi.String("cats")},
+		{ID: gotwi.String("2"), Value: gotwi.String("dogs"), Tag: gotwi.String("dogs")},
+		{ID: gotwi.String("3"), Value: gotwi.String("from:CatLover -is:retweet")},
+		{ID: gotwi.String("4"), Value: gotwi.String("retweets_of:TwitterDev")},
+	}
+	rm, err := query.NewRuleMatcher(rules)
+	asst.NoError(err)
+
+	tweet, inc := testTweet(t)
+	asst.Equal([]resources.FilterdStreamRule{rules[0], rules[3]}, rm.Match(tweet, inc))
+	asst.Empty(rm.Match(nil, inc))
+
+	_, err = query.NewRuleMatcher(append(rules, resources.FilterdStreamRule{ID: gotwi.String("5"), Value: gotwi.String("cats sample:1")}))
+	asst.ErrorIs(err, query.ErrUnsupportedOperator)
+	asst.Contains(err.Error(), "rule 5")
+}
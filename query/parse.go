@@ -0,0 +1,250 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse parses a search query or a filtered stream rule. Juxtaposed expressions are
+// combined with AND, which binds tighter than OR. Operators that this package has no
+// constructor for are kept as a Term with their name, so that Validate and Match report them.
+func Parse(s string) (Expr, error) {
+	p := &parser{src: s}
+	if p.peek() == tokenEOF {
+		return nil, fmt.Errorf("Query is required.")
+	}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != tokenEOF {
+		return nil, fmt.Errorf("Unexpected %q at %d.", p.src[p.pos:p.pos+1], p.pos)
+	}
+
+	return e, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenOpen
+	tokenClose
+	tokenOr
+	tokenTerm
+)
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// peek returns the kind of the next token without consuming it.
+func (p *parser) peek() tokenKind {
+	p.skipSpaces()
+	if p.pos >= len(p.src) {
+		return tokenEOF
+	}
+
+	switch p.src[p.pos] {
+	case '(':
+		return tokenOpen
+	case ')':
+		return tokenClose
+	}
+
+	rest := p.src[p.pos:]
+	if strings.HasPrefix(rest, "OR") && (len(rest) == 2 || isSpace(rest[2]) || rest[2] == '(') {
+		return tokenOr
+	}
+
+	return tokenTerm
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	exprs := []Expr{}
+	for {
+		e, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+
+		if p.peek() != tokenOr {
+			break
+		}
+		p.pos += len("OR")
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return OrExpr{Exprs: exprs}, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	exprs := []Expr{}
+	for {
+		k := p.peek()
+		if k == tokenEOF || k == tokenClose || k == tokenOr {
+			break
+		}
+
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+
+	switch len(exprs) {
+	case 0:
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("Expression is missing at the end.")
+		}
+		return nil, fmt.Errorf("Expression is missing before %q at %d.", p.src[p.pos:p.pos+1], p.pos)
+	case 1:
+		return exprs[0], nil
+	}
+	return AndExpr{Exprs: exprs}, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.src[p.pos] == '-' && p.pos+1 < len(p.src) && !isSpace(p.src[p.pos+1]) {
+		p.pos++
+		e, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Expr: e}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	if p.pos < len(p.src) && p.src[p.pos] == '(' {
+		start := p.pos
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != tokenClose {
+			return nil, fmt.Errorf("Parenthesis at %d is not closed.", start)
+		}
+		p.pos++
+		return e, nil
+	}
+
+	return p.parseTerm()
+}
+
+func (p *parser) parseTerm() (Expr, error) {
+	start := p.pos
+	if p.pos >= len(p.src) || p.src[p.pos] == ')' {
+		return nil, fmt.Errorf("Expression is missing at %d.", start)
+	}
+
+	if p.src[p.pos] == '"' {
+		v, err := p.readQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return Phrase(v), nil
+	}
+
+	switch c := p.src[p.pos]; c {
+	case '#', '@', '$':
+		if p.pos+1 < len(p.src) && !isDelimiter(p.src[p.pos+1]) {
+			p.pos++
+			return Term{Operator: Operator(string(c)), Value: p.readWord()}, nil
+		}
+	}
+
+	if name, ok := p.operatorName(); ok {
+		p.pos += len(name) + 1
+		v, err := p.readValue(Operator(name))
+		if err != nil {
+			return nil, err
+		}
+		return Term{Operator: Operator(name), Value: v}, nil
+	}
+
+	return Keyword(p.readWord()), nil
+}
+
+// operatorName returns the name of the operator at the position, e.g. "from" for "from:TwitterDev".
+func (p *parser) operatorName() (string, bool) {
+	i := p.pos
+	for i < len(p.src) && (p.src[i] == '_' || (p.src[i] >= 'a' && p.src[i] <= 'z')) {
+		i++
+	}
+	if i == p.pos || i+1 >= len(p.src) || p.src[i] != ':' || isDelimiter(p.src[i+1]) && p.src[i+1] != '"' {
+		return "", false
+	}
+	return p.src[p.pos:i], true
+}
+
+func (p *parser) readValue(op Operator) (string, error) {
+	switch {
+	case p.src[p.pos] == '"':
+		return p.readQuoted()
+	case p.src[p.pos] == '[' && (op == OperatorBoundingBox || op == OperatorPointRadius):
+		end := strings.IndexByte(p.src[p.pos:], ']')
+		if end < 0 {
+			return "", fmt.Errorf("Bracket at %d is not closed.", p.pos)
+		}
+		v := strings.Join(strings.Fields(p.src[p.pos+1:p.pos+end]), " ")
+		p.pos += end + 1
+		return v, nil
+	}
+	return p.readWord(), nil
+}
+
+// readQuoted reads a quoted string, in which \" is a quote.
+func (p *parser) readQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+
+	b := strings.Builder{}
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '"':
+			b.WriteByte('"')
+			p.pos += 2
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", fmt.Errorf("Quote at %d is not closed.", start)
+}
+
+func (p *parser) readWord() string {
+	start := p.pos
+	for p.pos < len(p.src) && !isDelimiter(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func isDelimiter(c byte) bool {
+	return isSpace(c) || c == '(' || c == ')' || c == '"'
+}
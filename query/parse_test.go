@@ -0,0 +1,159 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/xxiiaaon/gotwi/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parse(t *testing.T) {
+	cases := []struct {
+		name    string
+		query   string
+		expect  query.Expr
+		wantErr bool
+	}{
+		{
+			name:   "keyword",
+			query:  "cat",
+			expect: query.Keyword("cat"),
+		},
+		{
+			name:   "and",
+			query:  "  cat   has:images ",
+			expect: query.And(query.Keyword("cat"), query.Has(query.HasImages)),
+		},
+		{
+			name:   "and binds tighter than or",
+			query:  "cat has:images OR dog",
+			expect: query.Or(query.And(query.Keyword("cat"), query.Has(query.HasImages)), query.Keyword("dog")),
+		},
+		{
+			name:  "grouping and negation",
+			query: `(#golang OR "go programming") lang:en -is:retweet -(grumpy OR @angry)`,
+			expect: query.And(
+				query.Or(query.Hashtag("golang"), query.Phrase("go programming")),
+				query.Lang("en"),
+				query.Not(query.Is(query.IsRetweet)),
+				query.Not(query.Or(query.Keyword("grumpy"), query.Mention("angry"))),
+			),
+		},
+		{
+			name:  "operators",
+			query: `from:TwitterDev to:TwitterAPI retweets_of:golang $TWTR url:"https://example.com" place:"new york city" place_country:US conversation_id:1 sample:10`,
+			expect: query.And(
+				query.From("TwitterDev"),
+				query.To("TwitterAPI"),
+				query.RetweetsOf("golang"),
+				query.Cashtag("TWTR"),
+				query.URL("https://example.com"),
+				query.Place("new york city"),
+				query.PlaceCountry("US"),
+				query.ConversationID("1"),
+				query.Sample(10),
+			),
+		},
+		{
+			name:  "geo",
+			query: "bounding_box:[-105.301758 39.964069 -105.178505 40.09455] OR point_radius:[ 2.355128  48.861118 16km ]",
+			expect: query.Or(
+				query.BoundingBox(-105.301758, 39.964069, -105.178505, 40.09455),
+				query.PointRadius(2.355128, 48.861118, 16, query.Kilometers),
+			),
+		},
+		{
+			name:   "escaped quote",
+			query:  `"say \"hi\""`,
+			expect: query.Phrase(`say "hi"`),
+		},
+		{
+			name:   "unknown operator is kept",
+			query:  "cat context:123.456",
+			expect: query.And(query.Keyword("cat"), query.Term{Operator: "context", Value: "123.456"}),
+		},
+		{
+			name:   "symbols alone are keywords",
+			query:  "a - # b:",
+			expect: query.And(query.Keyword("a"), query.Keyword("-"), query.Keyword("#"), query.Keyword("b:")),
+		},
+		{
+			name:   "OR within a word",
+			query:  "ORANGE OR ORE",
+			expect: query.Or(query.Keyword("ORANGE"), query.Keyword("ORE")),
+		},
+		{
+			name:    "error: empty",
+			query:   "  ",
+			wantErr: true,
+		},
+		{
+			name:    "error: unclosed parenthesis",
+			query:   "(cat OR dog",
+			wantErr: true,
+		},
+		{
+			name:    "error: unexpected parenthesis",
+			query:   "cat)",
+			wantErr: true,
+		},
+		{
+			name:    "error: empty group",
+			query:   "cat ()",
+			wantErr: true,
+		},
+		{
+			name:    "error: dangling or",
+			query:   "cat OR",
+			wantErr: true,
+		},
+		{
+			name:    "error: unclosed quote",
+			query:   `"cat`,
+			wantErr: true,
+		},
+		{
+			name:    "error: unclosed bracket",
+			query:   "point_radius:[1 2 3mi",
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			e, err := query.Parse(c.query)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(e)
+				return
+			}
+
+			asst.NoError(err)
+			asst.Equal(c.expect, e)
+		})
+	}
+}
+
+func Test_Parse_RoundTrip(t *testing.T) {
+	exprs := []query.Expr{
+		query.And(
+			query.Or(query.Hashtag("golang"), query.Phrase(`say "go"`)),
+			query.Not(query.Or(query.From("a"), query.And(query.To("b"), query.Has(query.HasLinks)))),
+			query.Place("new york city"),
+			query.PointRadius(2.35, 48.86, 10, query.Miles),
+		),
+		query.Or(query.And(query.Keyword("cat"), query.Lang("en")), query.URL("https://example.com/a b")),
+	}
+
+	for _, e := range exprs {
+		t.Run(e.String(), func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			got, err := query.Parse(e.String())
+			asst.NoError(err)
+			asst.Equal(e, got)
+		})
+	}
+}
@@ -0,0 +1,344 @@
+// Package query builds the query syntax of search Tweets and the rule syntax of the filtered stream.
+//
+//	q, err := query.Build(
+//		query.And(
+//			query.Or(query.Hashtag("golang"), query.Phrase("go programming")),
+//			query.Lang("en"),
+//			query.Not(query.Is(query.IsRetweet)),
+//		),
+//		query.RecentSearch, query.AccessEssential,
+//	)
+//	// (#golang OR "go programming") lang:en -is:retweet
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Operator string
+
+const (
+	OperatorKeyword        Operator = "keyword"
+	OperatorPhrase         Operator = "phrase"
+	OperatorFrom           Operator = "from"
+	OperatorTo             Operator = "to"
+	OperatorMention        Operator = "@"
+	OperatorHashtag        Operator = "#"
+	OperatorCashtag        Operator = "$"
+	OperatorURL            Operator = "url"
+	OperatorRetweetsOf     Operator = "retweets_of"
+	OperatorConversationID Operator = "conversation_id"
+	OperatorIs             Operator = "is"
+	OperatorHas            Operator = "has"
+	OperatorLang           Operator = "lang"
+	OperatorPlace          Operator = "place"
+	OperatorPlaceCountry   Operator = "place_country"
+	OperatorBoundingBox    Operator = "bounding_box"
+	OperatorPointRadius    Operator = "point_radius"
+	OperatorSample         Operator = "sample"
+)
+
+func (o Operator) String() string {
+	return string(o)
+}
+
+type IsFilter string
+
+const (
+	IsRetweet  IsFilter = "retweet"
+	IsReply    IsFilter = "reply"
+	IsQuote    IsFilter = "quote"
+	IsVerified IsFilter = "verified"
+	IsNullcast IsFilter = "nullcast"
+)
+
+type HasFilter string
+
+const (
+	HasHashtags HasFilter = "hashtags"
+	HasCashtags HasFilter = "cashtags"
+	HasLinks    HasFilter = "links"
+	HasMentions HasFilter = "mentions"
+	HasMedia    HasFilter = "media"
+	HasImages   HasFilter = "images"
+	HasVideos   HasFilter = "videos"
+	HasGeo      HasFilter = "geo"
+)
+
+type DistanceUnit string
+
+const (
+	Miles      DistanceUnit = "mi"
+	Kilometers DistanceUnit = "km"
+)
+
+// Expr is a node of a query: a Term, AndExpr, OrExpr or NotExpr.
+type Expr interface {
+	// String returns the expression in the query syntax.
+	String() string
+
+	write(b *strings.Builder, parent precedence)
+}
+
+// precedence decides whether a group has to be enclosed in parentheses.
+type precedence int
+
+const (
+	precedenceTop precedence = iota
+	precedenceOr
+	precedenceAnd
+	precedenceNot
+)
+
+// Term is a keyword or an operator with its value.
+type Term struct {
+	Operator Operator
+
+	// Value is the value of the operator without the operator name and quotes,
+	// e.g. "TwitterDev" for from:TwitterDev. The value of bounding_box and point_radius
+	// is the content of the brackets, e.g. "-105.3 39.9 10mi".
+	Value string
+}
+
+// AndExpr matches when all of Exprs match.
+type AndExpr struct {
+	Exprs []Expr
+}
+
+// OrExpr matches when any of Exprs matches.
+type OrExpr struct {
+	Exprs []Expr
+}
+
+// NotExpr matches when Expr does not match.
+type NotExpr struct {
+	Expr Expr
+}
+
+// Keyword matches Tweets containing the word. It is quoted when it would otherwise
+// be read as an operator or as several words.
+func Keyword(word string) Term {
+	return Term{Operator: OperatorKeyword, Value: word}
+}
+
+// Phrase matches Tweets containing the exact phrase.
+func Phrase(phrase string) Term {
+	return Term{Operator: OperatorPhrase, Value: phrase}
+}
+
+// From matches Tweets sent by the user. A leading "@" is removed.
+func From(user string) Term {
+	return Term{Operator: OperatorFrom, Value: strings.TrimPrefix(user, "@")}
+}
+
+// To matches Tweets in reply to the user. A leading "@" is removed.
+func To(user string) Term {
+	return Term{Operator: OperatorTo, Value: strings.TrimPrefix(user, "@")}
+}
+
+// Mention matches Tweets mentioning the user. A leading "@" is removed.
+func Mention(user string) Term {
+	return Term{Operator: OperatorMention, Value: strings.TrimPrefix(user, "@")}
+}
+
+// Hashtag matches Tweets with the hashtag. A leading "#" is removed.
+func Hashtag(tag string) Term {
+	return Term{Operator: OperatorHashtag, Value: strings.TrimPrefix(tag, "#")}
+}
+
+// Cashtag matches Tweets with the cashtag. A leading "$" is removed.
+func Cashtag(tag string) Term {
+	return Term{Operator: OperatorCashtag, Value: strings.TrimPrefix(tag, "$")}
+}
+
+// URL matches Tweets containing a URL that matches url.
+func URL(url string) Term {
+	return Term{Operator: OperatorURL, Value: url}
+}
+
+// RetweetsOf matches Retweets of Tweets of the user. A leading "@" is removed.
+func RetweetsOf(user string) Term {
+	return Term{Operator: OperatorRetweetsOf, Value: strings.TrimPrefix(user, "@")}
+}
+
+func ConversationID(id string) Term {
+	return Term{Operator: OperatorConversationID, Value: id}
+}
+
+func Is(f IsFilter) Term {
+	return Term{Operator: OperatorIs, Value: string(f)}
+}
+
+func Has(f HasFilter) Term {
+	return Term{Operator: OperatorHas, Value: string(f)}
+}
+
+// Lang matches Tweets classified as the BCP 47 language, e.g. "en".
+func Lang(code string) Term {
+	return Term{Operator: OperatorLang, Value: code}
+}
+
+// Place matches Tweets tagged with the place, by name or ID.
+func Place(place string) Term {
+	return Term{Operator: OperatorPlace, Value: place}
+}
+
+// PlaceCountry matches Tweets tagged with a place in the country, by ISO alpha-2 code.
+func PlaceCountry(code string) Term {
+	return Term{Operator: OperatorPlaceCountry, Value: code}
+}
+
+// BoundingBox matches Tweets located in the box, given in degrees. Each side must be shorter than 25 miles.
+func BoundingBox(west, south, east, north float64) Term {
+	return Term{
+		Operator: OperatorBoundingBox,
+		Value:    strings.Join([]string{formatFloat(west), formatFloat(south), formatFloat(east), formatFloat(north)}, " "),
+	}
+}
+
+// PointRadius matches Tweets located in the circle. The radius must be at most 25 miles.
+func PointRadius(longitude, latitude, radius float64, unit DistanceUnit) Term {
+	return Term{
+		Operator: OperatorPointRadius,
+		Value:    fmt.Sprintf("%s %s %s%s", formatFloat(longitude), formatFloat(latitude), formatFloat(radius), unit),
+	}
+}
+
+// Sample matches a random percent of the Tweets matching the rest of the rule.
+// It is only available on the filtered stream.
+func Sample(percent int) Term {
+	return Term{Operator: OperatorSample, Value: strconv.Itoa(percent)}
+}
+
+// And returns an expression that matches when all of exprs match.
+func And(exprs ...Expr) Expr {
+	return AndExpr{Exprs: exprs}
+}
+
+// Or returns an expression that matches when any of exprs matches.
+func Or(exprs ...Expr) Expr {
+	return OrExpr{Exprs: exprs}
+}
+
+// Not returns an expression that matches when expr does not match.
+// Validate accepts only a negated term, not a negated group.
+func Not(expr Expr) Expr {
+	return NotExpr{Expr: expr}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func (t Term) String() string {
+	b := strings.Builder{}
+	t.write(&b, precedenceTop)
+	return b.String()
+}
+
+func (t Term) write(b *strings.Builder, _ precedence) {
+	switch t.Operator {
+	case OperatorKeyword:
+		if keywordNeedsQuotes(t.Value) {
+			writeQuoted(b, t.Value)
+		} else {
+			b.WriteString(t.Value)
+		}
+	case OperatorPhrase:
+		writeQuoted(b, t.Value)
+	case OperatorMention, OperatorHashtag, OperatorCashtag:
+		b.WriteString(string(t.Operator))
+		b.WriteString(t.Value)
+	case OperatorBoundingBox, OperatorPointRadius:
+		b.WriteString(string(t.Operator))
+		b.WriteString(":[")
+		b.WriteString(t.Value)
+		b.WriteString("]")
+	case OperatorURL:
+		b.WriteString("url:")
+		writeQuoted(b, t.Value)
+	default:
+		b.WriteString(string(t.Operator))
+		b.WriteString(":")
+		if keywordNeedsQuotes(t.Value) {
+			writeQuoted(b, t.Value)
+		} else {
+			b.WriteString(t.Value)
+		}
+	}
+}
+
+// keywordNeedsQuotes reports whether a word would not be read back as the same single keyword.
+func keywordNeedsQuotes(w string) bool {
+	if w == "" || w == "OR" || w == "AND" {
+		return true
+	}
+	if strings.ContainsAny(w[:1], "-#@$") {
+		return true
+	}
+	return strings.ContainsAny(w, " \t\r\n\"():")
+}
+
+func writeQuoted(b *strings.Builder, s string) {
+	b.WriteString(`"`)
+	b.WriteString(strings.ReplaceAll(s, `"`, `\"`))
+	b.WriteString(`"`)
+}
+
+func (e AndExpr) String() string {
+	b := strings.Builder{}
+	e.write(&b, precedenceTop)
+	return b.String()
+}
+
+func (e AndExpr) write(b *strings.Builder, parent precedence) {
+	writeGroup(b, e.Exprs, " ", precedenceAnd, parent)
+}
+
+func (e OrExpr) String() string {
+	b := strings.Builder{}
+	e.write(&b, precedenceTop)
+	return b.String()
+}
+
+func (e OrExpr) write(b *strings.Builder, parent precedence) {
+	writeGroup(b, e.Exprs, " OR ", precedenceOr, parent)
+}
+
+// writeGroup encloses the group in parentheses unless it is the whole query or has only one
+// expression. AND is enclosed within OR too, so that the query does not rely on precedence.
+func writeGroup(b *strings.Builder, exprs []Expr, sep string, self, parent precedence) {
+	if len(exprs) == 1 {
+		exprs[0].write(b, parent)
+		return
+	}
+
+	paren := parent != precedenceTop && parent != self
+	if paren {
+		b.WriteString("(")
+	}
+	for i, e := range exprs {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		e.write(b, self)
+	}
+	if paren {
+		b.WriteString(")")
+	}
+}
+
+func (e NotExpr) String() string {
+	b := strings.Builder{}
+	e.write(&b, precedenceTop)
+	return b.String()
+}
+
+func (e NotExpr) write(b *strings.Builder, _ precedence) {
+	b.WriteString("-")
+	if e.Expr != nil {
+		e.Expr.write(b, precedenceNot)
+	}
+}
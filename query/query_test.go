@@ -0,0 +1,110 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/xxiiaaon/gotwi/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Expr_String(t *testing.T) {
+	cases := []struct {
+		name   string
+		expr   query.Expr
+		expect string
+	}{
+		{
+			name:   "keyword",
+			expr:   query.Keyword("cat"),
+			expect: "cat",
+		},
+		{
+			name:   "keyword with space is quoted",
+			expr:   query.Keyword("cat food"),
+			expect: `"cat food"`,
+		},
+		{
+			name:   "keyword like an operator is quoted",
+			expr:   query.And(query.Keyword("from:me"), query.Keyword("OR"), query.Keyword("-1")),
+			expect: `"from:me" "OR" "-1"`,
+		},
+		{
+			name:   "phrase with quotes",
+			expr:   query.Phrase(`say "hi"`),
+			expect: `"say \"hi\""`,
+		},
+		{
+			name: "user operators",
+			expr: query.And(
+				query.From("@TwitterDev"),
+				query.To("TwitterAPI"),
+				query.Mention("@gotwi"),
+				query.RetweetsOf("golang"),
+			),
+			expect: "from:TwitterDev to:TwitterAPI @gotwi retweets_of:golang",
+		},
+		{
+			name:   "tags",
+			expr:   query.And(query.Hashtag("#golang"), query.Cashtag("$TWTR")),
+			expect: "#golang $TWTR",
+		},
+		{
+			name:   "filters",
+			expr:   query.And(query.Is(query.IsReply), query.Has(query.HasImages), query.Lang("ja"), query.Sample(10)),
+			expect: "is:reply has:images lang:ja sample:10",
+		},
+		{
+			name:   "url and places",
+			expr:   query.And(query.URL("https://example.com"), query.Place("new york city"), query.PlaceCountry("US")),
+			expect: `url:"https://example.com" place:"new york city" place_country:US`,
+		},
+		{
+			name: "geo",
+			expr: query.Or(
+				query.BoundingBox(-105.301758, 39.964069, -105.178505, 40.09455),
+				query.PointRadius(2.355128, 48.861118, 16, query.Kilometers),
+			),
+			expect: "bounding_box:[-105.301758 39.964069 -105.178505 40.09455] OR point_radius:[2.355128 48.861118 16km]",
+		},
+		{
+			name: "grouping",
+			expr: query.And(
+				query.Or(query.Hashtag("golang"), query.Phrase("go programming")),
+				query.Lang("en"),
+				query.Not(query.Is(query.IsRetweet)),
+			),
+			expect: `(#golang OR "go programming") lang:en -is:retweet`,
+		},
+		{
+			name: "and within or is grouped",
+			expr: query.Or(
+				query.And(query.Keyword("cat"), query.Has(query.HasImages)),
+				query.And(query.Keyword("dog"), query.Has(query.HasVideos)),
+			),
+			expect: "(cat has:images) OR (dog has:videos)",
+		},
+		{
+			name:   "negated group",
+			expr:   query.And(query.Keyword("cat"), query.Not(query.Or(query.Keyword("grumpy"), query.Keyword("angry")))),
+			expect: "cat -(grumpy OR angry)",
+		},
+		{
+			name:   "nested and is flattened",
+			expr:   query.And(query.Keyword("a"), query.And(query.Keyword("b"), query.Keyword("c"))),
+			expect: "a b c",
+		},
+		{
+			name:   "group of one",
+			expr:   query.And(query.Or(query.Keyword("a")), query.Not(query.And(query.Keyword("b")))),
+			expect: "a -b",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			asst.Equal(c.expect, c.expr.String())
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Product is the endpoint that a query is sent to.
+type Product string
+
+const (
+	RecentSearch      Product = "recent_search"
+	FullArchiveSearch Product = "full_archive_search"
+	FilteredStream    Product = "filtered_stream"
+)
+
+// AccessLevel is the access level of the Project of the app.
+type AccessLevel string
+
+const (
+	AccessEssential        AccessLevel = "essential"
+	AccessElevated         AccessLevel = "elevated"
+	AccessAcademicResearch AccessLevel = "academic_research"
+)
+
+// maxLengths are the maximum lengths of a query or a rule. A missing entry means
+// that the product is not available with the access level.
+var maxLengths = map[Product]map[AccessLevel]int{
+	RecentSearch: {
+		AccessEssential:        512,
+		AccessElevated:         512,
+		AccessAcademicResearch: 1024,
+	},
+	FullArchiveSearch: {
+		AccessAcademicResearch: 1024,
+	},
+	FilteredStream: {
+		AccessEssential:        512,
+		AccessElevated:         512,
+		AccessAcademicResearch: 1024,
+	},
+}
+
+// MaxLength returns the maximum length in characters of a query for the product with the access level.
+// ok is false if the product is not available with the access level.
+func MaxLength(p Product, a AccessLevel) (max int, ok bool) {
+	max, ok = maxLengths[p][a]
+	return max, ok
+}
+
+// advancedOperators are the operators, and the is: and has: filters, that are only
+// available with the access levels in advancedAccess.
+var advancedOperators = map[string]struct{}{
+	string(OperatorCashtag):      {},
+	string(OperatorPlace):        {},
+	string(OperatorPlaceCountry): {},
+	string(OperatorBoundingBox):  {},
+	string(OperatorPointRadius):  {},
+	"is:" + string(IsNullcast):   {},
+	"has:" + string(HasGeo):      {},
+}
+
+// advancedAccess are the access levels with which the advanced operators are available on each product.
+var advancedAccess = map[Product]map[AccessLevel]struct{}{
+	RecentSearch: {
+		AccessAcademicResearch: {},
+	},
+	FullArchiveSearch: {
+		AccessAcademicResearch: {},
+	},
+	FilteredStream: {
+		AccessAcademicResearch: {},
+	},
+}
+
+// OperatorAvailable reports whether the operator of t, or its is: or has: filter, can be used
+// on the product with the access level.
+func OperatorAvailable(t Term, p Product, a AccessLevel) bool {
+	if _, ok := advancedOperators[operatorName(t)]; !ok {
+		return true
+	}
+	_, ok := advancedAccess[p][a]
+	return ok
+}
+
+// operatorName returns the name of the operator of t in advancedOperators.
+func operatorName(t Term) string {
+	switch t.Operator {
+	case OperatorIs, OperatorHas:
+		return string(t.Operator) + ":" + t.Value
+	default:
+		return string(t.Operator)
+	}
+}
+
+// conjunctionRequired are the operators that cannot be used on their own.
+var conjunctionRequired = map[Operator]struct{}{
+	OperatorIs:     {},
+	OperatorHas:    {},
+	OperatorLang:   {},
+	OperatorSample: {},
+}
+
+// streamOnly are the operators that are only available on the filtered stream.
+var streamOnly = map[Operator]struct{}{
+	OperatorSample: {},
+}
+
+var isFilters = map[string]struct{}{
+	string(IsRetweet):  {},
+	string(IsReply):    {},
+	string(IsQuote):    {},
+	string(IsVerified): {},
+	string(IsNullcast): {},
+}
+
+var hasFilters = map[string]struct{}{
+	string(HasHashtags): {},
+	string(HasCashtags): {},
+	string(HasLinks):    {},
+	string(HasMentions): {},
+	string(HasMedia):    {},
+	string(HasImages):   {},
+	string(HasVideos):   {},
+	string(HasGeo):      {},
+}
+
+const (
+	maxGeoMiles      = 25
+	maxGeoKilometers = 40
+	earthRadiusMiles = 3958.8
+)
+
+// Build validates e for the product and the access level and returns it in the query syntax.
+func Build(e Expr, p Product, a AccessLevel) (string, error) {
+	if err := Validate(e, p, a); err != nil {
+		return "", err
+	}
+
+	return e.String(), nil
+}
+
+// Validate reports whether e is accepted by the product with the access level:
+// the operators available with the access level and their values, negation,
+// standalone operators and the length.
+func Validate(e Expr, p Product, a AccessLevel) error {
+	max, ok := MaxLength(p, a)
+	if !ok {
+		return fmt.Errorf("%s is not available with %s access.", p, a)
+	}
+
+	if e == nil {
+		return fmt.Errorf("Query is required.")
+	}
+
+	if err := validateExpr(e, p, a, false); err != nil {
+		return err
+	}
+
+	if !standalone(e) {
+		return fmt.Errorf("Query requires a keyword or an operator that can be used on its own, not negated.")
+	}
+
+	if l := utf8.RuneCountInString(e.String()); l > max {
+		return fmt.Errorf("Query is %d characters long, but %s with %s access accepts up to %d characters.", l, p, a, max)
+	}
+
+	return nil
+}
+
+func validateExpr(e Expr, p Product, a AccessLevel, negated bool) error {
+	switch e := e.(type) {
+	case Term:
+		return validateTerm(e, p, a, negated)
+	case AndExpr:
+		return validateGroup(e.Exprs, "AND", p, a, negated)
+	case OrExpr:
+		return validateGroup(e.Exprs, "OR", p, a, negated)
+	case NotExpr:
+		if negated {
+			return fmt.Errorf("Negation cannot be nested.")
+		}
+		switch e.Expr.(type) {
+		case nil:
+			return fmt.Errorf("Expression is nil.")
+		case AndExpr, OrExpr:
+			return fmt.Errorf("Negation of a group is not supported, negate each term instead.")
+		}
+		return validateExpr(e.Expr, p, a, true)
+	case nil:
+		return fmt.Errorf("Expression is nil.")
+	default:
+		return fmt.Errorf("Expression type %T is not supported.", e)
+	}
+}
+
+func validateGroup(exprs []Expr, name string, p Product, a AccessLevel, negated bool) error {
+	if len(exprs) == 0 {
+		return fmt.Errorf("%s requires at least one expression.", name)
+	}
+
+	for _, e := range exprs {
+		if err := validateExpr(e, p, a, negated); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateTerm(t Term, p Product, a AccessLevel, negated bool) error {
+	if t.Value == "" {
+		return fmt.Errorf("%s requires a value.", t.Operator)
+	}
+
+	if _, ok := streamOnly[t.Operator]; ok && p != FilteredStream {
+		return fmt.Errorf("%s: is only available on %s.", t.Operator, FilteredStream)
+	}
+
+	if !OperatorAvailable(t, p, a) {
+		return fmt.Errorf("%s is not available on %s with %s access.", operatorName(t), p, a)
+	}
+
+	switch t.Operator {
+	case OperatorKeyword, OperatorPhrase, OperatorURL, OperatorPlace:
+		return nil
+	case OperatorFrom, OperatorTo, OperatorMention, OperatorRetweetsOf:
+		if !isUserName(t.Value) {
+			return fmt.Errorf("%s: %q is not a user name or a user ID.", t.Operator, t.Value)
+		}
+	case OperatorHashtag, OperatorCashtag:
+		if strings.ContainsAny(t.Value, " \t\r\n\"():#$") {
+			return fmt.Errorf("%s%s is not a valid tag.", t.Operator, t.Value)
+		}
+	case OperatorConversationID:
+		if !isDigits(t.Value) {
+			return fmt.Errorf("conversation_id: %q is not a Tweet ID.", t.Value)
+		}
+	case OperatorIs:
+		if _, ok := isFilters[t.Value]; !ok {
+			return fmt.Errorf("is:%s is not supported.", t.Value)
+		}
+		if IsFilter(t.Value) == IsNullcast && !negated {
+			return fmt.Errorf("is:nullcast must be negated.")
+		}
+	case OperatorHas:
+		if _, ok := hasFilters[t.Value]; !ok {
+			return fmt.Errorf("has:%s is not supported.", t.Value)
+		}
+	case OperatorLang:
+		if !isLanguageTag(t.Value) {
+			return fmt.Errorf("lang: %q is not a BCP 47 language tag.", t.Value)
+		}
+	case OperatorPlaceCountry:
+		if len(t.Value) != 2 || !isLetters(t.Value) {
+			return fmt.Errorf("place_country: %q is not an ISO alpha-2 country code.", t.Value)
+		}
+	case OperatorBoundingBox:
+		return validateBoundingBox(t.Value)
+	case OperatorPointRadius:
+		return validatePointRadius(t.Value)
+	case OperatorSample:
+		if negated {
+			return fmt.Errorf("sample: cannot be negated.")
+		}
+		n, err := strconv.Atoi(t.Value)
+		if err != nil || n < 1 || n > 100 {
+			return fmt.Errorf("sample: %q is not a percent between 1 and 100.", t.Value)
+		}
+	default:
+		return fmt.Errorf("Operator %q is not supported.", t.Operator)
+	}
+
+	return nil
+}
+
+// standalone reports whether e matches on its own, i.e. it contains a keyword or an operator
+// that is not conjunction required in every alternative, outside a negation.
+func standalone(e Expr) bool {
+	switch e := e.(type) {
+	case Term:
+		_, ok := conjunctionRequired[e.Operator]
+		return !ok
+	case AndExpr:
+		for _, x := range e.Exprs {
+			if standalone(x) {
+				return true
+			}
+		}
+		return false
+	case OrExpr:
+		for _, x := range e.Exprs {
+			if !standalone(x) {
+				return false
+			}
+		}
+		return len(e.Exprs) > 0
+	default:
+		return false
+	}
+}
+
+func validateBoundingBox(v string) error {
+	f, err := parseFloats(strings.Fields(v))
+	if err != nil || len(f) != 4 {
+		return fmt.Errorf("bounding_box: %q is not \"west south east north\".", v)
+	}
+
+	west, south, east, north := f[0], f[1], f[2], f[3]
+	if !isLongitude(west) || !isLongitude(east) || !isLatitude(south) || !isLatitude(north) {
+		return fmt.Errorf("bounding_box: %q is out of range.", v)
+	}
+	if west >= east || south >= north {
+		return fmt.Errorf("bounding_box: %q must have west < east and south < north.", v)
+	}
+
+	width := math.Max(distanceMiles(west, south, east, south), distanceMiles(west, north, east, north))
+	height := distanceMiles(west, south, west, north)
+	if width > maxGeoMiles || height > maxGeoMiles {
+		return fmt.Errorf("bounding_box: %q is larger than %d miles per side.", v, maxGeoMiles)
+	}
+
+	return nil
+}
+
+func validatePointRadius(v string) error {
+	fs := strings.Fields(v)
+	if len(fs) != 3 {
+		return fmt.Errorf("point_radius: %q is not \"longitude latitude radius\".", v)
+	}
+
+	f, err := parseFloats(fs[:2])
+	if err != nil {
+		return fmt.Errorf("point_radius: %q is not \"longitude latitude radius\".", v)
+	}
+	if !isLongitude(f[0]) || !isLatitude(f[1]) {
+		return fmt.Errorf("point_radius: %q is out of range.", v)
+	}
+
+	unit := DistanceUnit(fs[2][max(len(fs[2])-2, 0):])
+	radius, err := strconv.ParseFloat(strings.TrimSuffix(fs[2], string(unit)), 64)
+	if err != nil || (unit != Miles && unit != Kilometers) || radius <= 0 {
+		return fmt.Errorf("point_radius: radius %q is not a positive distance in mi or km.", fs[2])
+	}
+	if (unit == Miles && radius > maxGeoMiles) || (unit == Kilometers && radius > maxGeoKilometers) {
+		return fmt.Errorf("point_radius: radius %q is larger than %d miles.", fs[2], maxGeoMiles)
+	}
+
+	return nil
+}
+
+func parseFloats(fs []string) ([]float64, error) {
+	res := make([]float64, 0, len(fs))
+	for _, s := range fs {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, f)
+	}
+	return res, nil
+}
+
+func isLongitude(f float64) bool {
+	return f >= -180 && f <= 180
+}
+
+func isLatitude(f float64) bool {
+	return f >= -90 && f <= 90
+}
+
+// distanceMiles returns the great-circle distance between two points in degrees.
+func distanceMiles(lon1, lat1, lon2, lat2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Sqrt(a))
+}
+
+func isUserName(s string) bool {
+	for _, r := range s {
+		if !(r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLanguageTag(s string) bool {
+	for _, sub := range strings.Split(s, "-") {
+		if sub == "" || len(sub) > 8 {
+			return false
+		}
+		for _, r := range sub {
+			if !((r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+				return false
+			}
+		}
+	}
+	return true
+}
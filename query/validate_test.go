@@ -0,0 +1,274 @@
+package query_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		expr    query.Expr
+		product query.Product
+		access  query.AccessLevel
+		wantErr string
+	}{
+		{
+			name:    "ok: recent search",
+			expr:    query.And(query.From("TwitterDev"), query.Not(query.Is(query.IsRetweet)), query.Has(query.HasLinks)),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+		},
+		{
+			name:    "ok: sample on filtered stream",
+			expr:    query.And(query.Keyword("cat"), query.Sample(10)),
+			product: query.FilteredStream,
+			access:  query.AccessElevated,
+		},
+		{
+			name:    "ok: negated nullcast",
+			expr:    query.And(query.Keyword("cat"), query.Not(query.Is(query.IsNullcast))),
+			product: query.FullArchiveSearch,
+			access:  query.AccessAcademicResearch,
+		},
+		{
+			name:    "ok: geo",
+			expr:    query.Or(query.BoundingBox(-105.301758, 39.964069, -105.178505, 40.09455), query.PointRadius(2.35, 48.86, 25, query.Miles)),
+			product: query.FilteredStream,
+			access:  query.AccessAcademicResearch,
+		},
+		{
+			name:    "ok: 1024 characters with academic research",
+			expr:    query.Keyword(strings.Repeat("a", 1024)),
+			product: query.RecentSearch,
+			access:  query.AccessAcademicResearch,
+		},
+		{
+			name:    "error: full archive search with essential",
+			expr:    query.Keyword("cat"),
+			product: query.FullArchiveSearch,
+			access:  query.AccessEssential,
+			wantErr: "not available",
+		},
+		{
+			name:    "error: too long",
+			expr:    query.Keyword(strings.Repeat("a", 513)),
+			product: query.FilteredStream,
+			access:  query.AccessElevated,
+			wantErr: "513 characters",
+		},
+		{
+			name:    "error: length counts characters",
+			expr:    query.Keyword(strings.Repeat("あ", 513)),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "513 characters",
+		},
+		{
+			name:    "error: sample on search",
+			expr:    query.And(query.Keyword("cat"), query.Sample(10)),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "only available",
+		},
+		{
+			name:    "error: sample out of range",
+			expr:    query.And(query.Keyword("cat"), query.Sample(0)),
+			product: query.FilteredStream,
+			access:  query.AccessEssential,
+			wantErr: "percent",
+		},
+		{
+			name:    "error: only conjunction required operators",
+			expr:    query.And(query.Has(query.HasImages), query.Lang("en")),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "on its own",
+		},
+		{
+			name:    "error: only negated terms",
+			expr:    query.Not(query.Keyword("cat")),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "on its own",
+		},
+		{
+			name:    "error: alternative without standalone operator",
+			expr:    query.Or(query.Keyword("cat"), query.Is(query.IsRetweet)),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "on its own",
+		},
+		{
+			name:    "error: nullcast not negated",
+			expr:    query.And(query.Keyword("cat"), query.Is(query.IsNullcast)),
+			product: query.RecentSearch,
+			access:  query.AccessAcademicResearch,
+			wantErr: "must be negated",
+		},
+		{
+			name:    "error: unknown has filter",
+			expr:    query.And(query.Keyword("cat"), query.Has("polls")),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "has:polls",
+		},
+		{
+			name:    "error: invalid user name",
+			expr:    query.From("Twitter Dev"),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "user name",
+		},
+		{
+			name:    "error: bounding box too large",
+			expr:    query.BoundingBox(-106, 39, -105, 40),
+			product: query.FilteredStream,
+			access:  query.AccessAcademicResearch,
+			wantErr: "25 miles",
+		},
+		{
+			name:    "error: point radius too large",
+			expr:    query.PointRadius(2.35, 48.86, 41, query.Kilometers),
+			product: query.FilteredStream,
+			access:  query.AccessAcademicResearch,
+			wantErr: "25 miles",
+		},
+		{
+			name:    "error: empty group",
+			expr:    query.And(query.Keyword("cat"), query.Or()),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "at least one",
+		},
+		{
+			name:    "error: empty value",
+			expr:    query.Keyword(""),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "requires a value",
+		},
+		{
+			name:    "error: nested negation",
+			expr:    query.And(query.Keyword("cat"), query.Not(query.Not(query.Keyword("dog")))),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "nested",
+		},
+		{
+			name:    "error: negated group",
+			expr:    query.And(query.Keyword("cat"), query.Not(query.Or(query.Keyword("grumpy"), query.Keyword("angry")))),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "group",
+		},
+		{
+			name:    "error: geo with essential",
+			expr:    query.And(query.Keyword("cat"), query.PointRadius(2.35, 48.86, 25, query.Miles)),
+			product: query.FilteredStream,
+			access:  query.AccessEssential,
+			wantErr: "point_radius is not available on filtered_stream with essential access",
+		},
+		{
+			name:    "error: has:geo with elevated",
+			expr:    query.And(query.Keyword("cat"), query.Has(query.HasGeo)),
+			product: query.RecentSearch,
+			access:  query.AccessElevated,
+			wantErr: "has:geo is not available",
+		},
+		{
+			name:    "error: cashtag with essential",
+			expr:    query.Cashtag("TWTR"),
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "$ is not available",
+		},
+		{
+			name:    "error: nil",
+			product: query.RecentSearch,
+			access:  query.AccessEssential,
+			wantErr: "required",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			err := query.Validate(c.expr, c.product, c.access)
+			if c.wantErr != "" {
+				if asst.Error(err) {
+					asst.Contains(err.Error(), c.wantErr)
+				}
+				return
+			}
+
+			asst.NoError(err)
+		})
+	}
+}
+
+func Test_Build(t *testing.T) {
+	asst := assert.New(t)
+
+	q, err := query.Build(query.And(query.Hashtag("golang"), query.Not(query.Is(query.IsRetweet))), query.RecentSearch, query.AccessEssential)
+	asst.NoError(err)
+	asst.Equal("#golang -is:retweet", q)
+
+	q, err = query.Build(query.Is(query.IsRetweet), query.RecentSearch, query.AccessEssential)
+	asst.Error(err)
+	asst.Empty(q)
+}
+
+func Test_MaxLength(t *testing.T) {
+	cases := []struct {
+		product query.Product
+		access  query.AccessLevel
+		expect  int
+		ok      bool
+	}{
+		{query.RecentSearch, query.AccessEssential, 512, true},
+		{query.RecentSearch, query.AccessAcademicResearch, 1024, true},
+		{query.FilteredStream, query.AccessElevated, 512, true},
+		{query.FilteredStream, query.AccessAcademicResearch, 1024, true},
+		{query.FullArchiveSearch, query.AccessAcademicResearch, 1024, true},
+		{query.FullArchiveSearch, query.AccessElevated, 0, false},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.product)+"/"+string(c.access), func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			max, ok := query.MaxLength(c.product, c.access)
+			asst.Equal(c.expect, max)
+			asst.Equal(c.ok, ok)
+		})
+	}
+}
+
+func Test_OperatorAvailable(t *testing.T) {
+	cases := []struct {
+		name    string
+		term    query.Term
+		product query.Product
+		access  query.AccessLevel
+		expect  bool
+	}{
+		{"core", query.Keyword("cat"), query.RecentSearch, query.AccessEssential, true},
+		{"core filter", query.Is(query.IsRetweet), query.FilteredStream, query.AccessElevated, true},
+		{"advanced", query.Place("paris"), query.FilteredStream, query.AccessElevated, false},
+		{"advanced filter", query.Is(query.IsNullcast), query.RecentSearch, query.AccessEssential, false},
+		{"advanced with academic research", query.Has(query.HasGeo), query.FullArchiveSearch, query.AccessAcademicResearch, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			asst.Equal(c.expect, query.OperatorAvailable(c.term, c.product, c.access))
+		})
+	}
+}
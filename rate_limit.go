@@ -0,0 +1,112 @@
+package gotwi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/xxiiaaon/gotwi/internal/util"
+)
+
+// RateLimitInformation is the rate limit status reported by the x-rate-limit-* response headers.
+type RateLimitInformation = util.RateLimitInformation
+
+type endpointContextKey struct{}
+
+// withEndpoint stores the endpoint template (e.g. "/2/users/:id/tweets") of the request in ctx.
+func withEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointContextKey{}, endpointTemplate(endpoint))
+}
+
+// endpointTemplate returns the path part of the endpoint, e.g. "/2/users/:id/tweets".
+func endpointTemplate(endpoint string) string {
+	if strings.HasPrefix(endpoint, "/") {
+		return endpointBase(endpoint)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Path == "" {
+		return endpointBase(endpoint)
+	}
+
+	return u.Path
+}
+
+// rateLimitKey returns the key such as "GET /2/users/:id/tweets" for the request.
+// Requests that were not prepared by CallAPI or CallStreamAPI are keyed by their URL path.
+func rateLimitKey(req *http.Request) string {
+	if tmpl, ok := req.Context().Value(endpointContextKey{}).(string); ok && tmpl != "" {
+		return strings.ToUpper(req.Method) + " " + tmpl
+	}
+
+	return strings.ToUpper(req.Method) + " " + req.URL.Path
+}
+
+// rateLimitTracker keeps the latest rate limit information per endpoint.
+// The zero value is ready to use.
+type rateLimitTracker struct {
+	mu     sync.RWMutex
+	limits map[string]util.RateLimitInformation
+}
+
+// update records the x-rate-limit-* headers of res and returns them.
+// Responses without these headers are ignored and nil is returned.
+func (t *rateLimitTracker) update(req *http.Request, res *http.Response) *RateLimitInformation {
+	if t == nil || req == nil || res == nil {
+		return nil
+	}
+
+	rli := responseRateLimit(res)
+	if rli == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.limits == nil {
+		t.limits = map[string]util.RateLimitInformation{}
+	}
+	t.limits[rateLimitKey(req)] = *rli
+
+	return rli
+}
+
+// observeRateLimit feeds the rate limit headers of res to the tracker and the limiter.
+func observeRateLimit(t *rateLimitTracker, l *RateLimiter, req *http.Request, res *http.Response) {
+	rli := t.update(req, res)
+	if rli == nil {
+		return
+	}
+
+	l.observe(rateLimitKey(req), rli)
+}
+
+func (t *rateLimitTracker) get(method, endpoint string) *util.RateLimitInformation {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rli, ok := t.limits[strings.ToUpper(method)+" "+endpointTemplate(endpoint)]
+	if !ok {
+		return nil
+	}
+
+	return &rli
+}
+
+// RateLimit returns the latest rate limit information the API reported for the endpoint,
+// e.g. c.RateLimit("GET", "/2/users/:id/tweets"). It returns nil if no response
+// with x-rate-limit-* headers has been received for the endpoint yet.
+func (c *Client) RateLimit(method, endpoint string) *RateLimitInformation {
+	if c == nil {
+		return nil
+	}
+
+	return c.rateLimits.get(method, endpoint)
+}
@@ -0,0 +1,159 @@
+package gotwi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Client_RateLimit(t *testing.T) {
+	resetAt := time.Unix(int64(100000000), 0)
+
+	cases := []struct {
+		name      string
+		responses []mockResponse
+		method    string
+		endpoint  string
+		wantNil   bool
+		expect    gotwi.RateLimitInformation
+	}{
+		{
+			name: "ok: success response",
+			responses: []mockResponse{
+				{
+					statusCode: http.StatusOK,
+					header: map[string][]string{
+						"Content-Type":           {"application/json"},
+						"X-Rate-Limit-Limit":     {"900"},
+						"X-Rate-Limit-Remaining": {"899"},
+						"X-Rate-Limit-Reset":     {"100000000"},
+					},
+					body: `{}`,
+				},
+			},
+			method:   "GET",
+			endpoint: "/2/users/:id/tweets",
+			expect:   gotwi.RateLimitInformation{Limit: 900, Remaining: 899, ResetAt: &resetAt},
+		},
+		{
+			name: "ok: method is case insensitive",
+			responses: []mockResponse{
+				{
+					statusCode: http.StatusOK,
+					header: map[string][]string{
+						"Content-Type":           {"application/json"},
+						"X-Rate-Limit-Limit":     {"900"},
+						"X-Rate-Limit-Remaining": {"10"},
+						"X-Rate-Limit-Reset":     {"100000000"},
+					},
+					body: `{}`,
+				},
+			},
+			method:   "get",
+			endpoint: "/2/users/:id/tweets",
+			expect:   gotwi.RateLimitInformation{Limit: 900, Remaining: 10, ResetAt: &resetAt},
+		},
+		{
+			name: "ok: too many requests",
+			responses: []mockResponse{
+				{
+					statusCode: http.StatusTooManyRequests,
+					header: map[string][]string{
+						"Content-Type":           {"application/json"},
+						"X-Rate-Limit-Limit":     {"900"},
+						"X-Rate-Limit-Remaining": {"0"},
+						"X-Rate-Limit-Reset":     {"100000000"},
+					},
+					body: `{}`,
+				},
+			},
+			method:   "GET",
+			endpoint: "/2/users/:id/tweets",
+			expect:   gotwi.RateLimitInformation{Limit: 900, Remaining: 0, ResetAt: &resetAt},
+		},
+		{
+			name: "nil: no rate limit headers",
+			responses: []mockResponse{
+				{statusCode: http.StatusOK, body: `{}`},
+			},
+			method:   "GET",
+			endpoint: "/2/users/:id/tweets",
+			wantNil:  true,
+		},
+		{
+			name: "nil: other endpoint",
+			responses: []mockResponse{
+				{
+					statusCode: http.StatusOK,
+					header: map[string][]string{
+						"Content-Type":       {"application/json"},
+						"X-Rate-Limit-Limit": {"900"},
+					},
+					body: `{}`,
+				},
+			},
+			method:   "POST",
+			endpoint: "/2/users/:id/tweets",
+			wantNil:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			calls := 0
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient:  newSequentialMockClient(c.responses, &calls),
+				AccessToken: "token",
+			})
+			asst.NoError(err)
+
+			_ = client.CallAPI(context.Background(), "/2/users/:id/tweets", http.MethodGet, &testParameter{}, &mockAPIResponse{})
+
+			rli := client.RateLimit(c.method, c.endpoint)
+			if c.wantNil {
+				asst.Nil(rli)
+				return
+			}
+
+			asst.NotNil(rli)
+			asst.Equal(c.expect, *rli)
+		})
+	}
+}
+
+func Test_TypedClient_RateLimit(t *testing.T) {
+	asst := assert.New(t)
+
+	calls := 0
+	client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newSequentialMockClient([]mockResponse{
+			{
+				statusCode: http.StatusOK,
+				header: map[string][]string{
+					"Content-Type":           {"application/json"},
+					"X-Rate-Limit-Limit":     {"50"},
+					"X-Rate-Limit-Remaining": {"49"},
+				},
+				body: `{}`,
+			},
+		}, &calls),
+		AccessToken: "token",
+	})
+	asst.NoError(err)
+
+	tc := gotwi.NewTypedClient[*gotwi.MockResponse](client)
+	s, err := tc.CallStreamAPI(context.Background(), "/2/tweets/search/stream", http.MethodGet, &testParameter{})
+	asst.NoError(err)
+	defer s.Stop()
+
+	rli := client.RateLimit("GET", "/2/tweets/search/stream")
+	asst.NotNil(rli)
+	asst.Equal(50, rli.Limit)
+	asst.Equal(49, rli.Remaining)
+}
@@ -0,0 +1,248 @@
+package gotwi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRateLimitWindow = time.Duration(15) * time.Minute
+
+// EndpointLimit is the number of requests allowed to an endpoint per window.
+type EndpointLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// DefaultEndpointLimits are the documented rate limits of the endpoints supported by gotwi,
+// keyed by method and endpoint template. Where the limit differs between app and user
+// authentication, the lower one is used. All windows are 15 minutes.
+// https://developer.twitter.com/en/docs/twitter-api/rate-limits
+var DefaultEndpointLimits = map[string]EndpointLimit{
+	// Tweets
+	"GET /2/tweets":                                    {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/tweets/:id":                                {Limit: 300, Window: defaultRateLimitWindow},
+	"POST /2/tweets":                                   {Limit: 200, Window: defaultRateLimitWindow},
+	"DELETE /2/tweets/:id":                             {Limit: 50, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/tweets":                          {Limit: 900, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/mentions":                        {Limit: 180, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/timelines/reverse_chronological": {Limit: 180, Window: defaultRateLimitWindow},
+	"GET /2/tweets/search/recent":                      {Limit: 180, Window: defaultRateLimitWindow},
+	"GET /2/tweets/search/all":                         {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/tweets/counts/recent":                      {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/tweets/counts/all":                         {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/tweets/search/stream/rules":                {Limit: 450, Window: defaultRateLimitWindow},
+	"POST /2/tweets/search/stream/rules":               {Limit: 450, Window: defaultRateLimitWindow},
+	"GET /2/tweets/search/stream":                      {Limit: 50, Window: defaultRateLimitWindow},
+	"GET /2/tweets/sample/stream":                      {Limit: 50, Window: defaultRateLimitWindow},
+	"GET /2/tweets/:id/retweeted_by":                   {Limit: 75, Window: defaultRateLimitWindow},
+	"POST /2/users/:id/retweets":                       {Limit: 50, Window: defaultRateLimitWindow},
+	"DELETE /2/users/:id/retweets/:source_tweet_id":    {Limit: 50, Window: defaultRateLimitWindow},
+	"GET /2/tweets/:id/liking_users":                   {Limit: 75, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/liked_tweets":                    {Limit: 75, Window: defaultRateLimitWindow},
+	"POST /2/users/:id/likes":                          {Limit: 50, Window: defaultRateLimitWindow},
+	"DELETE /2/users/:id/likes/:tweet_id":              {Limit: 50, Window: defaultRateLimitWindow},
+	"PUT /2/tweets/:id/hidden":                         {Limit: 50, Window: defaultRateLimitWindow},
+	"GET /2/tweets/:id/quote_tweets":                   {Limit: 75, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/bookmarks":                       {Limit: 180, Window: defaultRateLimitWindow},
+	"POST /2/users/:id/bookmarks":                      {Limit: 50, Window: defaultRateLimitWindow},
+	"DELETE /2/users/:id/bookmarks/:tweet_id":          {Limit: 50, Window: defaultRateLimitWindow},
+	// Users
+	"GET /2/users":                       {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/users/:id":                   {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/users/by":                    {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/users/by/username/:username": {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/users/me":                    {Limit: 75, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/following":         {Limit: 15, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/followers":         {Limit: 15, Window: defaultRateLimitWindow},
+	"POST /2/users/:id/following":        {Limit: 50, Window: defaultRateLimitWindow},
+	"DELETE /2/users/:source_user_id/following/:target_user_id": {Limit: 50, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/blocking":                                 {Limit: 15, Window: defaultRateLimitWindow},
+	"POST /2/users/:id/blocking":                                {Limit: 50, Window: defaultRateLimitWindow},
+	"DELETE /2/users/:source_user_id/blocking/:target_user_id":  {Limit: 50, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/muting":                                   {Limit: 15, Window: defaultRateLimitWindow},
+	"POST /2/users/:id/muting":                                  {Limit: 50, Window: defaultRateLimitWindow},
+	"DELETE /2/users/:source_user_id/muting/:target_user_id":    {Limit: 50, Window: defaultRateLimitWindow},
+	// Lists
+	"GET /2/lists/:id":                            {Limit: 75, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/owned_lists":                {Limit: 15, Window: defaultRateLimitWindow},
+	"POST /2/lists":                               {Limit: 300, Window: defaultRateLimitWindow},
+	"PUT /2/lists/:id":                            {Limit: 300, Window: defaultRateLimitWindow},
+	"DELETE /2/lists/:id":                         {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/lists/:id/tweets":                     {Limit: 900, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/list_memberships":           {Limit: 75, Window: defaultRateLimitWindow},
+	"GET /2/lists/:id/members":                    {Limit: 900, Window: defaultRateLimitWindow},
+	"POST /2/lists/:id/members":                   {Limit: 300, Window: defaultRateLimitWindow},
+	"DELETE /2/lists/:id/members/:user_id":        {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/lists/:id/followers":                  {Limit: 180, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/followed_lists":             {Limit: 15, Window: defaultRateLimitWindow},
+	"POST /2/users/:id/followed_lists":            {Limit: 50, Window: defaultRateLimitWindow},
+	"DELETE /2/users/:id/followed_lists/:list_id": {Limit: 50, Window: defaultRateLimitWindow},
+	"GET /2/users/:id/pinned_lists":               {Limit: 15, Window: defaultRateLimitWindow},
+	"POST /2/users/:id/pinned_lists":              {Limit: 50, Window: defaultRateLimitWindow},
+	"DELETE /2/users/:id/pinned_lists/:list_id":   {Limit: 50, Window: defaultRateLimitWindow},
+	// Spaces
+	"GET /2/spaces/:id":            {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/spaces":                {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/spaces/by/creator_ids": {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/spaces/:id/buyers":     {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/spaces/:id/tweets":     {Limit: 300, Window: defaultRateLimitWindow},
+	"GET /2/spaces/search":         {Limit: 300, Window: defaultRateLimitWindow},
+	// Compliance
+	"GET /2/compliance/jobs":     {Limit: 150, Window: defaultRateLimitWindow},
+	"GET /2/compliance/jobs/:id": {Limit: 150, Window: defaultRateLimitWindow},
+	"POST /2/compliance/jobs":    {Limit: 150, Window: defaultRateLimitWindow},
+}
+
+// RateLimitExceededError is returned by CallAPI and CallStreamAPI when the rate limiter
+// is in fail fast mode and the known budget for the endpoint is exhausted.
+type RateLimitExceededError struct {
+	Endpoint string
+	ResetAt  time.Time
+}
+
+func (e *RateLimitExceededError) Error() string {
+	return fmt.Sprintf("Rate limit for '%s' is exhausted until %s.", e.Endpoint, e.ResetAt.Format(time.RFC3339))
+}
+
+// Is makes errors.Is(err, ErrRateLimited) true.
+func (e *RateLimitExceededError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+type NewRateLimiterInput struct {
+	// FailFast makes requests fail with RateLimitExceededError instead of
+	// waiting for the rate limit window to reset.
+	FailFast bool
+
+	// Limits seeds the limiter before any response has been received,
+	// keyed like "GET /2/users/:id/tweets". DefaultEndpointLimits can be used.
+	Limits map[string]EndpointLimit
+}
+
+// RateLimiter blocks requests that would exceed the remaining rate limit budget of an endpoint.
+// The budgets are learned from the x-rate-limit-* response headers. A RateLimiter is safe for
+// concurrent use and can be shared by several clients that use the same credentials.
+type RateLimiter struct {
+	failFast bool
+	limits   map[string]EndpointLimit
+	now      func() time.Time
+
+	mu      sync.Mutex
+	budgets map[string]*rateBudget
+}
+
+type rateBudget struct {
+	remaining int
+	resetAt   time.Time
+}
+
+func NewRateLimiter(in *NewRateLimiterInput) *RateLimiter {
+	l := &RateLimiter{
+		limits:  map[string]EndpointLimit{},
+		now:     time.Now,
+		budgets: map[string]*rateBudget{},
+	}
+
+	if in == nil {
+		return l
+	}
+
+	l.failFast = in.FailFast
+	for k, v := range in.Limits {
+		if v.Limit <= 0 || v.Window <= 0 {
+			continue
+		}
+		l.limits[normalizeRateLimitKey(k)] = v
+	}
+
+	return l
+}
+
+// normalizeRateLimitKey upper-cases the method part of a key such as "get /2/tweets".
+func normalizeRateLimitKey(k string) string {
+	method, endpoint, ok := strings.Cut(k, " ")
+	if !ok {
+		return k
+	}
+	return strings.ToUpper(method) + " " + endpoint
+}
+
+// wait takes one request from the budget of the endpoint, blocking until
+// the window resets if the budget is exhausted.
+func (l *RateLimiter) wait(ctx context.Context, key string) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		d, ok := l.reserve(key)
+		if ok {
+			return nil
+		}
+
+		if l.failFast {
+			return &RateLimitExceededError{Endpoint: key, ResetAt: l.now().Add(d)}
+		}
+
+		if err := sleepContext(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes one request from the budget. If the budget is exhausted,
+// it returns the duration until the window resets and false.
+func (l *RateLimiter) reserve(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.budgets[key]
+	if ok && !now.Before(b.resetAt) {
+		delete(l.budgets, key)
+		ok = false
+	}
+
+	if !ok {
+		el, seeded := l.limits[key]
+		if !seeded {
+			// The budget is unknown until the API reports it.
+			return 0, true
+		}
+		b = &rateBudget{remaining: el.Limit, resetAt: now.Add(el.Window)}
+		l.budgets[key] = b
+	}
+
+	if b.remaining > 0 {
+		b.remaining--
+		return 0, true
+	}
+
+	return b.resetAt.Sub(now), false
+}
+
+// observe updates the budget of the endpoint with the rate limit information the API reported.
+func (l *RateLimiter) observe(key string, rli *RateLimitInformation) {
+	if l == nil || rli == nil || rli.ResetAt == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.budgets[key]
+	if !ok || rli.ResetAt.After(b.resetAt) {
+		l.budgets[key] = &rateBudget{remaining: rli.Remaining, resetAt: *rli.ResetAt}
+		return
+	}
+
+	// Other requests in the same window may still be in flight,
+	// so never give back budget that has already been reserved.
+	if rli.Remaining < b.remaining {
+		b.remaining = rli.Remaining
+	}
+	b.resetAt = *rli.ResetAt
+}
@@ -0,0 +1,212 @@
+package gotwi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RateLimiter(t *testing.T) {
+	futureReset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	exhausted := mockResponse{
+		statusCode: http.StatusOK,
+		header: map[string][]string{
+			"Content-Type":           {"application/json"},
+			"X-Rate-Limit-Limit":     {"15"},
+			"X-Rate-Limit-Remaining": {"0"},
+			"X-Rate-Limit-Reset":     {futureReset},
+		},
+		body: `{}`,
+	}
+
+	cases := []struct {
+		name        string
+		in          *gotwi.NewRateLimiterInput
+		responses   []mockResponse
+		requests    int
+		expectCalls int
+		expectErrs  int
+	}{
+		{
+			name: "ok: seeded limit",
+			in: &gotwi.NewRateLimiterInput{
+				FailFast: true,
+				Limits: map[string]gotwi.EndpointLimit{
+					"GET /2/users/:id/tweets": {Limit: 2, Window: time.Minute},
+				},
+			},
+			responses:   []mockResponse{{statusCode: http.StatusOK, body: `{}`}},
+			requests:    3,
+			expectCalls: 2,
+			expectErrs:  1,
+		},
+		{
+			name: "ok: seeded limit with lower case method",
+			in: &gotwi.NewRateLimiterInput{
+				FailFast: true,
+				Limits: map[string]gotwi.EndpointLimit{
+					"get /2/users/:id/tweets": {Limit: 1, Window: time.Minute},
+				},
+			},
+			responses:   []mockResponse{{statusCode: http.StatusOK, body: `{}`}},
+			requests:    2,
+			expectCalls: 1,
+			expectErrs:  1,
+		},
+		{
+			name:        "ok: learned from headers",
+			in:          &gotwi.NewRateLimiterInput{FailFast: true},
+			responses:   []mockResponse{exhausted},
+			requests:    3,
+			expectCalls: 1,
+			expectErrs:  2,
+		},
+		{
+			name: "ok: other endpoint is not limited",
+			in: &gotwi.NewRateLimiterInput{
+				FailFast: true,
+				Limits: map[string]gotwi.EndpointLimit{
+					"GET /2/users/:id/mentions": {Limit: 1, Window: time.Minute},
+				},
+			},
+			responses:   []mockResponse{{statusCode: http.StatusOK, body: `{}`}},
+			requests:    3,
+			expectCalls: 3,
+		},
+		{
+			name:        "ok: no limiter input",
+			in:          nil,
+			responses:   []mockResponse{{statusCode: http.StatusOK, body: `{}`}},
+			requests:    3,
+			expectCalls: 3,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			calls := 0
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient:  newSequentialMockClient(c.responses, &calls),
+				AccessToken: "token",
+				RateLimiter: gotwi.NewRateLimiter(c.in),
+			})
+			asst.NoError(err)
+
+			errs := 0
+			for i := 0; i < c.requests; i++ {
+				err := client.CallAPI(context.Background(), "/2/users/:id/tweets", http.MethodGet, &testParameter{}, &mockAPIResponse{})
+				if err != nil {
+					var rle *gotwi.RateLimitExceededError
+					asst.True(errors.As(err, &rle))
+					asst.Equal("GET /2/users/:id/tweets", rle.Endpoint)
+					errs++
+				}
+			}
+
+			asst.Equal(c.expectCalls, calls)
+			asst.Equal(c.expectErrs, errs)
+		})
+	}
+}
+
+func Test_RateLimiter_WindowReset(t *testing.T) {
+	asst := assert.New(t)
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := gotwi.NewRateLimiter(&gotwi.NewRateLimiterInput{
+		FailFast: true,
+		Limits: map[string]gotwi.EndpointLimit{
+			"GET /2/tweets": {Limit: 1, Window: time.Minute},
+		},
+	})
+	l.SetNow(func() time.Time { return now })
+
+	calls := 0
+	client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient:  newSequentialMockClient([]mockResponse{{statusCode: http.StatusOK, body: `{}`}}, &calls),
+		AccessToken: "token",
+		RateLimiter: l,
+	})
+	asst.NoError(err)
+
+	asst.NoError(client.CallAPI(context.Background(), "/2/tweets", http.MethodGet, &testParameter{}, &mockAPIResponse{}))
+	asst.Error(client.CallAPI(context.Background(), "/2/tweets", http.MethodGet, &testParameter{}, &mockAPIResponse{}))
+
+	now = now.Add(time.Minute)
+	asst.NoError(client.CallAPI(context.Background(), "/2/tweets", http.MethodGet, &testParameter{}, &mockAPIResponse{}))
+	asst.Equal(2, calls)
+}
+
+func Test_RateLimiter_WaitContextCanceled(t *testing.T) {
+	asst := assert.New(t)
+
+	calls := 0
+	client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient:  newSequentialMockClient([]mockResponse{{statusCode: http.StatusOK, body: `{}`}}, &calls),
+		AccessToken: "token",
+		RateLimiter: gotwi.NewRateLimiter(&gotwi.NewRateLimiterInput{
+			Limits: map[string]gotwi.EndpointLimit{
+				"GET /2/tweets": {Limit: 1, Window: time.Hour},
+			},
+		}),
+	})
+	asst.NoError(err)
+
+	asst.NoError(client.CallAPI(context.Background(), "/2/tweets", http.MethodGet, &testParameter{}, &mockAPIResponse{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Millisecond)
+	defer cancel()
+
+	err = client.CallAPI(ctx, "/2/tweets", http.MethodGet, &testParameter{}, &mockAPIResponse{})
+	asst.ErrorIs(err, context.DeadlineExceeded)
+	asst.Equal(1, calls)
+}
+
+func Test_RateLimiter_Concurrent(t *testing.T) {
+	asst := assert.New(t)
+
+	l := gotwi.NewRateLimiter(&gotwi.NewRateLimiterInput{
+		FailFast: true,
+		Limits: map[string]gotwi.EndpointLimit{
+			"GET /2/tweets": {Limit: 5, Window: time.Hour},
+		},
+	})
+
+	client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}
+		}),
+		AccessToken: "token",
+		RateLimiter: l,
+	})
+	asst.NoError(err)
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		ok int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.CallAPI(context.Background(), "/2/tweets", http.MethodGet, &testParameter{}, &mockAPIResponse{}); err == nil {
+				mu.Lock()
+				ok++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	asst.Equal(5, ok)
+}
@@ -12,6 +12,10 @@ type Non2XXError struct {
 	Status        string                     `json:"-"`
 	StatusCode    int                        `json:"-"`
 	RateLimitInfo *util.RateLimitInformation `json:"-"`
+
+	// OAuthError and OAuthErrorDescription are returned by the OAuth 2.0 token endpoints.
+	OAuthError            string `json:"error,omitempty"`
+	OAuthErrorDescription string `json:"error_description,omitempty"`
 }
 
 type ErrorInformation struct {
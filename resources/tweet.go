@@ -64,6 +64,10 @@ type TweetEntityTag struct {
 	Start *int    `json:"start"`
 	End   *int    `json:"end"`
 	Tag   *string `json:"tag"`
+
+	// Username and ID are set for mentions, which have no Tag.
+	Username *string `json:"username"`
+	ID       *string `json:"id"`
 }
 
 type URL struct {
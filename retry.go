@@ -0,0 +1,147 @@
+package gotwi
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/xxiiaaon/gotwi/resources"
+)
+
+const (
+	defaultRetryBaseDelay = time.Duration(1) * time.Second
+	defaultRetryMaxDelay  = time.Duration(64) * time.Second
+
+	errorCodeOverCapacity resources.ErrorCode = 130
+)
+
+// RetryPolicy configures how Client.CallAPI retries requests that failed with
+// 429 Too Many Requests or a temporary 5XX error. A nil policy disables retries.
+// 5XX errors are only retried for idempotent methods, because a POST such as
+// creating a Tweet may have been committed before the error was returned.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+
+	// BaseDelay is the initial backoff for 5XX errors. Default is 1 second.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the exponential backoff for 5XX errors. Default is 64 seconds.
+	MaxDelay time.Duration
+
+	// MaxRateLimitWait caps how long to wait for a rate limit window to reset.
+	// If the reset is further away than this, the 429 error is returned immediately.
+	// Zero means no cap.
+	MaxRateLimitWait time.Duration
+
+	// RetryNonIdempotent also retries 5XX errors of POST and PATCH requests,
+	// which may perform the operation twice.
+	RetryNonIdempotent bool
+}
+
+var idempotentMethods = map[string]struct{}{
+	http.MethodGet:    {},
+	http.MethodHead:   {},
+	http.MethodPut:    {},
+	http.MethodDelete: {},
+}
+
+var retryableStatusCodes = map[int]struct{}{
+	http.StatusInternalServerError: {},
+	http.StatusBadGateway:          {},
+	http.StatusServiceUnavailable:  {},
+}
+
+// retryDelay returns how long to wait before the next attempt, and false if the
+// error should not be retried. attempt is the number of attempts already made.
+// A 429 error is retried for every method, because the request was not processed.
+func (p *RetryPolicy) retryDelay(attempt int, method string, e *resources.Non2XXError, now time.Time) (time.Duration, bool) {
+	if p == nil || e == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if e.StatusCode == http.StatusTooManyRequests {
+		if e.RateLimitInfo == nil || e.RateLimitInfo.ResetAt == nil {
+			return p.backoff(attempt), true
+		}
+
+		d := e.RateLimitInfo.ResetAt.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		if p.MaxRateLimitWait > 0 && d > p.MaxRateLimitWait {
+			return 0, false
+		}
+		return d, true
+	}
+
+	if temporaryError(e) && p.retriesMethod(method) {
+		return p.backoff(attempt), true
+	}
+
+	return 0, false
+}
+
+func (p *RetryPolicy) retriesMethod(method string) bool {
+	if p.RetryNonIdempotent {
+		return true
+	}
+	_, ok := idempotentMethods[strings.ToUpper(method)]
+	return ok
+}
+
+// temporaryError reports whether e is a 5XX error that is likely to succeed on retry.
+func temporaryError(e *resources.Non2XXError) bool {
+	if _, ok := retryableStatusCodes[e.StatusCode]; ok {
+		return true
+	}
+
+	for _, ae := range e.APIErrors {
+		if ae.Code == errorCodeOverCapacity {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns a fully jittered exponential delay for the given attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	max := p.MaxDelay
+	if max <= 0 {
+		max = defaultRetryMaxDelay
+	}
+
+	d := max
+	if attempt-1 < 32 {
+		if exp := base << (attempt - 1); exp > 0 && exp < max {
+			d = exp
+		}
+	}
+
+	return time.Duration(rand.Int64N(int64(d) + 1))
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
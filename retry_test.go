@@ -0,0 +1,242 @@
+package gotwi_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockResponse struct {
+	statusCode int
+	header     map[string][]string
+	body       string
+}
+
+func newSequentialMockClient(responses []mockResponse, calls *int) *http.Client {
+	return newMockClient(func(req *http.Request) *http.Response {
+		r := responses[len(responses)-1]
+		if *calls < len(responses) {
+			r = responses[*calls]
+		}
+		*calls++
+
+		header := r.header
+		if header == nil {
+			header = map[string][]string{"Content-Type": {"application/json"}}
+		}
+
+		return &http.Response{
+			Status:     http.StatusText(r.statusCode),
+			StatusCode: r.statusCode,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(r.body)),
+		}
+	})
+}
+
+func Test_CallAPI_Retry(t *testing.T) {
+	pastReset := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	futureReset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+
+	policy := &gotwi.RetryPolicy{
+		MaxAttempts:      3,
+		BaseDelay:        time.Millisecond,
+		MaxDelay:         time.Duration(5) * time.Millisecond,
+		MaxRateLimitWait: time.Minute,
+	}
+
+	cases := []struct {
+		name        string
+		policy      *gotwi.RetryPolicy
+		method      string
+		responses   []mockResponse
+		wantErr     bool
+		expectCalls int
+	}{
+		{
+			name:   "ok: retry 503 then succeed",
+			policy: policy,
+			responses: []mockResponse{
+				{statusCode: http.StatusServiceUnavailable, body: `{}`},
+				{statusCode: http.StatusOK, body: `{}`},
+			},
+			expectCalls: 2,
+		},
+		{
+			name:   "ok: retry over capacity error code",
+			policy: policy,
+			responses: []mockResponse{
+				{statusCode: http.StatusGatewayTimeout, body: `{"errors":[{"message":"Over capacity","code":130}]}`},
+				{statusCode: http.StatusOK, body: `{}`},
+			},
+			expectCalls: 2,
+		},
+		{
+			name:   "ok: retry 429 after reset",
+			policy: policy,
+			responses: []mockResponse{
+				{
+					statusCode: http.StatusTooManyRequests,
+					header: map[string][]string{
+						"Content-Type":           {"application/json"},
+						"X-Rate-Limit-Limit":     {"15"},
+						"X-Rate-Limit-Remaining": {"0"},
+						"X-Rate-Limit-Reset":     {pastReset},
+					},
+					body: `{}`,
+				},
+				{statusCode: http.StatusOK, body: `{}`},
+			},
+			expectCalls: 2,
+		},
+		{
+			name:   "error: attempts exhausted",
+			policy: policy,
+			responses: []mockResponse{
+				{statusCode: http.StatusInternalServerError, body: `{}`},
+			},
+			wantErr:     true,
+			expectCalls: 3,
+		},
+		{
+			name:   "error: not retryable status",
+			policy: policy,
+			responses: []mockResponse{
+				{statusCode: http.StatusBadRequest, body: `{}`},
+			},
+			wantErr:     true,
+			expectCalls: 1,
+		},
+		{
+			name:   "error: rate limit reset is too far",
+			policy: policy,
+			responses: []mockResponse{
+				{
+					statusCode: http.StatusTooManyRequests,
+					header: map[string][]string{
+						"Content-Type":           {"application/json"},
+						"X-Rate-Limit-Limit":     {"15"},
+						"X-Rate-Limit-Remaining": {"0"},
+						"X-Rate-Limit-Reset":     {futureReset},
+					},
+					body: `{}`,
+				},
+			},
+			wantErr:     true,
+			expectCalls: 1,
+		},
+		{
+			name:   "ok: retry 429 of POST",
+			policy: policy,
+			method: http.MethodPost,
+			responses: []mockResponse{
+				{
+					statusCode: http.StatusTooManyRequests,
+					header: map[string][]string{
+						"Content-Type":           {"application/json"},
+						"X-Rate-Limit-Limit":     {"15"},
+						"X-Rate-Limit-Remaining": {"0"},
+						"X-Rate-Limit-Reset":     {pastReset},
+					},
+					body: `{}`,
+				},
+				{statusCode: http.StatusOK, body: `{}`},
+			},
+			expectCalls: 2,
+		},
+		{
+			name: "ok: retry 503 of POST with RetryNonIdempotent",
+			policy: &gotwi.RetryPolicy{
+				MaxAttempts:        3,
+				BaseDelay:          time.Millisecond,
+				MaxDelay:           time.Duration(5) * time.Millisecond,
+				RetryNonIdempotent: true,
+			},
+			method: http.MethodPost,
+			responses: []mockResponse{
+				{statusCode: http.StatusServiceUnavailable, body: `{}`},
+				{statusCode: http.StatusOK, body: `{}`},
+			},
+			expectCalls: 2,
+		},
+		{
+			name:   "error: 503 of POST is not retried",
+			policy: policy,
+			method: http.MethodPost,
+			responses: []mockResponse{
+				{statusCode: http.StatusServiceUnavailable, body: `{}`},
+				{statusCode: http.StatusOK, body: `{}`},
+			},
+			wantErr:     true,
+			expectCalls: 1,
+		},
+		{
+			name:   "error: no retry policy",
+			policy: nil,
+			responses: []mockResponse{
+				{statusCode: http.StatusServiceUnavailable, body: `{}`},
+				{statusCode: http.StatusOK, body: `{}`},
+			},
+			wantErr:     true,
+			expectCalls: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			calls := 0
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient:  newSequentialMockClient(c.responses, &calls),
+				AccessToken: "token",
+				RetryPolicy: c.policy,
+			})
+			asst.NoError(err)
+
+			method := c.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			err = client.CallAPI(context.Background(), "/2/tweets", method, &testParameter{}, &mockAPIResponse{})
+			if c.wantErr {
+				asst.Error(err)
+			} else {
+				asst.NoError(err)
+			}
+			asst.Equal(c.expectCalls, calls)
+		})
+	}
+}
+
+func Test_CallAPI_RetryContextCanceled(t *testing.T) {
+	asst := assert.New(t)
+
+	calls := 0
+	client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newSequentialMockClient([]mockResponse{
+			{statusCode: http.StatusServiceUnavailable, body: `{}`},
+		}, &calls),
+		AccessToken: "token",
+		RetryPolicy: &gotwi.RetryPolicy{
+			MaxAttempts: 5,
+			BaseDelay:   time.Hour,
+			MaxDelay:    time.Hour,
+		},
+	})
+	asst.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Millisecond)
+	defer cancel()
+
+	err = client.CallAPI(ctx, "/2/tweets", http.MethodGet, &testParameter{}, &mockAPIResponse{})
+	asst.ErrorIs(err, context.DeadlineExceeded)
+	asst.Equal(1, calls)
+}
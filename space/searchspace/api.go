@@ -8,7 +8,7 @@ import (
 )
 
 const (
-	listEndpoint = "https://api.twitter.com/2/spaces/search"
+	listEndpoint = "/2/spaces/search"
 )
 
 // Return live or scheduled Spaces matching your specified search terms.
@@ -18,6 +18,9 @@ const (
 func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.ListOutput, error) {
 	res := &types.ListOutput{}
 	if err := c.CallAPI(ctx, listEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -8,11 +8,11 @@ import (
 )
 
 const (
-	getEndpoint              = "https://api.twitter.com/2/spaces/:id"
-	listEndpoint             = "https://api.twitter.com/2/spaces"
-	listByCreatorIDsEndpoint = "https://api.twitter.com/2/spaces/by/creator_ids"
-	listBuyersEndpoint       = "https://api.twitter.com/2/spaces/:id/buyers"
-	listTweetsEndpoint       = "https://api.twitter.com/2/spaces/:id/tweets"
+	getEndpoint              = "/2/spaces/:id"
+	listEndpoint             = "/2/spaces"
+	listByCreatorIDsEndpoint = "/2/spaces/by/creator_ids"
+	listBuyersEndpoint       = "/2/spaces/:id/buyers"
+	listTweetsEndpoint       = "/2/spaces/:id/tweets"
 )
 
 // Returns a variety of information about a single Space specified by the requested ID.
@@ -20,6 +20,9 @@ const (
 func Get(ctx context.Context, c *gotwi.Client, p *types.GetInput) (*types.GetOutput, error) {
 	res := &types.GetOutput{}
 	if err := c.CallAPI(ctx, getEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -31,6 +34,9 @@ func Get(ctx context.Context, c *gotwi.Client, p *types.GetInput) (*types.GetOut
 func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.ListOutput, error) {
 	res := &types.ListOutput{}
 	if err := c.CallAPI(ctx, listEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -43,6 +49,9 @@ func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.List
 func ListByCreatorIDs(ctx context.Context, c *gotwi.Client, p *types.ListByCreatorIDsInput) (*types.ListByCreatorIDsOutput, error) {
 	res := &types.ListByCreatorIDsOutput{}
 	if err := c.CallAPI(ctx, listByCreatorIDsEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -55,6 +64,9 @@ func ListByCreatorIDs(ctx context.Context, c *gotwi.Client, p *types.ListByCreat
 func ListBuyers(ctx context.Context, c *gotwi.Client, p *types.ListBuyersInput) (*types.ListBuyersOutput, error) {
 	res := &types.ListBuyersOutput{}
 	if err := c.CallAPI(ctx, listBuyersEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -66,6 +78,9 @@ func ListBuyers(ctx context.Context, c *gotwi.Client, p *types.ListBuyersInput)
 func ListTweets(ctx context.Context, c *gotwi.Client, p *types.ListTweetsInput) (*types.ListTweetsOutput, error) {
 	res := &types.ListTweetsOutput{}
 	if err := c.CallAPI(ctx, listTweetsEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
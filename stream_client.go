@@ -2,19 +2,38 @@ package gotwi
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/xxiiaaon/gotwi/internal/util"
 )
 
+// DefaultMaxStreamMessageSize is the size of the largest stream message that
+// StreamClient can read when NewClientInput.MaxStreamMessageSize is 0.
+const DefaultMaxStreamMessageSize = 1024 * 1024
+
+// initialStreamBufferSize is the initial size of the buffer of the scanner, which grows up to the max message size.
+const initialStreamBufferSize = 64 * 1024
+
 type StreamClient[T util.Response] struct {
-	response *http.Response
-	stream   *bufio.Scanner
+	response    *http.Response
+	stream      *bufio.Scanner
+	stopped     atomic.Bool
+	err         error
+	onHeartbeat func()
+
+	ctx             context.Context
+	instrumentation Instrumentation
+	info            RequestInfo
 }
 
-func newStreamClient[T util.Response](httpRes *http.Response) (*StreamClient[T], error) {
+// newStreamClient returns a StreamClient that reads messages of up to maxMessageSize bytes
+// from httpRes. Zero means DefaultMaxStreamMessageSize.
+func newStreamClient[T util.Response](httpRes *http.Response, maxMessageSize int) (*StreamClient[T], error) {
 	if httpRes == nil {
 		return nil, errors.New("HTTP Response is nil.")
 	}
@@ -23,7 +42,11 @@ func newStreamClient[T util.Response](httpRes *http.Response) (*StreamClient[T],
 		return nil, errors.New("HTTP Response body has already closed.")
 	}
 
+	if maxMessageSize <= 0 {
+		maxMessageSize = DefaultMaxStreamMessageSize
+	}
 	s := bufio.NewScanner(httpRes.Body)
+	s.Buffer(make([]byte, 0, min(initialStreamBufferSize, maxMessageSize)), maxMessageSize)
 
 	return &StreamClient[T]{
 		response: httpRes,
@@ -31,20 +54,142 @@ func newStreamClient[T util.Response](httpRes *http.Response) (*StreamClient[T],
 	}, nil
 }
 
+func (s *StreamClient[T]) instrument(ctx context.Context, ins Instrumentation, info RequestInfo) {
+	s.ctx = ctx
+	s.instrumentation = ins
+	s.info = info
+}
+
+// SetHeartbeatHandler sets fn to be called for every keep-alive line, which Receive skips.
+// fn is called on the goroutine that calls Receive.
+func (s *StreamClient[T]) SetHeartbeatHandler(fn func()) {
+	if s == nil {
+		return
+	}
+	s.onHeartbeat = fn
+}
+
+// Receive waits for the next message and reports whether there is one to Read.
+// Keep-alive lines are not messages; they are reported to the heartbeat handler.
+// When Receive returns false, Err returns the read failure, if any. Messages larger
+// than the max message size end the stream with bufio.ErrTooLong.
 func (s *StreamClient[T]) Receive() bool {
 	if s == nil {
 		return false
 	}
-	return s.stream.Scan()
+
+	for {
+		if !s.stream.Scan() {
+			if err := s.stream.Err(); err != nil && s.err == nil && !s.stopped.Load() {
+				s.err = err
+			}
+			return false
+		}
+
+		if len(bytes.TrimSpace(s.stream.Bytes())) > 0 {
+			break
+		}
+
+		if s.onHeartbeat != nil {
+			s.onHeartbeat()
+		}
+	}
+
+	if s.instrumentation != nil {
+		s.instrumentation.StreamMessage(s.ctx, s.info, len(s.stream.Bytes()))
+	}
+
+	return true
 }
 
+// Stop closes the stream. It may be called from another goroutine to unblock Receive.
 func (s *StreamClient[T]) Stop() {
 	if s == nil {
 		return
 	}
+	s.stopped.Store(true)
 	s.response.Body.Close()
 }
 
+// Err returns the error that ended the stream: a read failure, a message that Run or
+// Messages could not decode, the error of the handler of Run, or the error of the
+// context given to Run or Messages. It is nil while the stream is open, and when the
+// server closed the stream or Stop was called.
+func (s *StreamClient[T]) Err() error {
+	if s == nil {
+		return nil
+	}
+	return s.err
+}
+
+// Run calls handle for every message until the stream ends, ctx is done, or handle
+// returns an error, and then closes the stream. An in-band error message ends the
+// stream with a *StreamError. It returns the error that ended the stream, which is nil if the server closed it.
+func (s *StreamClient[T]) Run(ctx context.Context, handle func(T) error) error {
+	if s == nil {
+		return errors.New("StreamClient is nil.")
+	}
+
+	// closing the body is the only way to unblock a pending read
+	stopOnDone := context.AfterFunc(ctx, s.Stop)
+	defer stopOnDone()
+	defer s.Stop()
+
+	for s.Receive() {
+		out, err := s.Read()
+		if err != nil {
+			return s.fail(err)
+		}
+
+		if err := handle(out); err != nil {
+			return s.fail(err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return s.fail(err)
+	}
+	return s.err
+}
+
+// Messages returns a channel of the messages of the stream, which is closed when the
+// stream ends or ctx is done. Check Err after the channel is closed.
+//
+//	for out := range s.Messages(ctx) {
+//		// use out
+//	}
+//	if err := s.Err(); err != nil {
+//		return err
+//	}
+func (s *StreamClient[T]) Messages(ctx context.Context) <-chan T {
+	ch := make(chan T)
+	if s == nil {
+		close(ch)
+		return ch
+	}
+
+	go func() {
+		defer close(ch)
+		s.Run(ctx, func(out T) error {
+			select {
+			case ch <- out:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return ch
+}
+
+func (s *StreamClient[T]) fail(err error) error {
+	if s.err == nil {
+		s.err = err
+	}
+	return s.err
+}
+
 func safeUnmarshal(input []byte, target interface{}) error {
 	if len(input) == 0 {
 		return nil
@@ -52,15 +197,25 @@ func safeUnmarshal(input []byte, target interface{}) error {
 	return json.Unmarshal(input, target)
 }
 
+// Read decodes the message received by Receive. If the message is an in-band error,
+// such as an operational disconnect, a *StreamError is returned instead.
 func (s *StreamClient[T]) Read() (T, error) {
 	var n T
 	if s == nil {
 		return n, errors.New("StreamClient is nil.")
 	}
 
-	t := s.stream.Text()
+	if s.err != nil {
+		return n, s.err
+	}
+
+	b := s.stream.Bytes()
+	if se := decodeStreamError(b); se != nil {
+		return n, se
+	}
+
 	out := new(T)
-	if err := safeUnmarshal([]byte(t), out); err != nil {
+	if err := safeUnmarshal(b, out); err != nil {
 		return n, err
 	}
 
@@ -1,10 +1,14 @@
 package gotwi_test
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/xxiiaaon/gotwi"
 	"github.com/stretchr/testify/assert"
@@ -204,3 +208,292 @@ func Test_Read(t *testing.T) {
 		})
 	}
 }
+
+type errReader struct {
+	data string
+	err  error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func Test_StreamClient_Err(t *testing.T) {
+	asst := assert.New(t)
+
+	readErr := errors.New("connection reset by peer")
+	st, _ := gotwi.ExportNewStreamClient(&http.Response{
+		Body: io.NopCloser(&errReader{data: `{"text": "1"}` + "\n", err: readErr}),
+	})
+
+	asst.True(st.Receive())
+	out, err := st.Read()
+	asst.NoError(err)
+	asst.Equal("1", out.Text)
+	asst.NoError(st.Err())
+
+	asst.False(st.Receive())
+	_, err = st.Read()
+	asst.Equal(readErr, err)
+	asst.Equal(readErr, st.Err())
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	stopped, _ := gotwi.ExportNewStreamClient(&http.Response{Body: pr})
+	stopped.Stop()
+	asst.False(stopped.Receive())
+	asst.NoError(stopped.Err())
+
+	var nilSt *gotwi.StreamClient[*gotwi.MockResponse]
+	asst.NoError(nilSt.Err())
+}
+
+func Test_StreamClient_Run(t *testing.T) {
+	handlerErr := errors.New("handler error")
+	readErr := errors.New("connection reset by peer")
+
+	cases := []struct {
+		name       string
+		body       io.Reader
+		handlerErr error
+		expect     []string
+		wantErr    error
+		anyErr     bool
+	}{
+		{
+			name:   "ok: keep-alives are skipped",
+			body:   strings.NewReader(`{"text": "1"}` + "\r\n\r\n" + `{"text": "2"}` + "\r\n"),
+			expect: []string{"1", "2"},
+		},
+		{
+			name:    "error: read failure",
+			body:    &errReader{data: `{"text": "1"}` + "\n", err: readErr},
+			expect:  []string{"1"},
+			wantErr: readErr,
+		},
+		{
+			name:   "error: decode failure",
+			body:   strings.NewReader(`{"text": "1"}` + "\n" + `{"text": ` + "\n" + `{"text": "3"}` + "\n"),
+			expect: []string{"1"},
+			anyErr: true,
+		},
+		{
+			name:       "error: handler",
+			body:       strings.NewReader(`{"text": "1"}` + "\n" + `{"text": "2"}` + "\n"),
+			handlerErr: handlerErr,
+			expect:     []string{"1"},
+			wantErr:    handlerErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			st, _ := gotwi.ExportNewStreamClient(&http.Response{Body: io.NopCloser(c.body)})
+			received := []string{}
+			err := st.Run(context.Background(), func(out *gotwi.MockResponse) error {
+				received = append(received, out.Text)
+				return c.handlerErr
+			})
+
+			asst.Equal(c.expect, received)
+			switch {
+			case c.wantErr != nil:
+				asst.Equal(c.wantErr, err)
+			case c.anyErr:
+				asst.Error(err)
+			default:
+				asst.NoError(err)
+			}
+			asst.Equal(err, st.Err())
+		})
+	}
+}
+
+func Test_StreamClient_Run_ContextCanceled(t *testing.T) {
+	asst := assert.New(t)
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	st, _ := gotwi.ExportNewStreamClient(&http.Response{Body: pr})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Duration(20)*time.Millisecond, cancel)
+
+	err := st.Run(ctx, func(*gotwi.MockResponse) error { return nil })
+	asst.ErrorIs(err, context.Canceled)
+	asst.ErrorIs(st.Err(), context.Canceled)
+}
+
+func Test_StreamClient_Messages(t *testing.T) {
+	t.Run("ok", func(tt *testing.T) {
+		asst := assert.New(tt)
+
+		st, _ := gotwi.ExportNewStreamClient(&http.Response{
+			Body: io.NopCloser(strings.NewReader(`{"text": "1"}` + "\r\n\r\n" + `{"text": "2"}` + "\r\n")),
+		})
+
+		received := []string{}
+		for out := range st.Messages(context.Background()) {
+			received = append(received, out.Text)
+		}
+
+		asst.Equal([]string{"1", "2"}, received)
+		asst.NoError(st.Err())
+	})
+
+	t.Run("context canceled", func(tt *testing.T) {
+		asst := assert.New(tt)
+
+		pr, pw := io.Pipe()
+		defer pw.Close()
+		go pw.Write([]byte(`{"text": "1"}` + "\n"))
+		st, _ := gotwi.ExportNewStreamClient(&http.Response{Body: pr})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		received := []string{}
+		for out := range st.Messages(ctx) {
+			received = append(received, out.Text)
+			cancel()
+		}
+
+		asst.Equal([]string{"1"}, received)
+		asst.ErrorIs(st.Err(), context.Canceled)
+	})
+
+	t.Run("nil", func(tt *testing.T) {
+		var st *gotwi.StreamClient[*gotwi.MockResponse]
+		_, ok := <-st.Messages(context.Background())
+		assert.False(tt, ok)
+	})
+}
+
+func Test_StreamClient_MaxMessageSize(t *testing.T) {
+	large := `{"text": "` + strings.Repeat("a", 100*1024) + `"}` + "\n"
+
+	cases := []struct {
+		name    string
+		max     int
+		wantErr error
+	}{
+		{
+			name: "ok: default",
+			max:  0,
+		},
+		{
+			name:    "error: too long",
+			max:     64 * 1024,
+			wantErr: bufio.ErrTooLong,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			st, err := gotwi.ExportNewStreamClientWithMaxMessageSize(&http.Response{
+				Body: io.NopCloser(strings.NewReader(large)),
+			}, c.max)
+			asst.NoError(err)
+
+			if c.wantErr != nil {
+				asst.False(st.Receive())
+				asst.ErrorIs(st.Err(), c.wantErr)
+				return
+			}
+
+			asst.True(st.Receive())
+			out, err := st.Read()
+			asst.NoError(err)
+			asst.Len(out.Text, 100*1024)
+		})
+	}
+}
+
+func Test_StreamClient_Heartbeat(t *testing.T) {
+	asst := assert.New(t)
+
+	st, _ := gotwi.ExportNewStreamClient(&http.Response{
+		Body: io.NopCloser(strings.NewReader("\r\n\r\n" + `{"text": "1"}` + "\r\n\r\n")),
+	})
+
+	heartbeats := 0
+	st.SetHeartbeatHandler(func() { heartbeats++ })
+
+	asst.True(st.Receive())
+	asst.Equal(2, heartbeats)
+	out, err := st.Read()
+	asst.NoError(err)
+	asst.Equal("1", out.Text)
+
+	asst.False(st.Receive())
+	asst.Equal(3, heartbeats)
+	asst.NoError(st.Err())
+}
+
+func Test_StreamClient_StreamError(t *testing.T) {
+	cases := []struct {
+		name                  string
+		line                  string
+		wantErr               bool
+		title                 string
+		operationalDisconnect bool
+		connectionException   bool
+	}{
+		{
+			name:                  "operational disconnect",
+			line:                  `{"errors":[{"title":"operational-disconnect","disconnect_type":"UpstreamOperationalDisconnect","detail":"This stream has been disconnected upstream for operational reasons.","type":"https://api.twitter.com/2/problems/operational-disconnect"}]}`,
+			wantErr:               true,
+			title:                 "operational-disconnect",
+			operationalDisconnect: true,
+		},
+		{
+			name:                "connection exception",
+			line:                `{"title":"ConnectionException","detail":"This stream is currently at the maximum allowed connection limit.","connection_issue":"TooManyConnections","type":"https://api.twitter.com/2/problems/streaming-connection"}`,
+			wantErr:             true,
+			title:               "ConnectionException",
+			connectionException: true,
+		},
+		{
+			name:    "data",
+			line:    `{"data":{"id":"1"},"text":"1"}`,
+			wantErr: false,
+		},
+		{
+			name:    "data with partial errors",
+			line:    `{"data":{"id":"1"},"errors":[{"title":"Not Found Error"}]}`,
+			wantErr: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			st, _ := gotwi.ExportNewStreamClient(&http.Response{
+				Body: io.NopCloser(strings.NewReader(c.line + "\r\n")),
+			})
+			asst.True(st.Receive())
+
+			_, err := st.Read()
+			var se *gotwi.StreamError
+			if !c.wantErr {
+				asst.NoError(err)
+				return
+			}
+
+			asst.ErrorAs(err, &se)
+			asst.Equal(c.title, se.Title)
+			asst.Equal(c.operationalDisconnect, se.OperationalDisconnect())
+			asst.Equal(c.connectionException, se.ConnectionException())
+			asst.NotEmpty(se.Error())
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package gotwi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Titles of the in-band stream errors.
+const (
+	StreamErrorOperationalDisconnect = "operational-disconnect"
+	StreamErrorConnectionException   = "ConnectionException"
+)
+
+// StreamError is an error message sent in a stream instead of data, e.g.
+//
+//	{"errors":[{"title":"operational-disconnect","disconnect_type":"UpstreamOperationalDisconnect",...}]}
+//	{"title":"ConnectionException","connection_issue":"TooManyConnections",...}
+//
+// The server usually closes the connection after it.
+type StreamError struct {
+	Title           string `json:"title"`
+	Detail          string `json:"detail"`
+	Type            string `json:"type"`
+	DisconnectType  string `json:"disconnect_type"`
+	ConnectionIssue string `json:"connection_issue"`
+}
+
+func (e *StreamError) Error() string {
+	reason := e.DisconnectType
+	if reason == "" {
+		reason = e.ConnectionIssue
+	}
+	if reason == "" {
+		return fmt.Sprintf("stream error: %s: %s", e.Title, e.Detail)
+	}
+	return fmt.Sprintf("stream error: %s (%s): %s", e.Title, reason, e.Detail)
+}
+
+// OperationalDisconnect reports whether the server disconnected the stream, e.g. for maintenance.
+func (e *StreamError) OperationalDisconnect() bool {
+	return e.Title == StreamErrorOperationalDisconnect || e.Type == problemTypeBase+StreamErrorOperationalDisconnect
+}
+
+// ConnectionException reports whether the connection was refused, e.g. because
+// there are too many connections.
+func (e *StreamError) ConnectionException() bool {
+	return e.Title == StreamErrorConnectionException || e.ConnectionIssue != ""
+}
+
+// streamErrorMessage is a stream message without data.
+type streamErrorMessage struct {
+	StreamError
+	Errors []StreamError `json:"errors"`
+}
+
+// decodeStreamError returns the in-band error in a stream message, or nil if b is data.
+// Data messages always have the data field, so only the other messages are decoded twice.
+func decodeStreamError(b []byte) *StreamError {
+	if bytes.Contains(b, []byte(`"data"`)) {
+		return nil
+	}
+
+	m := streamErrorMessage{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+
+	if len(m.Errors) > 0 {
+		return &m.Errors[0]
+	}
+	if m.Title != "" {
+		return &m.StreamError
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package gotwi
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultStreamConnectTimeout        = time.Duration(10) * time.Second
+	defaultStreamTLSHandshakeTimeout   = time.Duration(10) * time.Second
+	defaultStreamResponseHeaderTimeout = time.Duration(30) * time.Second
+
+	// DefaultStreamIdleTimeout is the default StreamHTTPConfig.IdleTimeout. The streaming
+	// endpoints send a keep-alive every 20 seconds, so it leaves a margin of 10 seconds.
+	DefaultStreamIdleTimeout = time.Duration(30) * time.Second
+)
+
+// ErrStreamIdleTimeout is the read failure of a stream that received nothing within
+// StreamHTTPConfig.IdleTimeout.
+var ErrStreamIdleTimeout = errors.New("Stream read idle timeout.")
+
+// StreamHTTPConfig configures the HTTP client of the streaming endpoints, which has no
+// overall timeout unlike the client of the other endpoints.
+type StreamHTTPConfig struct {
+	// HTTPClient is used as is for the streaming endpoints, and the timeouts below except
+	// IdleTimeout are ignored. By default, the HTTPClient of the client is used without its
+	// overall timeout if it was given, and a client with the timeouts below otherwise.
+	HTTPClient *http.Client
+
+	// ConnectTimeout limits the time to establish the TCP connection. Default is 10 seconds.
+	ConnectTimeout time.Duration
+
+	// TLSHandshakeTimeout limits the time of the TLS handshake. Default is 10 seconds.
+	TLSHandshakeTimeout time.Duration
+
+	// ResponseHeaderTimeout limits the time to wait for the response headers after
+	// sending the request. Default is 30 seconds.
+	ResponseHeaderTimeout time.Duration
+
+	// IdleTimeout ends the stream with ErrStreamIdleTimeout if no data, including
+	// keep-alives, is read for this long. Default is DefaultStreamIdleTimeout.
+	// A negative value disables it.
+	IdleTimeout time.Duration
+}
+
+var defaultStreamHTTPClient = newStreamHTTPClient(&StreamHTTPConfig{})
+
+// newStreamHTTPClient returns a client with the connect, TLS and response header timeouts of cfg.
+func newStreamHTTPClient(cfg *StreamHTTPConfig) *http.Client {
+	connect := cfg.ConnectTimeout
+	if connect <= 0 {
+		connect = defaultStreamConnectTimeout
+	}
+	tlsHandshake := cfg.TLSHandshakeTimeout
+	if tlsHandshake <= 0 {
+		tlsHandshake = defaultStreamTLSHandshakeTimeout
+	}
+	responseHeader := cfg.ResponseHeaderTimeout
+	if responseHeader <= 0 {
+		responseHeader = defaultStreamResponseHeaderTimeout
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   connect,
+				KeepAlive: time.Duration(30) * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			TLSHandshakeTimeout:   tlsHandshake,
+			ResponseHeaderTimeout: responseHeader,
+		},
+	}
+}
+
+// configureStream sets the HTTP client and the idle timeout of the streaming endpoints from cfg.
+func (c *Client) configureStream(cfg *StreamHTTPConfig) {
+	if cfg == nil {
+		return
+	}
+
+	c.streamIdleTimeout = cfg.IdleTimeout
+	switch {
+	case cfg.HTTPClient != nil:
+		c.streamHTTPClient = cfg.HTTPClient
+	case cfg.ConnectTimeout > 0 || cfg.TLSHandshakeTimeout > 0 || cfg.ResponseHeaderTimeout > 0:
+		c.streamHTTPClient = newStreamHTTPClient(cfg)
+	}
+}
+
+// StreamHTTPClient returns the HTTP client used by the streaming endpoints.
+func (c *Client) StreamHTTPClient() *http.Client {
+	if c.streamHTTPClient != nil {
+		return c.streamHTTPClient
+	}
+
+	if c.Client == nil || c.Client == defaultHTTPClient {
+		return defaultStreamHTTPClient
+	}
+
+	if c.Client.Timeout == 0 {
+		return c.Client
+	}
+
+	// the overall timeout would tear down the stream
+	hc := *c.Client
+	hc.Timeout = 0
+	return &hc
+}
+
+// StreamIdleTimeout returns the idle timeout of the streams, which is 0 if it is disabled.
+func (c *Client) StreamIdleTimeout() time.Duration {
+	switch {
+	case c.streamIdleTimeout < 0:
+		return 0
+	case c.streamIdleTimeout == 0:
+		return DefaultStreamIdleTimeout
+	default:
+		return c.streamIdleTimeout
+	}
+}
+
+// idleTimeoutBody closes the body if nothing is read from it for the timeout.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+}
+
+// withIdleTimeout returns body that fails with ErrStreamIdleTimeout after d without data.
+// A non-positive d returns body as is.
+func withIdleTimeout(body io.ReadCloser, d time.Duration) io.ReadCloser {
+	if d <= 0 {
+		return body
+	}
+
+	b := &idleTimeoutBody{ReadCloser: body, timeout: d}
+	b.timer = time.AfterFunc(d, func() {
+		b.timedOut.Store(true)
+		body.Close()
+	})
+
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && b.timedOut.Load() {
+		return n, ErrStreamIdleTimeout
+	}
+
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.ReadCloser.Close()
+}
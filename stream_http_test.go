@@ -0,0 +1,166 @@
+package gotwi_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StreamHTTPClient(t *testing.T) {
+	restClient := &http.Client{
+		Timeout:   time.Duration(30) * time.Second,
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response { return nil }),
+	}
+	streamClient := &http.Client{}
+
+	cases := []struct {
+		name                  string
+		httpClient            *http.Client
+		stream                *gotwi.StreamHTTPConfig
+		expectClient          *http.Client
+		expectTransport       http.RoundTripper
+		tlsHandshakeTimeout   time.Duration
+		responseHeaderTimeout time.Duration
+		idleTimeout           time.Duration
+	}{
+		{
+			name:                  "default",
+			tlsHandshakeTimeout:   time.Duration(10) * time.Second,
+			responseHeaderTimeout: time.Duration(30) * time.Second,
+			idleTimeout:           gotwi.DefaultStreamIdleTimeout,
+		},
+		{
+			name:            "http client without its overall timeout",
+			httpClient:      restClient,
+			expectTransport: restClient.Transport,
+			idleTimeout:     gotwi.DefaultStreamIdleTimeout,
+		},
+		{
+			name:         "stream http client",
+			httpClient:   restClient,
+			stream:       &gotwi.StreamHTTPConfig{HTTPClient: streamClient, IdleTimeout: time.Minute},
+			expectClient: streamClient,
+			idleTimeout:  time.Minute,
+		},
+		{
+			name:       "stream timeouts",
+			httpClient: restClient,
+			stream: &gotwi.StreamHTTPConfig{
+				TLSHandshakeTimeout:   time.Second,
+				ResponseHeaderTimeout: time.Duration(2) * time.Second,
+				IdleTimeout:           -1,
+			},
+			tlsHandshakeTimeout:   time.Second,
+			responseHeaderTimeout: time.Duration(2) * time.Second,
+			idleTimeout:           0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			client, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient:  c.httpClient,
+				AccessToken: "token",
+				Stream:      c.stream,
+			})
+			asst.NoError(err)
+
+			hc := client.StreamHTTPClient()
+			asst.Equal(time.Duration(0), hc.Timeout)
+			asst.Equal(c.idleTimeout, client.StreamIdleTimeout())
+
+			if c.httpClient != nil {
+				// the REST client is not changed
+				asst.Equal(time.Duration(30)*time.Second, client.Client.Timeout)
+			}
+
+			switch {
+			case c.expectClient != nil:
+				asst.Same(c.expectClient, hc)
+			case c.expectTransport != nil:
+				asst.NotSame(c.httpClient, hc)
+				asst.NotNil(hc.Transport)
+			default:
+				tr, ok := hc.Transport.(*http.Transport)
+				asst.True(ok)
+				asst.Equal(c.tlsHandshakeTimeout, tr.TLSHandshakeTimeout)
+				asst.Equal(c.responseHeaderTimeout, tr.ResponseHeaderTimeout)
+				asst.NotNil(tr.DialContext)
+			}
+		})
+	}
+}
+
+func Test_StreamIdleTimeout(t *testing.T) {
+	cases := []struct {
+		name      string
+		keepAlive time.Duration
+		messages  int
+		wantErr   error
+	}{
+		{
+			name:      "ok: keep-alives prevent the timeout",
+			keepAlive: time.Duration(20) * time.Millisecond,
+			messages:  1,
+		},
+		{
+			name:      "error: idle",
+			keepAlive: 0,
+			messages:  1,
+			wantErr:   gotwi.ErrStreamIdleTimeout,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			pr, pw := io.Pipe()
+			go func() {
+				pw.Write([]byte(`{"data":{"id":"1"}}` + "\r\n"))
+				if c.keepAlive == 0 {
+					return
+				}
+				for i := 0; i < 5; i++ {
+					time.Sleep(c.keepAlive)
+					pw.Write([]byte("\r\n"))
+				}
+				pw.Close()
+			}()
+			defer pw.Close()
+
+			client, _ := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+				HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+					res := newMockResponse(http.StatusOK, "")
+					res.Body = pr
+					return res
+				}),
+				AccessToken: "token",
+				Stream:      &gotwi.StreamHTTPConfig{IdleTimeout: time.Duration(60) * time.Millisecond},
+			})
+
+			s, err := filteredstream.SearchStream(context.Background(), client, &types.SearchStreamInput{})
+			asst.NoError(err)
+
+			n := 0
+			for range s.Messages(context.Background()) {
+				n++
+			}
+			asst.Equal(c.messages, n)
+			if c.wantErr != nil {
+				asst.ErrorIs(s.Err(), c.wantErr)
+			} else {
+				asst.NoError(s.Err())
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package gotwi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xxiiaaon/gotwi/resources"
+)
+
+const (
+	defaultStreamRecorderPrefix = "stream"
+
+	// maxStreamRecorderOpenAttempts is the number of sequences tried when the name of
+	// a new file is taken, e.g. by another recorder writing to the same directory.
+	maxStreamRecorderOpenAttempts = 100
+)
+
+// StreamRecord is a line of the JSONL files written by StreamRecorder.
+type StreamRecord struct {
+	ReceivedAt time.Time       `json:"received_at"`
+	Message    json.RawMessage `json:"message"`
+}
+
+type NewStreamRecorderInput struct {
+	// Dir is the directory of the files. It is created if it does not exist.
+	Dir string
+
+	// Prefix of the file names, which are "<Prefix>-<time>-<sequence>.jsonl". Default is "stream".
+	Prefix string
+
+	// MaxBytes rotates the file once this many bytes (before compression) were written to it.
+	// Zero means no size based rotation.
+	MaxBytes int64
+
+	// MaxAge rotates the file once it is older than this. Zero means no time based rotation.
+	MaxAge time.Duration
+
+	// Gzip compresses the files, which are then named "*.jsonl.gz".
+	Gzip bool
+
+	// Clock defaults to the system clock.
+	Clock Clock
+
+	// OnError is called when a message received through Middleware cannot be recorded.
+	OnError func(error)
+}
+
+// StreamRecorder writes stream messages with the time they were received to rotating JSONL files.
+// Use Middleware to record the streams of a client, and NewReplayStreamClient to replay the files.
+// It is safe for concurrent use.
+type StreamRecorder struct {
+	dir      string
+	prefix   string
+	maxBytes int64
+	maxAge   time.Duration
+	gzip     bool
+	clock    Clock
+	onError  func(error)
+
+	mu       sync.Mutex
+	file     *os.File
+	gz       *gzip.Writer
+	opened   time.Time
+	written  int64
+	sequence int
+	closed   bool
+}
+
+func NewStreamRecorder(in *NewStreamRecorderInput) (*StreamRecorder, error) {
+	if in == nil {
+		return nil, fmt.Errorf("NewStreamRecorderInput is nil.")
+	}
+
+	if in.Dir == "" {
+		return nil, fmt.Errorf("Dir is required.")
+	}
+
+	if err := os.MkdirAll(in.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	r := &StreamRecorder{
+		dir:      in.Dir,
+		prefix:   in.Prefix,
+		maxBytes: in.MaxBytes,
+		maxAge:   in.MaxAge,
+		gzip:     in.Gzip,
+		clock:    in.Clock,
+		onError:  in.OnError,
+	}
+	if r.prefix == "" {
+		r.prefix = defaultStreamRecorderPrefix
+	}
+	if r.clock == nil {
+		r.clock = ClockFunc(time.Now)
+	}
+
+	return r, nil
+}
+
+// Record writes a message. Keep-alive lines are ignored.
+func (r *StreamRecorder) Record(message []byte) error {
+	message = bytes.TrimSpace(message)
+	if len(message) == 0 {
+		return nil
+	}
+
+	now := r.clock.Now().UTC()
+	line, err := json.Marshal(StreamRecord{
+		ReceivedAt: now,
+		Message:    message,
+	})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return fmt.Errorf("StreamRecorder is closed.")
+	}
+
+	if err := r.rotateIfNeeded(now); err != nil {
+		return err
+	}
+
+	var w io.Writer = r.file
+	if r.gz != nil {
+		w = r.gz
+	}
+	n, err := w.Write(line)
+	r.written += int64(n)
+	return err
+}
+
+// rotateIfNeeded opens a new file if there is none or the current one is too large or too old.
+func (r *StreamRecorder) rotateIfNeeded(now time.Time) error {
+	if r.file != nil {
+		full := r.maxBytes > 0 && r.written >= r.maxBytes
+		old := r.maxAge > 0 && now.Sub(r.opened) >= r.maxAge
+		if !full && !old {
+			return nil
+		}
+		if err := r.closeFile(); err != nil {
+			return err
+		}
+	}
+
+	f, err := r.openNext(now)
+	if err != nil {
+		return err
+	}
+
+	r.file = f
+	r.opened = now
+	r.written = 0
+	if r.gzip {
+		r.gz = gzip.NewWriter(f)
+	}
+
+	return nil
+}
+
+// openNext creates the file of the next sequence, skipping the names that already exist.
+func (r *StreamRecorder) openNext(now time.Time) (*os.File, error) {
+	for i := 0; i < maxStreamRecorderOpenAttempts; i++ {
+		r.sequence++
+		name := fmt.Sprintf("%s-%s-%06d.jsonl", r.prefix, now.Format("20060102T150405Z"), r.sequence)
+		if r.gzip {
+			name += ".gz"
+		}
+
+		f, err := os.OpenFile(filepath.Join(r.dir, name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+
+	return nil, fmt.Errorf("No file name is available for %s in %s.", now.Format("20060102T150405Z"), r.dir)
+}
+
+func (r *StreamRecorder) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+
+	var err error
+	if r.gz != nil {
+		err = r.gz.Close()
+		r.gz = nil
+	}
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.file = nil
+
+	return err
+}
+
+// Close closes the current file. Messages recorded afterwards are rejected.
+func (r *StreamRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	return r.closeFile()
+}
+
+// Middleware returns a Middleware that records the messages of the streaming
+// endpoints, whose path ends with "/stream", as they are read.
+//
+//	c.Use(rec.Middleware())
+//	s, err := filteredstream.SearchStream(ctx, c, p)
+func (r *StreamRecorder) Middleware() Middleware {
+	return func(next ExecFunc) ExecFunc {
+		return func(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
+			res, non200err, err := next(req)
+			if err != nil || non200err != nil || res == nil || res.Body == nil {
+				return res, non200err, err
+			}
+
+			if !strings.HasSuffix(strings.TrimRight(req.URL.Path, "/"), "/stream") {
+				return res, non200err, err
+			}
+
+			res.Body = &recordingBody{ReadCloser: res.Body, recorder: r}
+			return res, non200err, err
+		}
+	}
+}
+
+// recordingBody passes every complete line read from the body to the recorder.
+type recordingBody struct {
+	io.ReadCloser
+	recorder *StreamRecorder
+	pending  []byte
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.pending = append(b.pending, p[:n]...)
+
+	for {
+		i := bytes.IndexByte(b.pending, '\n')
+		if i < 0 {
+			break
+		}
+		b.record(b.pending[:i])
+		b.pending = b.pending[i+1:]
+	}
+
+	if err == io.EOF && len(b.pending) > 0 {
+		b.record(b.pending)
+		b.pending = nil
+	}
+
+	return n, err
+}
+
+func (b *recordingBody) record(line []byte) {
+	if err := b.recorder.Record(line); err != nil && b.recorder.onError != nil {
+		b.recorder.onError(err)
+	}
+}
@@ -0,0 +1,242 @@
+package gotwi_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock returns now and advances by step on each call.
+func fakeClock(now time.Time, step time.Duration) gotwi.Clock {
+	return gotwi.ClockFunc(func() time.Time {
+		t := now
+		now = now.Add(step)
+		return t
+	})
+}
+
+func readRecords(t *testing.T, path string) []gotwi.StreamRecord {
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		assert.NoError(t, err)
+		r = gz
+	}
+
+	records := []gotwi.StreamRecord{}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		rec := gotwi.StreamRecord{}
+		assert.NoError(t, json.Unmarshal(s.Bytes(), &rec))
+		records = append(records, rec)
+	}
+	return records
+}
+
+func recordedFiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	files := []string{}
+	for _, e := range entries {
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files
+}
+
+func Test_NewStreamRecorder(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      *gotwi.NewStreamRecorderInput
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			in:   &gotwi.NewStreamRecorderInput{Dir: filepath.Join(t.TempDir(), "capture")},
+		},
+		{
+			name:    "error: dir is empty",
+			in:      &gotwi.NewStreamRecorderInput{},
+			wantErr: true,
+		},
+		{
+			name:    "error: nil",
+			in:      nil,
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			r, err := gotwi.NewStreamRecorder(c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(r)
+				return
+			}
+
+			asst.NoError(err)
+			asst.NotNil(r)
+			asst.DirExists(c.in.Dir)
+		})
+	}
+}
+
+func Test_StreamRecorder_Rotation(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	message := `{"data":{"id":"1","text":"hello"}}`
+
+	cases := []struct {
+		name        string
+		in          gotwi.NewStreamRecorderInput
+		expectFiles []int
+	}{
+		{
+			name:        "no rotation",
+			in:          gotwi.NewStreamRecorderInput{},
+			expectFiles: []int{5},
+		},
+		{
+			name:        "size based",
+			in:          gotwi.NewStreamRecorderInput{MaxBytes: 150},
+			expectFiles: []int{2, 2, 1},
+		},
+		{
+			name:        "time based",
+			in:          gotwi.NewStreamRecorderInput{MaxAge: time.Duration(3) * time.Second},
+			expectFiles: []int{3, 2},
+		},
+		{
+			name:        "gzip",
+			in:          gotwi.NewStreamRecorderInput{MaxBytes: 150, Gzip: true},
+			expectFiles: []int{2, 2, 1},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			in := c.in
+			in.Dir = tt.TempDir()
+			// each record is one second after the previous one
+			in.Clock = fakeClock(start, time.Second)
+			r, err := gotwi.NewStreamRecorder(&in)
+			asst.NoError(err)
+
+			for i := 0; i < 5; i++ {
+				asst.NoError(r.Record([]byte(message)))
+			}
+			asst.NoError(r.Record([]byte("\r\n")))
+			asst.NoError(r.Close())
+			asst.Error(r.Record([]byte(message)))
+
+			files := recordedFiles(tt, in.Dir)
+			counts := []int{}
+			for _, f := range files {
+				if c.in.Gzip {
+					asst.True(strings.HasSuffix(f, ".jsonl.gz"), f)
+				} else {
+					asst.True(strings.HasSuffix(f, ".jsonl"), f)
+				}
+
+				records := readRecords(tt, f)
+				for _, rec := range records {
+					asst.JSONEq(message, string(rec.Message))
+					asst.False(rec.ReceivedAt.IsZero())
+				}
+				counts = append(counts, len(records))
+			}
+			asst.Equal(c.expectFiles, counts)
+		})
+	}
+}
+
+func Test_StreamRecorder_Middleware(t *testing.T) {
+	asst := assert.New(t)
+
+	dir := t.TempDir()
+	rec, err := gotwi.NewStreamRecorder(&gotwi.NewStreamRecorderInput{Dir: dir})
+	asst.NoError(err)
+
+	body := `{"data":{"id":"1","text":"a"}}` + "\r\n\r\n" + `{"data":{"id":"2","text":"b"}}` + "\r\n"
+	client, _ := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			if strings.HasSuffix(req.URL.Path, "/rules") {
+				return newMockResponse(http.StatusOK, `{"data":[{"id":"10","value":"gotwi"}]}`)
+			}
+			return newMockResponse(http.StatusOK, body)
+		}),
+		AccessToken: "token",
+		Middlewares: []gotwi.Middleware{rec.Middleware()},
+	})
+
+	_, err = filteredstream.ListRules(context.Background(), client, &types.ListRulesInput{})
+	asst.NoError(err)
+
+	s, err := filteredstream.SearchStream(context.Background(), client, &types.SearchStreamInput{})
+	asst.NoError(err)
+	ids := []string{}
+	for out := range s.Messages(context.Background()) {
+		ids = append(ids, gotwi.StringValue(out.Data.ID))
+	}
+	asst.NoError(s.Err())
+	asst.Equal([]string{"1", "2"}, ids)
+	asst.NoError(rec.Close())
+
+	files := recordedFiles(t, dir)
+	asst.Len(files, 1)
+	records := readRecords(t, files[0])
+	asst.Len(records, 2)
+	asst.JSONEq(`{"data":{"id":"1","text":"a"}}`, string(records[0].Message))
+	asst.JSONEq(`{"data":{"id":"2","text":"b"}}`, string(records[1].Message))
+}
+
+func Test_StreamRecorder_NameCollision(t *testing.T) {
+	asst := assert.New(t)
+
+	// two recorders write to the same directory in the same second
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	recorders := []*gotwi.StreamRecorder{}
+	for i := 0; i < 2; i++ {
+		r, err := gotwi.NewStreamRecorder(&gotwi.NewStreamRecorderInput{Dir: dir, Clock: fakeClock(start, 0)})
+		asst.NoError(err)
+		recorders = append(recorders, r)
+	}
+
+	for _, r := range recorders {
+		asst.NoError(r.Record([]byte(`{"data":{"id":"1","text":"hello"}}`)))
+		asst.NoError(r.Close())
+	}
+
+	files := recordedFiles(t, dir)
+	asst.Equal([]string{
+		filepath.Join(dir, "stream-20240102T030405Z-000001.jsonl"),
+		filepath.Join(dir, "stream-20240102T030405Z-000002.jsonl"),
+	}, files)
+	for _, f := range files {
+		asst.Len(readRecords(t, f), 1)
+	}
+}
@@ -0,0 +1,185 @@
+package gotwi
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/xxiiaaon/gotwi/internal/util"
+)
+
+type ReplayStreamOption struct {
+	// OriginalTiming waits between messages as long as between their ReceivedAt.
+	// Otherwise the messages are replayed as fast as they are read.
+	OriginalTiming bool
+
+	// MaxMessageSize is the size in bytes of the largest line. Default is DefaultMaxStreamMessageSize.
+	MaxMessageSize int
+}
+
+// NewReplayStreamClient returns a StreamClient that reads the messages in r instead of a
+// live stream, so that captured traffic goes through the same code as the live stream.
+// r is either the JSONL written by StreamRecorder or raw stream lines; gzip is detected.
+// Stop ends the replay, and a read failure of r is returned by Err.
+func NewReplayStreamClient[T util.Response](r io.Reader, opt *ReplayStreamOption) (*StreamClient[T], error) {
+	if r == nil {
+		return nil, errors.New("Reader is nil.")
+	}
+	if opt == nil {
+		opt = &ReplayStreamOption{}
+	}
+
+	pr, pw := io.Pipe()
+	body := &replayBody{PipeReader: pr, done: make(chan struct{})}
+	go replay(r, pw, body.done, opt)
+
+	return newStreamClient[T](&http.Response{
+		Status:     http.StatusText(http.StatusOK),
+		StatusCode: http.StatusOK,
+		Body:       body,
+	}, opt.MaxMessageSize)
+}
+
+// OpenStreamCapture opens the files written by StreamRecorder, in the given order, as one reader
+// for NewReplayStreamClient. Gzip files are decompressed.
+func OpenStreamCapture(paths ...string) (io.ReadCloser, error) {
+	files := make([]*os.File, 0, len(paths))
+	readers := make([]io.Reader, 0, len(paths))
+	closeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		files = append(files, f)
+
+		r, err := decompress(f)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		readers = append(readers, r)
+	}
+
+	return &streamCapture{Reader: io.MultiReader(readers...), files: files}, nil
+}
+
+type streamCapture struct {
+	io.Reader
+	files []*os.File
+}
+
+func (c *streamCapture) Close() error {
+	var err error
+	for _, f := range c.files {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// replayBody ends the replay when the StreamClient is stopped.
+type replayBody struct {
+	*io.PipeReader
+	done chan struct{}
+	once sync.Once
+}
+
+func (b *replayBody) Close() error {
+	b.once.Do(func() { close(b.done) })
+	return b.PipeReader.Close()
+}
+
+// replay writes the messages in r to pw as stream lines.
+func replay(r io.Reader, pw *io.PipeWriter, done <-chan struct{}, opt *ReplayStreamOption) {
+	src, err := decompress(r)
+	if err != nil {
+		pw.CloseWithError(err)
+		return
+	}
+
+	max := opt.MaxMessageSize
+	if max <= 0 {
+		max = DefaultMaxStreamMessageSize
+	}
+	// a record is a little larger than its message
+	max += 1024
+
+	s := bufio.NewScanner(src)
+	s.Buffer(make([]byte, 0, min(initialStreamBufferSize, max)), max)
+
+	var prev time.Time
+	for s.Scan() {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		message, receivedAt := decodeStreamRecord(line)
+		if opt.OriginalTiming && !receivedAt.IsZero() {
+			if !prev.IsZero() && receivedAt.After(prev) {
+				t := time.NewTimer(receivedAt.Sub(prev))
+				select {
+				case <-done:
+					t.Stop()
+					pw.Close()
+					return
+				case <-t.C:
+				}
+			}
+			prev = receivedAt
+		}
+
+		// message points into the buffer of the scanner, so it is copied before appending
+		out := make([]byte, 0, len(message)+2)
+		out = append(append(out, message...), '\r', '\n')
+		if _, err := pw.Write(out); err != nil {
+			// the StreamClient was stopped
+			return
+		}
+	}
+
+	pw.CloseWithError(s.Err())
+}
+
+// decodeStreamRecord returns the message of a StreamRecord, or line itself if it is a raw message.
+func decodeStreamRecord(line []byte) ([]byte, time.Time) {
+	if !bytes.Contains(line, []byte(`"received_at"`)) {
+		return line, time.Time{}
+	}
+
+	rec := StreamRecord{}
+	if err := json.Unmarshal(line, &rec); err != nil || len(rec.Message) == 0 || rec.ReceivedAt.IsZero() {
+		return line, time.Time{}
+	}
+
+	return rec.Message, rec.ReceivedAt
+}
+
+// decompress returns a reader of the content of r, which may be gzip compressed.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
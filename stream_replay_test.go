@@ -0,0 +1,188 @@
+package gotwi_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewReplayStreamClient(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(`{"text":"1"}` + "\n" + `{"text":"2"}` + "\n"))
+	w.Close()
+
+	cases := []struct {
+		name   string
+		r      io.Reader
+		expect []string
+	}{
+		{
+			name:   "raw stream lines",
+			r:      strings.NewReader(`{"text":"1"}` + "\r\n\r\n" + `{"text":"2"}` + "\r\n"),
+			expect: []string{"1", "2"},
+		},
+		{
+			name: "records",
+			r: strings.NewReader(`{"received_at":"2024-01-02T03:04:05Z","message":{"text":"1"}}` + "\n" +
+				`{"received_at":"2024-01-02T03:04:06Z","message":{"text":"2"}}` + "\n"),
+			expect: []string{"1", "2"},
+		},
+		{
+			name:   "gzip",
+			r:      &gz,
+			expect: []string{"1", "2"},
+		},
+		{
+			name:   "empty",
+			r:      strings.NewReader(""),
+			expect: []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			s, err := gotwi.NewReplayStreamClient[*gotwi.MockResponse](c.r, nil)
+			asst.NoError(err)
+
+			received := []string{}
+			err = s.Run(context.Background(), func(m *gotwi.MockResponse) error {
+				received = append(received, m.Text)
+				return nil
+			})
+			asst.NoError(err)
+			asst.Equal(c.expect, received)
+		})
+	}
+}
+
+func Test_NewReplayStreamClient_OriginalTiming(t *testing.T) {
+	records := `{"received_at":"2024-01-02T03:04:05.000Z","message":{"text":"1"}}` + "\n" +
+		`{"received_at":"2024-01-02T03:04:05.100Z","message":{"text":"2"}}` + "\n"
+
+	cases := []struct {
+		name           string
+		originalTiming bool
+		min            time.Duration
+		max            time.Duration
+	}{
+		{
+			name:           "original timing",
+			originalTiming: true,
+			min:            time.Duration(100) * time.Millisecond,
+			max:            time.Second,
+		},
+		{
+			name:           "as fast as possible",
+			originalTiming: false,
+			min:            0,
+			max:            time.Duration(90) * time.Millisecond,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			s, err := gotwi.NewReplayStreamClient[*gotwi.MockResponse](strings.NewReader(records), &gotwi.ReplayStreamOption{OriginalTiming: c.originalTiming})
+			asst.NoError(err)
+
+			start := time.Now()
+			n := 0
+			for range s.Messages(context.Background()) {
+				n++
+			}
+			elapsed := time.Since(start)
+
+			asst.Equal(2, n)
+			asst.GreaterOrEqual(elapsed, c.min)
+			asst.Less(elapsed, c.max)
+		})
+	}
+}
+
+func Test_NewReplayStreamClient_Stop(t *testing.T) {
+	asst := assert.New(t)
+
+	records := `{"received_at":"2024-01-02T03:04:05Z","message":{"text":"1"}}` + "\n" +
+		`{"received_at":"2024-01-02T04:04:05Z","message":{"text":"2"}}` + "\n"
+	s, err := gotwi.NewReplayStreamClient[*gotwi.MockResponse](strings.NewReader(records), &gotwi.ReplayStreamOption{OriginalTiming: true})
+	asst.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(50)*time.Millisecond)
+	defer cancel()
+
+	received := []string{}
+	err = s.Run(ctx, func(m *gotwi.MockResponse) error {
+		received = append(received, m.Text)
+		return nil
+	})
+	asst.ErrorIs(err, context.DeadlineExceeded)
+	asst.Equal([]string{"1"}, received)
+}
+
+func Test_NewReplayStreamClient_ReadError(t *testing.T) {
+	asst := assert.New(t)
+
+	readErr := errors.New("read error")
+	s, err := gotwi.NewReplayStreamClient[*gotwi.MockResponse](&errReader{data: `{"text":"1"}` + "\n", err: readErr}, nil)
+	asst.NoError(err)
+
+	n := 0
+	for range s.Messages(context.Background()) {
+		n++
+	}
+	asst.Equal(1, n)
+	asst.Equal(readErr, s.Err())
+
+	_, err = gotwi.NewReplayStreamClient[*gotwi.MockResponse](nil, nil)
+	asst.Error(err)
+}
+
+func Test_RecordAndReplay(t *testing.T) {
+	asst := assert.New(t)
+
+	dir := t.TempDir()
+	rec, err := gotwi.NewStreamRecorder(&gotwi.NewStreamRecorderInput{
+		Dir:      dir,
+		MaxBytes: 100,
+		Gzip:     true,
+	})
+	asst.NoError(err)
+	for _, text := range []string{"1", "2", "3"} {
+		asst.NoError(rec.Record([]byte(`{"text":"` + text + `"}`)))
+	}
+	asst.NoError(rec.Close())
+
+	files := recordedFiles(t, dir)
+	asst.Len(files, 2)
+
+	r, err := gotwi.OpenStreamCapture(files...)
+	asst.NoError(err)
+	defer r.Close()
+
+	s, err := gotwi.NewReplayStreamClient[*gotwi.MockResponse](r, nil)
+	asst.NoError(err)
+
+	received := []string{}
+	for m := range s.Messages(context.Background()) {
+		received = append(received, m.Text)
+	}
+	asst.NoError(s.Err())
+	asst.Equal([]string{"1", "2", "3"}, received)
+
+	_, err = gotwi.OpenStreamCapture(filepath.Join(dir, "missing.jsonl"))
+	asst.ErrorIs(err, os.ErrNotExist)
+}
@@ -0,0 +1,433 @@
+package gotwi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/xxiiaaon/gotwi/internal/util"
+)
+
+const (
+	defaultStreamStallTimeout = time.Duration(20) * time.Second
+	defaultStreamDedupeSize   = 10000
+
+	defaultStreamNetworkStep      = time.Duration(250) * time.Millisecond
+	defaultStreamNetworkMax       = time.Duration(16) * time.Second
+	defaultStreamHTTPInitial      = time.Duration(5) * time.Second
+	defaultStreamHTTPMax          = time.Duration(320) * time.Second
+	defaultStreamRateLimitInitial = time.Duration(1) * time.Minute
+	defaultStreamRateLimitMax     = time.Duration(15) * time.Minute
+
+	// maxStreamBackfillMinutes is the longest backfill the streaming endpoints accept.
+	maxStreamBackfillMinutes = 5
+)
+
+// ErrStreamStalled is the cause of a disconnection when neither data nor a
+// keep-alive arrived within StreamRunnerOption.StallTimeout.
+var ErrStreamStalled = errors.New("Stream stalled.")
+
+// StreamBackoff configures how long StreamRunner waits between failed connection attempts.
+// The defaults follow the reconnection guidelines of the streaming endpoints.
+type StreamBackoff struct {
+	// NetworkStep is added to the wait after each network error. Default is 250 milliseconds.
+	NetworkStep time.Duration
+
+	// NetworkMax caps the wait for network errors. Default is 16 seconds.
+	NetworkMax time.Duration
+
+	// HTTPInitial is the first wait for 5XX errors, doubled on each attempt. Default is 5 seconds.
+	HTTPInitial time.Duration
+
+	// HTTPMax caps the wait for 5XX errors. Default is 320 seconds.
+	HTTPMax time.Duration
+
+	// RateLimitInitial is the first wait for 429 errors, doubled on each attempt. Default is 1 minute.
+	RateLimitInitial time.Duration
+
+	// RateLimitMax caps the wait for 429 errors. Default is 15 minutes, the rate limit window.
+	RateLimitMax time.Duration
+}
+
+type StreamRunnerOption struct {
+	// StallTimeout is how long to wait for data or a keep-alive before reconnecting.
+	// Default is 20 seconds.
+	StallTimeout time.Duration
+
+	// Backoff overrides the waits between failed connection attempts.
+	Backoff *StreamBackoff
+
+	// Backfill requests the messages missed while disconnected, up to 5 minutes,
+	// when reconnecting. Duplicates are dropped by ID.
+	Backfill bool
+
+	// DedupeSize is how many recent IDs are remembered to drop duplicates. Default is 10000.
+	DedupeSize int
+
+	// OnEvent is called synchronously for every connection lifecycle event.
+	OnEvent func(StreamEvent)
+}
+
+type StreamEventType string
+
+const (
+	StreamConnecting   StreamEventType = "connecting"
+	StreamConnected    StreamEventType = "connected"
+	StreamDisconnected StreamEventType = "disconnected"
+	StreamHeartbeat    StreamEventType = "heartbeat"
+	StreamBackingOff   StreamEventType = "backing-off"
+	StreamDecodeFailed StreamEventType = "decode-failed"
+	StreamStopped      StreamEventType = "stopped"
+)
+
+// StreamEvent is a connection lifecycle event reported to StreamRunnerOption.OnEvent.
+type StreamEvent struct {
+	Type StreamEventType
+
+	// Attempt is the number of the connection attempt, starting from 1.
+	Attempt int
+
+	// BackfillMinutes is requested by a StreamConnecting event, if any.
+	BackfillMinutes int
+
+	// Backoff is the wait of a StreamBackingOff event.
+	Backoff time.Duration
+
+	// Err is the cause of StreamDisconnected, StreamBackingOff, StreamDecodeFailed and
+	// StreamStopped events. It is nil when the server closed the stream normally, and
+	// a *StreamError when the server sent an in-band error such as an operational disconnect.
+	Err error
+}
+
+// StreamConnectFunc opens a stream, requesting backfillMinutes of missed messages if it is not 0.
+type StreamConnectFunc[T util.Response] func(ctx context.Context, backfillMinutes int) (*StreamClient[T], error)
+
+// StreamRunner keeps a stream connected: it reconnects when the stream ends, fails or
+// stalls, and drops the messages already delivered. Use the constructors of the
+// streaming packages, e.g. filteredstream.NewSearchStreamRunner.
+type StreamRunner[T util.Response] struct {
+	connect StreamConnectFunc[T]
+	id      func(T) string
+	opt     StreamRunnerOption
+	backoff StreamBackoff
+}
+
+// NewStreamRunner returns a StreamRunner that opens streams with connect. id returns
+// the ID used to drop duplicates, or an empty string for messages without an ID.
+// id may be nil.
+func NewStreamRunner[T util.Response](connect StreamConnectFunc[T], id func(T) string, opt *StreamRunnerOption) *StreamRunner[T] {
+	r := &StreamRunner[T]{
+		connect: connect,
+		id:      id,
+	}
+	if opt != nil {
+		r.opt = *opt
+	}
+	if r.opt.StallTimeout <= 0 {
+		r.opt.StallTimeout = defaultStreamStallTimeout
+	}
+	if r.opt.DedupeSize <= 0 {
+		r.opt.DedupeSize = defaultStreamDedupeSize
+	}
+
+	r.backoff = StreamBackoff{
+		NetworkStep:      defaultStreamNetworkStep,
+		NetworkMax:       defaultStreamNetworkMax,
+		HTTPInitial:      defaultStreamHTTPInitial,
+		HTTPMax:          defaultStreamHTTPMax,
+		RateLimitInitial: defaultStreamRateLimitInitial,
+		RateLimitMax:     defaultStreamRateLimitMax,
+	}
+	if b := r.opt.Backoff; b != nil {
+		if b.NetworkStep > 0 {
+			r.backoff.NetworkStep = b.NetworkStep
+		}
+		if b.NetworkMax > 0 {
+			r.backoff.NetworkMax = b.NetworkMax
+		}
+		if b.HTTPInitial > 0 {
+			r.backoff.HTTPInitial = b.HTTPInitial
+		}
+		if b.HTTPMax > 0 {
+			r.backoff.HTTPMax = b.HTTPMax
+		}
+		if b.RateLimitInitial > 0 {
+			r.backoff.RateLimitInitial = b.RateLimitInitial
+		}
+		if b.RateLimitMax > 0 {
+			r.backoff.RateLimitMax = b.RateLimitMax
+		}
+	}
+
+	return r
+}
+
+// Run connects and calls handle for every message until ctx is done, handle returns
+// an error, or a connection attempt fails with an error that cannot be retried,
+// such as 401 or 403. The error that stopped the runner is returned.
+func (r *StreamRunner[T]) Run(ctx context.Context, handle func(T) error) error {
+	if r == nil || r.connect == nil {
+		return errors.New("StreamRunner is not initialized.")
+	}
+
+	var (
+		attempt     int
+		failures    streamFailures
+		lastMessage time.Time
+	)
+	seen := newRecentIDs(r.opt.DedupeSize)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return r.stop(attempt, err)
+		}
+		attempt++
+
+		backfill := 0
+		if r.opt.Backfill && !lastMessage.IsZero() {
+			backfill = backfillMinutes(time.Since(lastMessage))
+		}
+
+		r.emit(StreamEvent{Type: StreamConnecting, Attempt: attempt, BackfillMinutes: backfill})
+		s, err := r.connect(ctx, backfill)
+		if err != nil {
+			if ctx.Err() != nil {
+				return r.stop(attempt, ctx.Err())
+			}
+
+			d, ok := failures.next(&r.backoff, err)
+			if !ok {
+				return r.stop(attempt, err)
+			}
+			if err := r.wait(ctx, attempt, d, err); err != nil {
+				return r.stop(attempt, err)
+			}
+			continue
+		}
+		r.emit(StreamEvent{Type: StreamConnected, Attempt: attempt})
+
+		received, err := r.consume(ctx, s, seen, &lastMessage, handle)
+		var he *streamHandlerError
+		if errors.As(err, &he) {
+			return r.stop(attempt, he.err)
+		}
+		if ctx.Err() != nil {
+			return r.stop(attempt, ctx.Err())
+		}
+		r.emit(StreamEvent{Type: StreamDisconnected, Attempt: attempt, Err: err})
+
+		var se *StreamError
+		if errors.As(err, &se) {
+			// the server refused or closed the connection, which is retried with backoff
+			// even if messages arrived before, not to reconnect in a tight loop
+			if received {
+				failures = streamFailures{}
+			}
+			d, _ := failures.next(&r.backoff, se)
+			if err := r.wait(ctx, attempt, d, se); err != nil {
+				return r.stop(attempt, err)
+			}
+			continue
+		}
+
+		if received {
+			// reconnect immediately after an established connection drops
+			failures = streamFailures{}
+			continue
+		}
+
+		// the connection ended before anything arrived, so it is treated as a network error
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		if err := r.wait(ctx, attempt, failures.networkBackoff(&r.backoff), err); err != nil {
+			return r.stop(attempt, err)
+		}
+	}
+}
+
+// consume reads s until it ends. received reports whether any message or keep-alive arrived,
+// not counting an in-band error.
+func (r *StreamRunner[T]) consume(ctx context.Context, s *StreamClient[T], seen *recentIDs, lastMessage *time.Time, handle func(T) error) (received bool, err error) {
+	var stalled atomic.Bool
+	watchdog := time.AfterFunc(r.opt.StallTimeout, func() {
+		stalled.Store(true)
+		s.Stop()
+	})
+	defer watchdog.Stop()
+
+	stopOnDone := context.AfterFunc(ctx, s.Stop)
+	defer stopOnDone()
+	defer s.Stop()
+
+	s.SetHeartbeatHandler(func() {
+		watchdog.Reset(r.opt.StallTimeout)
+		received = true
+		r.emit(StreamEvent{Type: StreamHeartbeat})
+	})
+
+	for s.Receive() {
+		watchdog.Reset(r.opt.StallTimeout)
+
+		msg, err := s.Read()
+		var se *StreamError
+		if errors.As(err, &se) {
+			// the server is about to close the connection
+			return received, se
+		}
+		received = true
+		if err != nil {
+			r.emit(StreamEvent{Type: StreamDecodeFailed, Err: err})
+			continue
+		}
+		*lastMessage = time.Now()
+
+		if r.id != nil {
+			if id := r.id(msg); id != "" && !seen.add(id) {
+				continue
+			}
+		}
+
+		if err := handle(msg); err != nil {
+			return received, &streamHandlerError{err: err}
+		}
+	}
+
+	if stalled.Load() {
+		return received, ErrStreamStalled
+	}
+	return received, s.Err()
+}
+
+func (r *StreamRunner[T]) wait(ctx context.Context, attempt int, d time.Duration, cause error) error {
+	r.emit(StreamEvent{Type: StreamBackingOff, Attempt: attempt, Backoff: d, Err: cause})
+	return sleepContext(ctx, d)
+}
+
+func (r *StreamRunner[T]) stop(attempt int, err error) error {
+	r.emit(StreamEvent{Type: StreamStopped, Attempt: attempt, Err: err})
+	return err
+}
+
+func (r *StreamRunner[T]) emit(e StreamEvent) {
+	if r.opt.OnEvent != nil {
+		r.opt.OnEvent(e)
+	}
+}
+
+type streamHandlerError struct {
+	err error
+}
+
+func (e *streamHandlerError) Error() string { return e.err.Error() }
+
+// streamFailures counts the consecutive failed connection attempts per kind of error.
+type streamFailures struct {
+	network   int
+	http      int
+	rateLimit int
+}
+
+// next returns the wait before the next attempt after err, and false if err cannot be retried.
+// In-band errors are always retried: a ConnectionException, e.g. too many connections,
+// backs off like 429 errors, and the other ones, e.g. an operational disconnect, like 5XX errors.
+func (f *streamFailures) next(b *StreamBackoff, err error) (time.Duration, bool) {
+	var se *StreamError
+	if errors.As(err, &se) {
+		if se.ConnectionException() {
+			f.rateLimit++
+			return exponentialBackoff(b.RateLimitInitial, b.RateLimitMax, f.rateLimit), true
+		}
+		f.http++
+		return exponentialBackoff(b.HTTPInitial, b.HTTPMax, f.http), true
+	}
+
+	var ge *GotwiError
+	if errors.As(err, &ge) && ge.OnAPI {
+		switch {
+		case ge.StatusCode == http.StatusTooManyRequests || ge.StatusCode == 420:
+			f.rateLimit++
+			return exponentialBackoff(b.RateLimitInitial, b.RateLimitMax, f.rateLimit), true
+		case ge.StatusCode >= http.StatusInternalServerError:
+			f.http++
+			return exponentialBackoff(b.HTTPInitial, b.HTTPMax, f.http), true
+		default:
+			return 0, false
+		}
+	}
+
+	var ne net.Error
+	if errors.As(err, &ne) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return f.networkBackoff(b), true
+	}
+
+	return 0, false
+}
+
+// networkBackoff returns the linear wait after a network error.
+func (f *streamFailures) networkBackoff(b *StreamBackoff) time.Duration {
+	f.network++
+	d := time.Duration(f.network) * b.NetworkStep
+	if d > b.NetworkMax {
+		d = b.NetworkMax
+	}
+	return d
+}
+
+// exponentialBackoff returns initial doubled for each failure after the first, capped at max unless it is 0.
+func exponentialBackoff(initial, max time.Duration, failures int) time.Duration {
+	d := initial
+	for i := 1; i < failures; i++ {
+		d *= 2
+		if max > 0 && d >= max {
+			return max
+		}
+	}
+	return d
+}
+
+// backfillMinutes returns the minutes to backfill for a disconnection of d.
+func backfillMinutes(d time.Duration) int {
+	m := int((d + time.Minute - 1) / time.Minute)
+	if m < 1 {
+		return 1
+	}
+	if m > maxStreamBackfillMinutes {
+		return maxStreamBackfillMinutes
+	}
+	return m
+}
+
+// recentIDs remembers the last size IDs.
+type recentIDs struct {
+	ring []string
+	next int
+	set  map[string]struct{}
+}
+
+func newRecentIDs(size int) *recentIDs {
+	return &recentIDs{
+		ring: make([]string, size),
+		set:  make(map[string]struct{}, size),
+	}
+}
+
+// add returns false if id was already seen.
+func (r *recentIDs) add(id string) bool {
+	if _, ok := r.set[id]; ok {
+		return false
+	}
+
+	if old := r.ring[r.next]; old != "" {
+		delete(r.set, old)
+	}
+	r.ring[r.next] = id
+	r.next = (r.next + 1) % len(r.ring)
+	r.set[id] = struct{}{}
+
+	return true
+}
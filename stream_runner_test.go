@@ -0,0 +1,341 @@
+package gotwi_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/resources"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream/types"
+	"github.com/xxiiaaon/gotwi/tweet/volumestream"
+	volumestreamtypes "github.com/xxiiaaon/gotwi/tweet/volumestream/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// connectResult is the result of one connection attempt of a StreamRunner in tests.
+type connectResult struct {
+	body io.ReadCloser
+	err  error
+}
+
+func streamBody(lines ...string) io.ReadCloser {
+	return io.NopCloser(strings.NewReader(strings.Join(lines, "\r\n") + "\r\n"))
+}
+
+func newTestStreamRunner(results []connectResult, backfills *[]int, opt *gotwi.StreamRunnerOption) *gotwi.StreamRunner[*gotwi.MockResponse] {
+	connect := func(ctx context.Context, backfillMinutes int) (*gotwi.StreamClient[*gotwi.MockResponse], error) {
+		n := len(*backfills)
+		*backfills = append(*backfills, backfillMinutes)
+		if n >= len(results) {
+			return nil, gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: http.StatusForbidden})
+		}
+
+		r := results[n]
+		if r.err != nil {
+			return nil, r.err
+		}
+		return gotwi.ExportNewStreamClient(&http.Response{Body: r.body})
+	}
+
+	id := func(m *gotwi.MockResponse) string {
+		return m.Text
+	}
+
+	return gotwi.NewStreamRunner(connect, id, opt)
+}
+
+func eventTypes(events []gotwi.StreamEvent) []gotwi.StreamEventType {
+	types := []gotwi.StreamEventType{}
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func Test_StreamRunner_Reconnect(t *testing.T) {
+	asst := assert.New(t)
+
+	events := []gotwi.StreamEvent{}
+	backfills := []int{}
+	r := newTestStreamRunner([]connectResult{
+		{body: streamBody(`{"text":"1"}`, ``, `{"text":"2"}`)},
+		{body: streamBody(`{"text":"2"}`, `{"text":"3"}`)},
+	}, &backfills, &gotwi.StreamRunnerOption{
+		Backfill: true,
+		OnEvent:  func(e gotwi.StreamEvent) { events = append(events, e) },
+	})
+
+	received := []string{}
+	err := r.Run(context.Background(), func(m *gotwi.MockResponse) error {
+		received = append(received, m.Text)
+		return nil
+	})
+
+	asst.ErrorIs(err, gotwi.ErrForbidden)
+	asst.Equal([]string{"1", "2", "3"}, received)
+	asst.Equal([]int{0, 1, 1}, backfills)
+	asst.Equal([]gotwi.StreamEventType{
+		gotwi.StreamConnecting, gotwi.StreamConnected, gotwi.StreamHeartbeat, gotwi.StreamDisconnected,
+		gotwi.StreamConnecting, gotwi.StreamConnected, gotwi.StreamDisconnected,
+		gotwi.StreamConnecting, gotwi.StreamStopped,
+	}, eventTypes(events))
+	asst.Equal(3, events[len(events)-1].Attempt)
+}
+
+func Test_StreamRunner_Backoff(t *testing.T) {
+	networkErr := gotwi.ExportWrapErr(&url.Error{Op: "Get", URL: "https://api.twitter.com/2/tweets/search/stream", Err: errors.New("connection refused")})
+	httpErr := gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: http.StatusServiceUnavailable})
+	rateLimitErr := gotwi.ExportWrapWithAPIErr(&resources.Non2XXError{StatusCode: http.StatusTooManyRequests})
+
+	backoff := &gotwi.StreamBackoff{
+		NetworkStep:      time.Millisecond,
+		NetworkMax:       time.Duration(2) * time.Millisecond,
+		HTTPInitial:      time.Duration(2) * time.Millisecond,
+		HTTPMax:          time.Duration(5) * time.Millisecond,
+		RateLimitInitial: time.Duration(3) * time.Millisecond,
+		RateLimitMax:     time.Duration(10) * time.Millisecond,
+	}
+
+	cases := []struct {
+		name    string
+		results []connectResult
+		expect  []time.Duration
+	}{
+		{
+			name:    "network errors back off linearly",
+			results: []connectResult{{err: networkErr}, {err: networkErr}, {err: networkErr}},
+			expect:  []time.Duration{time.Millisecond, time.Duration(2) * time.Millisecond, time.Duration(2) * time.Millisecond},
+		},
+		{
+			name:    "http errors back off exponentially",
+			results: []connectResult{{err: httpErr}, {err: httpErr}, {err: httpErr}},
+			expect:  []time.Duration{time.Duration(2) * time.Millisecond, time.Duration(4) * time.Millisecond, time.Duration(5) * time.Millisecond},
+		},
+		{
+			name:    "rate limit errors back off exponentially",
+			results: []connectResult{{err: rateLimitErr}, {err: rateLimitErr}, {err: rateLimitErr}},
+			expect:  []time.Duration{time.Duration(3) * time.Millisecond, time.Duration(6) * time.Millisecond, time.Duration(10) * time.Millisecond},
+		},
+		{
+			name:    "empty stream is a network error",
+			results: []connectResult{{body: io.NopCloser(strings.NewReader(""))}, {body: io.NopCloser(strings.NewReader(""))}},
+			expect:  []time.Duration{time.Millisecond, time.Duration(2) * time.Millisecond},
+		},
+		{
+			name:    "counters are reset after data arrived",
+			results: []connectResult{{err: httpErr}, {err: httpErr}, {body: streamBody(`{"text":"1"}`)}, {err: httpErr}},
+			expect:  []time.Duration{time.Duration(2) * time.Millisecond, time.Duration(4) * time.Millisecond, time.Duration(2) * time.Millisecond},
+		},
+		{
+			name:    "other errors are not retried",
+			results: []connectResult{{err: errors.New("parameters is nil")}},
+			expect:  []time.Duration{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			waits := []time.Duration{}
+			backfills := []int{}
+			r := newTestStreamRunner(c.results, &backfills, &gotwi.StreamRunnerOption{
+				Backoff: backoff,
+				OnEvent: func(e gotwi.StreamEvent) {
+					if e.Type == gotwi.StreamBackingOff {
+						waits = append(waits, e.Backoff)
+					}
+				},
+			})
+
+			err := r.Run(context.Background(), func(*gotwi.MockResponse) error { return nil })
+			asst.Error(err)
+			asst.Equal(c.expect, waits)
+		})
+	}
+}
+
+func Test_StreamRunner_Stall(t *testing.T) {
+	asst := assert.New(t)
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() {
+		pw.Write([]byte(`{"text":"1"}` + "\r\n"))
+	}()
+
+	events := []gotwi.StreamEvent{}
+	backfills := []int{}
+	r := newTestStreamRunner([]connectResult{{body: pr}}, &backfills, &gotwi.StreamRunnerOption{
+		StallTimeout: time.Duration(50) * time.Millisecond,
+		OnEvent:      func(e gotwi.StreamEvent) { events = append(events, e) },
+	})
+
+	received := 0
+	err := r.Run(context.Background(), func(*gotwi.MockResponse) error {
+		received++
+		return nil
+	})
+
+	asst.ErrorIs(err, gotwi.ErrForbidden)
+	asst.Equal(1, received)
+	asst.Equal(gotwi.StreamDisconnected, events[2].Type)
+	asst.ErrorIs(events[2].Err, gotwi.ErrStreamStalled)
+}
+
+func Test_StreamRunner_Stop(t *testing.T) {
+	t.Run("handler error", func(tt *testing.T) {
+		asst := assert.New(tt)
+
+		handlerErr := errors.New("handler error")
+		backfills := []int{}
+		r := newTestStreamRunner([]connectResult{{body: streamBody(`{"text":"1"}`, `{"text":"2"}`)}}, &backfills, nil)
+
+		received := 0
+		err := r.Run(context.Background(), func(*gotwi.MockResponse) error {
+			received++
+			return handlerErr
+		})
+
+		asst.Equal(handlerErr, err)
+		asst.Equal(1, received)
+		asst.Len(backfills, 1)
+	})
+
+	t.Run("context canceled", func(tt *testing.T) {
+		asst := assert.New(tt)
+
+		pr, pw := io.Pipe()
+		defer pw.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		backfills := []int{}
+		r := newTestStreamRunner([]connectResult{{body: pr}}, &backfills, nil)
+
+		time.AfterFunc(time.Duration(20)*time.Millisecond, cancel)
+		err := r.Run(ctx, func(*gotwi.MockResponse) error { return nil })
+
+		asst.ErrorIs(err, context.Canceled)
+		asst.Len(backfills, 1)
+	})
+}
+
+func Test_NewSearchStreamRunner(t *testing.T) {
+	asst := assert.New(t)
+
+	queries := []url.Values{}
+	client, _ := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			queries = append(queries, req.URL.Query())
+			if len(queries) > 2 {
+				return newMockResponse(http.StatusUnauthorized, `{"title": "Unauthorized"}`)
+			}
+			return newMockResponse(http.StatusOK, `{"data":{"id":"1","text":"a"}}`+"\r\n"+`{"data":{"id":"2","text":"b"}}`+"\r\n")
+		}),
+		AccessToken: "token",
+	})
+
+	r := filteredstream.NewSearchStreamRunner(client, &types.SearchStreamInput{}, &gotwi.StreamRunnerOption{Backfill: true})
+	ids := []string{}
+	err := r.Run(context.Background(), func(o *types.SearchStreamOutput) error {
+		ids = append(ids, gotwi.StringValue(o.Data.ID))
+		return nil
+	})
+
+	asst.ErrorIs(err, gotwi.ErrUnauthorized)
+	asst.Equal([]string{"1", "2"}, ids)
+	asst.Len(queries, 3)
+	asst.Equal("", queries[0].Get("backfill_minutes"))
+	asst.Equal("1", queries[1].Get("backfill_minutes"))
+}
+
+func Test_StreamRunner_StreamError(t *testing.T) {
+	asst := assert.New(t)
+
+	events := []gotwi.StreamEvent{}
+	backfills := []int{}
+	r := newTestStreamRunner([]connectResult{
+		{body: streamBody(`{"text":"1"}`, `{"errors":[{"title":"operational-disconnect","disconnect_type":"UpstreamOperationalDisconnect","detail":"This stream has been disconnected upstream for operational reasons.","type":"https://api.twitter.com/2/problems/operational-disconnect"}]}`, `{"text":"2"}`)},
+		{body: streamBody(`{"text":"3"}`)},
+	}, &backfills, &gotwi.StreamRunnerOption{
+		Backoff: &gotwi.StreamBackoff{HTTPInitial: time.Millisecond},
+		OnEvent: func(e gotwi.StreamEvent) { events = append(events, e) },
+	})
+
+	received := []string{}
+	err := r.Run(context.Background(), func(m *gotwi.MockResponse) error {
+		received = append(received, m.Text)
+		return nil
+	})
+
+	asst.ErrorIs(err, gotwi.ErrForbidden)
+	asst.Equal([]string{"1", "3"}, received)
+
+	var se *gotwi.StreamError
+	asst.Equal(gotwi.StreamDisconnected, events[2].Type)
+	asst.ErrorAs(events[2].Err, &se)
+	asst.True(se.OperationalDisconnect())
+	asst.Equal(gotwi.StreamBackingOff, events[3].Type)
+	asst.Equal(time.Millisecond, events[3].Backoff)
+}
+
+func Test_StreamRunner_ConnectionException(t *testing.T) {
+	asst := assert.New(t)
+
+	connectionException := `{"errors":[{"title":"ConnectionException","detail":"This stream is currently at the maximum allowed connection limit.","connection_issue":"TooManyConnections","type":"https://api.twitter.com/2/problems/streaming-connection"}]}`
+
+	events := []gotwi.StreamEvent{}
+	backfills := []int{}
+	r := newTestStreamRunner([]connectResult{
+		{body: streamBody(connectionException)},
+		{body: streamBody(connectionException)},
+	}, &backfills, &gotwi.StreamRunnerOption{
+		Backoff: &gotwi.StreamBackoff{RateLimitInitial: time.Millisecond},
+		OnEvent: func(e gotwi.StreamEvent) { events = append(events, e) },
+	})
+
+	err := r.Run(context.Background(), func(*gotwi.MockResponse) error { return nil })
+	asst.ErrorIs(err, gotwi.ErrForbidden)
+	asst.Len(backfills, 3)
+
+	// each refused connection backs off like a 429 error before the next attempt
+	waits := []time.Duration{}
+	for i, e := range events {
+		if e.Type != gotwi.StreamBackingOff {
+			continue
+		}
+		var se *gotwi.StreamError
+		asst.ErrorAs(e.Err, &se)
+		asst.True(se.ConnectionException())
+		asst.Equal(gotwi.StreamDisconnected, events[i-1].Type)
+		asst.Equal(gotwi.StreamConnecting, events[i+1].Type)
+		waits = append(waits, e.Backoff)
+	}
+	asst.Equal([]time.Duration{time.Millisecond, time.Duration(2) * time.Millisecond}, waits)
+}
+
+func Test_NewStreamRunner_NilInput(t *testing.T) {
+	asst := assert.New(t)
+
+	client, _ := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			return newMockResponse(http.StatusUnauthorized, `{"title": "Unauthorized"}`)
+		}),
+		AccessToken: "token",
+	})
+
+	sr := filteredstream.NewSearchStreamRunner(client, nil, nil)
+	err := sr.Run(context.Background(), func(*types.SearchStreamOutput) error { return nil })
+	asst.ErrorIs(err, gotwi.ErrUnauthorized)
+
+	vr := volumestream.NewSampleStreamRunner(client, nil, nil)
+	err = vr.Run(context.Background(), func(*volumestreamtypes.SampleStreamOutput) error { return nil })
+	asst.ErrorIs(err, gotwi.ErrUnauthorized)
+}
@@ -0,0 +1,59 @@
+package gotwi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/xxiiaaon/gotwi/resources"
+)
+
+// TokenSource supplies the OAuth 2.0 access token of every request made by a client
+// created with NewClientWithTokenSource. Implementations must be safe for concurrent use.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenInvalidator is implemented by a TokenSource that can replace a token
+// the API rejected with 401 Unauthorized. After InvalidateToken is called,
+// Token must not return the rejected token again.
+type TokenInvalidator interface {
+	InvalidateToken(token string)
+}
+
+type tokenSourceHolder interface {
+	TokenSource() TokenSource
+}
+
+// requestAccessToken returns the access token for the next request of c.
+// It is the static token unless c has a TokenSource.
+func requestAccessToken(ctx context.Context, c IClient, static string) (string, error) {
+	if h, ok := c.(tokenSourceHolder); ok && h.TokenSource() != nil {
+		t, err := h.TokenSource().Token(ctx)
+		if err != nil {
+			return "", err
+		}
+		if t == "" {
+			return "", fmt.Errorf("TokenSource returned an empty token.")
+		}
+		return t, nil
+	}
+
+	return static, nil
+}
+
+// invalidateRejectedToken tells ts that token was rejected if the API returned 401 Unauthorized.
+// It reports whether the request should be retried with a new token.
+func invalidateRejectedToken(ts TokenSource, e *resources.Non2XXError, token string) bool {
+	if ts == nil || e == nil || e.StatusCode != http.StatusUnauthorized || token == "" {
+		return false
+	}
+
+	ti, ok := ts.(TokenInvalidator)
+	if !ok {
+		return false
+	}
+
+	ti.InvalidateToken(token)
+	return true
+}
@@ -0,0 +1,209 @@
+package gotwi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/user/userlookup"
+	"github.com/xxiiaaon/gotwi/user/userlookup/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockTokenSource issues "token-1", "token-2", ... and moves on to the next token when invalidated.
+type mockTokenSource struct {
+	mu          sync.Mutex
+	n           int
+	err         error
+	invalidated []string
+}
+
+func (s *mockTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return "", s.err
+	}
+	return fmt.Sprintf("token-%d", s.n+1), nil
+}
+
+func (s *mockTokenSource) InvalidateToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalidated = append(s.invalidated, token)
+	if token == fmt.Sprintf("token-%d", s.n+1) {
+		s.n++
+	}
+}
+
+// staticTokenSource does not implement gotwi.TokenInvalidator.
+type staticTokenSource string
+
+func (s staticTokenSource) Token(ctx context.Context) (string, error) { return string(s), nil }
+
+func Test_NewClientWithTokenSource(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      *gotwi.NewClientWithTokenSourceInput
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			in:   &gotwi.NewClientWithTokenSourceInput{TokenSource: &mockTokenSource{}},
+		},
+		{
+			name:    "error: nil input",
+			in:      nil,
+			wantErr: true,
+		},
+		{
+			name:    "error: nil token source",
+			in:      &gotwi.NewClientWithTokenSourceInput{},
+			wantErr: true,
+		},
+		{
+			name:    "error: invalid base url",
+			in:      &gotwi.NewClientWithTokenSourceInput{TokenSource: &mockTokenSource{}, BaseURL: "api.x.com"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			client, err := gotwi.NewClientWithTokenSource(c.in)
+			if c.wantErr {
+				asst.Error(err)
+				asst.Nil(client)
+				return
+			}
+
+			asst.NoError(err)
+			asst.True(client.IsReady())
+			asst.Equal(c.in.TokenSource, client.TokenSource())
+			asst.Equal(gotwi.AuthenticationMethod(gotwi.AuthenMethodOAuth2BearerToken), client.AuthenticationMethod())
+		})
+	}
+}
+
+func Test_CallAPI_TokenSource(t *testing.T) {
+	cases := []struct {
+		name              string
+		source            gotwi.TokenSource
+		statusCodes       []int
+		wantErr           bool
+		expectAuth        []string
+		expectInvalidated []string
+	}{
+		{
+			name:        "ok",
+			source:      &mockTokenSource{},
+			statusCodes: []int{http.StatusOK},
+			expectAuth:  []string{"Bearer token-1"},
+		},
+		{
+			name:              "ok: retry with a new token after 401",
+			source:            &mockTokenSource{},
+			statusCodes:       []int{http.StatusUnauthorized, http.StatusOK},
+			expectAuth:        []string{"Bearer token-1", "Bearer token-2"},
+			expectInvalidated: []string{"token-1"},
+		},
+		{
+			name:              "error: retry only once",
+			source:            &mockTokenSource{},
+			statusCodes:       []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusOK},
+			wantErr:           true,
+			expectAuth:        []string{"Bearer token-1", "Bearer token-2"},
+			expectInvalidated: []string{"token-1"},
+		},
+		{
+			name:        "error: source can not be invalidated",
+			source:      staticTokenSource("static"),
+			statusCodes: []int{http.StatusUnauthorized, http.StatusOK},
+			wantErr:     true,
+			expectAuth:  []string{"Bearer static"},
+		},
+		{
+			name:        "error: source failed",
+			source:      &mockTokenSource{err: errors.New("refresh failed")},
+			statusCodes: []int{http.StatusOK},
+			wantErr:     true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			responses := []mockResponse{}
+			for _, sc := range c.statusCodes {
+				responses = append(responses, mockResponse{statusCode: sc, body: `{}`})
+			}
+
+			auth := []string{}
+			calls := 0
+			mock := newSequentialMockClient(responses, &calls)
+			client, err := gotwi.NewClientWithTokenSource(&gotwi.NewClientWithTokenSourceInput{
+				HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+					auth = append(auth, req.Header.Get("Authorization"))
+					res, _ := mock.Transport.RoundTrip(req)
+					return res
+				}),
+				TokenSource: c.source,
+			})
+			asst.NoError(err)
+
+			_, err = userlookup.GetMe(context.Background(), client, &types.GetMeInput{})
+			if c.wantErr {
+				asst.Error(err)
+			} else {
+				asst.NoError(err)
+			}
+
+			asst.Equal(len(c.expectAuth), calls)
+			if len(c.expectAuth) > 0 {
+				asst.Equal(c.expectAuth, auth)
+			}
+			if ms, ok := c.source.(*mockTokenSource); ok {
+				asst.Equal(c.expectInvalidated, ms.invalidated)
+			}
+		})
+	}
+}
+
+func Test_TypedClient_TokenSource(t *testing.T) {
+	asst := assert.New(t)
+
+	auth := []string{}
+	calls := 0
+	mock := newSequentialMockClient([]mockResponse{
+		{statusCode: http.StatusUnauthorized, body: `{}`},
+		{statusCode: http.StatusOK, body: `{"text":"hello"}`},
+	}, &calls)
+	source := &mockTokenSource{}
+	client, err := gotwi.NewClientWithTokenSource(&gotwi.NewClientWithTokenSourceInput{
+		HTTPClient: newMockClient(func(req *http.Request) *http.Response {
+			auth = append(auth, req.Header.Get("Authorization"))
+			res, _ := mock.Transport.RoundTrip(req)
+			return res
+		}),
+		TokenSource: source,
+	})
+	asst.NoError(err)
+
+	tc := gotwi.NewTypedClient[*gotwi.MockResponse](client)
+	asst.True(tc.IsReady())
+
+	s, err := tc.CallStreamAPI(context.Background(), "/2/tweets/search/stream", http.MethodGet, &types.GetMeInput{})
+	asst.NoError(err)
+	defer s.Stop()
+
+	asst.Equal(2, calls)
+	asst.Equal([]string{"Bearer token-1", "Bearer token-2"}, auth)
+	asst.Equal([]string{"token-1"}, source.invalidated)
+}
@@ -8,9 +8,9 @@ import (
 )
 
 const (
-	listEndpoint   = "https://api.twitter.com/2/users/:id/bookmarks"
-	createEndpoint = "https://api.twitter.com/2/users/:id/bookmarks"
-	deleteEndpoint = "https://api.twitter.com/2/users/:id/bookmarks/:tweet_id"
+	listEndpoint   = "/2/users/:id/bookmarks"
+	createEndpoint = "/2/users/:id/bookmarks"
+	deleteEndpoint = "/2/users/:id/bookmarks/:tweet_id"
 )
 
 // Allows you to get information about a authenticated user’s 800 most recent bookmarked Tweets
@@ -18,6 +18,9 @@ const (
 func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.ListOutput, error) {
 	res := &types.ListOutput{}
 	if err := c.CallAPI(ctx, listEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -30,6 +33,9 @@ func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.List
 func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.CreateOutput, error) {
 	res := &types.CreateOutput{}
 	if err := c.CallAPI(ctx, createEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -41,6 +47,9 @@ func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.
 func Delete(ctx context.Context, c *gotwi.Client, p *types.DeleteInput) (*types.DeleteOutput, error) {
 	res := &types.DeleteOutput{}
 	if err := c.CallAPI(ctx, deleteEndpoint, "DELETE", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -18,6 +18,17 @@ func (r *ListOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type CreateOutput struct {
 	Data struct {
 		Bookmarked bool `json:"bookmarked"`
@@ -8,9 +8,9 @@ import (
 )
 
 const (
-	listRulesEndpoint           = "https://api.twitter.com/2/tweets/search/stream/rules"
-	createOrDeleteRulesEndpoint = "https://api.twitter.com/2/tweets/search/stream/rules"
-	searchStreamEndpoint        = "https://api.twitter.com/2/tweets/search/stream"
+	listRulesEndpoint           = "/2/tweets/search/stream/rules"
+	createOrDeleteRulesEndpoint = "/2/tweets/search/stream/rules"
+	searchStreamEndpoint        = "/2/tweets/search/stream"
 )
 
 // Return a list of rules currently active on the streaming endpoint, either as a list or individually.
@@ -18,6 +18,9 @@ const (
 func ListRules(ctx context.Context, c *gotwi.Client, p *types.ListRulesInput) (*types.ListRulesOutput, error) {
 	res := &types.ListRulesOutput{}
 	if err := c.CallAPI(ctx, listRulesEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -29,6 +32,9 @@ func ListRules(ctx context.Context, c *gotwi.Client, p *types.ListRulesInput) (*
 func CreateRules(ctx context.Context, c *gotwi.Client, p *types.CreateRulesInput) (*types.CreateRulesOutput, error) {
 	res := &types.CreateRulesOutput{}
 	if err := c.CallAPI(ctx, createOrDeleteRulesEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -40,6 +46,9 @@ func CreateRules(ctx context.Context, c *gotwi.Client, p *types.CreateRulesInput
 func DeleteRules(ctx context.Context, c *gotwi.Client, p *types.DeleteRulesInput) (*types.DeleteRulesOutput, error) {
 	res := &types.DeleteRulesOutput{}
 	if err := c.CallAPI(ctx, createOrDeleteRulesEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -58,3 +67,28 @@ func SearchStream(ctx context.Context, c *gotwi.Client, p *types.SearchStreamInp
 
 	return s, nil
 }
+
+// NewSearchStreamRunner returns a runner that keeps SearchStream connected with p.
+// When opt.Backfill is true, the BackfillMinutes of p is replaced on reconnection
+// by the minutes since the last message. Tweets delivered twice are dropped by ID.
+func NewSearchStreamRunner(c *gotwi.Client, p *types.SearchStreamInput, opt *gotwi.StreamRunnerOption) *gotwi.StreamRunner[*types.SearchStreamOutput] {
+	connect := func(ctx context.Context, backfillMinutes int) (*gotwi.StreamClient[*types.SearchStreamOutput], error) {
+		in := types.SearchStreamInput{}
+		if p != nil {
+			in = *p
+		}
+		if backfillMinutes > 0 {
+			in.BackfillMinutes = types.SearchStreamBackfillMinutes(backfillMinutes)
+		}
+		return SearchStream(ctx, c, &in)
+	}
+
+	id := func(o *types.SearchStreamOutput) string {
+		if o == nil {
+			return ""
+		}
+		return gotwi.StringValue(o.Data.ID)
+	}
+
+	return gotwi.NewStreamRunner(connect, id, opt)
+}
@@ -0,0 +1,368 @@
+package filteredstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream/types"
+)
+
+const (
+	defaultRouteWorkers   = 1
+	defaultRouteQueueSize = 100
+)
+
+// RouteHandler handles the messages of a route. A message matching several routes, or
+// handled by several workers, is the same *types.SearchStreamOutput, so it must be treated
+// as read-only. A handler may call Handle, but Close waits for the handlers and must be
+// called from another goroutine.
+type RouteHandler func(out *types.SearchStreamOutput) error
+
+// OverflowPolicy is what Dispatch does when the queue of a route is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits until the route has room, which slows down the reading of the stream.
+	// The other routes receive the message first.
+	OverflowBlock OverflowPolicy = "block"
+
+	// OverflowDrop drops the message for the full route and passes it to OnDrop,
+	// so that a slow route never delays the others.
+	OverflowDrop OverflowPolicy = "drop"
+)
+
+type RouterOption struct {
+	// OnError is called with the errors returned by the handlers and their recovered panics.
+	// It is called from the workers of the route and must be safe for concurrent use.
+	OnError func(err *RouteError)
+
+	// OnUnmatched is called with the messages that match no route.
+	OnUnmatched func(out *types.SearchStreamOutput)
+
+	// Overflow is the policy for full queues. Default is OverflowBlock.
+	Overflow OverflowPolicy
+
+	// OnDrop is called with the messages dropped for a route by OverflowDrop.
+	OnDrop func(route string, out *types.SearchStreamOutput)
+}
+
+type RouteInput struct {
+	// Name identifies the route in RouteError. Default is the first tag, glob or rule ID.
+	Name string
+
+	// The route receives the messages with a matching rule that has one of Tags,
+	// a tag that matches one of TagGlobs in the syntax of path.Match, or one of RuleIDs.
+	Tags     []string
+	TagGlobs []string
+	RuleIDs  []string
+
+	Handler RouteHandler
+
+	// Workers is the number of goroutines calling Handler. Default is 1, which handles
+	// the messages in the order they were received.
+	Workers int
+
+	// QueueSize is the number of messages waiting for a worker. What Dispatch does while
+	// the queue is full depends on RouterOption.Overflow. Default is 100.
+	QueueSize int
+}
+
+// RouteError is an error of the handler of a route.
+type RouteError struct {
+	Route   string
+	Message *types.SearchStreamOutput
+	Err     error
+}
+
+func (e *RouteError) Error() string {
+	return fmt.Sprintf("route %s: %v", e.Route, e.Err)
+}
+
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
+
+// Router dispatches the messages of the filtered stream to handlers by the tags and IDs
+// of their MatchingRules, so that one stream connection serves several consumers.
+// A message matching several rules is delivered once to each route that any of the rules
+// selects. Every route has its own workers and queue, and the errors of a handler do not
+// affect other routes. With OverflowDrop, a slow route does not delay the others either.
+//
+//	r := filteredstream.NewRouter(&filteredstream.RouterOption{OnError: logRouteError})
+//	r.Handle(&filteredstream.RouteInput{TagGlobs: []string{"cats-*"}, Handler: handleCats, Workers: 4})
+//	err := r.Run(ctx, filteredstream.NewSearchStreamRunner(c, p, nil).Run)
+type Router struct {
+	opt RouterOption
+
+	mu     sync.RWMutex
+	routes []*route
+	names  map[string]struct{}
+	closed bool
+
+	// done is closed by Close to unblock Dispatch, and stop once no Dispatch is in flight,
+	// after which the workers handle the queued messages and return.
+	done        chan struct{}
+	stop        chan struct{}
+	dispatching sync.WaitGroup
+	wg          sync.WaitGroup
+}
+
+type route struct {
+	name    string
+	tags    map[string]struct{}
+	globs   []string
+	ruleIDs map[string]struct{}
+	handler RouteHandler
+	queue   chan *types.SearchStreamOutput
+}
+
+func NewRouter(opt *RouterOption) *Router {
+	r := &Router{
+		names: map[string]struct{}{},
+		done:  make(chan struct{}),
+		stop:  make(chan struct{}),
+	}
+	if opt != nil {
+		r.opt = *opt
+	}
+	if r.opt.Overflow == "" {
+		r.opt.Overflow = OverflowBlock
+	}
+
+	return r
+}
+
+// Handle adds a route and starts its workers.
+func (r *Router) Handle(in *RouteInput) error {
+	if in == nil {
+		return fmt.Errorf("RouteInput is nil.")
+	}
+
+	if in.Handler == nil {
+		return fmt.Errorf("Handler is required.")
+	}
+
+	if len(in.Tags) == 0 && len(in.TagGlobs) == 0 && len(in.RuleIDs) == 0 {
+		return fmt.Errorf("Tags, TagGlobs or RuleIDs is required.")
+	}
+
+	for _, g := range in.TagGlobs {
+		if _, err := path.Match(g, ""); err != nil {
+			return fmt.Errorf("TagGlob %q is invalid: %w", g, err)
+		}
+	}
+
+	rt := &route{
+		name:    in.Name,
+		tags:    toSet(in.Tags),
+		globs:   in.TagGlobs,
+		ruleIDs: toSet(in.RuleIDs),
+		handler: in.Handler,
+	}
+	if rt.name == "" {
+		rt.name = defaultRouteName(in)
+	}
+
+	workers := in.Workers
+	if workers <= 0 {
+		workers = defaultRouteWorkers
+	}
+	queueSize := in.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultRouteQueueSize
+	}
+	rt.queue = make(chan *types.SearchStreamOutput, queueSize)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return fmt.Errorf("Router is closed.")
+	}
+	if _, ok := r.names[rt.name]; ok {
+		return fmt.Errorf("Route %q is already registered.", rt.name)
+	}
+	r.names[rt.name] = struct{}{}
+	r.routes = append(r.routes, rt)
+
+	r.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go r.work(rt)
+	}
+
+	return nil
+}
+
+func defaultRouteName(in *RouteInput) string {
+	switch {
+	case len(in.Tags) > 0:
+		return "tag:" + in.Tags[0]
+	case len(in.TagGlobs) > 0:
+		return "glob:" + in.TagGlobs[0]
+	default:
+		return "rule:" + in.RuleIDs[0]
+	}
+}
+
+func toSet(values []string) map[string]struct{} {
+	m := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		m[v] = struct{}{}
+	}
+	return m
+}
+
+// Dispatch queues out for every route that one of its MatchingRules selects. The routes with
+// room receive out first, so that a full queue does not delay them. Then Dispatch drops out
+// for the full routes with OverflowDrop, or blocks until they have room with OverflowBlock.
+// It returns ctx.Err() if ctx is done first, or an error if the Router is closed meanwhile,
+// in which case the full routes still waiting do not receive out.
+// The errors of the handlers are passed to OnError, not returned.
+func (r *Router) Dispatch(ctx context.Context, out *types.SearchStreamOutput) error {
+	if out == nil {
+		return nil
+	}
+
+	// the routes are copied so that the lock is not held while a queue is full
+	r.mu.RLock()
+	if r.closed {
+		r.mu.RUnlock()
+		return fmt.Errorf("Router is closed.")
+	}
+	routes := append([]*route{}, r.routes...)
+	r.dispatching.Add(1)
+	r.mu.RUnlock()
+	defer r.dispatching.Done()
+
+	matched := false
+	full := []*route{}
+	for _, rt := range routes {
+		if !rt.selects(out.MatchingRules) {
+			continue
+		}
+		matched = true
+
+		select {
+		case rt.queue <- out:
+		default:
+			full = append(full, rt)
+		}
+	}
+
+	if !matched && r.opt.OnUnmatched != nil {
+		r.opt.OnUnmatched(out)
+	}
+
+	for _, rt := range full {
+		if r.opt.Overflow == OverflowDrop {
+			if r.opt.OnDrop != nil {
+				r.opt.OnDrop(rt.name, out)
+			}
+			continue
+		}
+
+		select {
+		case rt.queue <- out:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.done:
+			return fmt.Errorf("Router is closed.")
+		}
+	}
+
+	return nil
+}
+
+func (rt *route) selects(rules []types.SearchStreamMatchedRule) bool {
+	for _, mr := range rules {
+		if _, ok := rt.ruleIDs[gotwi.StringValue(mr.ID)]; ok {
+			return true
+		}
+		if mr.Tag == nil {
+			continue
+		}
+		if _, ok := rt.tags[*mr.Tag]; ok {
+			return true
+		}
+		for _, g := range rt.globs {
+			if ok, _ := path.Match(g, *mr.Tag); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Router) work(rt *route) {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case out := <-rt.queue:
+			r.deliver(rt, out)
+		case <-r.stop:
+			// nothing is queued after stop, so the queue is drained once it is empty
+			for {
+				select {
+				case out := <-rt.queue:
+					r.deliver(rt, out)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Router) deliver(rt *route, out *types.SearchStreamOutput) {
+	if err := rt.handle(out); err != nil && r.opt.OnError != nil {
+		r.opt.OnError(&RouteError{Route: rt.name, Message: out, Err: err})
+	}
+}
+
+func (rt *route) handle(out *types.SearchStreamOutput) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("handler panicked: %v", p)
+		}
+	}()
+
+	return rt.handler(out)
+}
+
+// Close stops accepting messages and waits until the queued messages are handled.
+// It must not be called from a handler, which it would wait for.
+func (r *Router) Close() {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		r.wg.Wait()
+		return
+	}
+	r.closed = true
+	close(r.done)
+	r.mu.Unlock()
+
+	r.dispatching.Wait()
+	close(r.stop)
+	r.wg.Wait()
+}
+
+// Run dispatches the messages of run, which is StreamRunner.Run or StreamClient.Run,
+// and closes the Router when run returns. It returns the error of run.
+func (r *Router) Run(ctx context.Context, run func(context.Context, func(*types.SearchStreamOutput) error) error) error {
+	if run == nil {
+		return errors.New("run is nil.")
+	}
+
+	err := run(ctx, func(out *types.SearchStreamOutput) error {
+		return r.Dispatch(ctx, out)
+	})
+	r.Close()
+
+	return err
+}
@@ -0,0 +1,448 @@
+package filteredstream_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func matched(id string, rules ...[2]string) *types.SearchStreamOutput {
+	out := &types.SearchStreamOutput{}
+	out.Data.ID = gotwi.String(id)
+	for _, r := range rules {
+		mr := types.SearchStreamMatchedRule{ID: gotwi.String(r[0])}
+		if r[1] != "" {
+			mr.Tag = gotwi.String(r[1])
+		}
+		out.MatchingRules = append(out.MatchingRules, mr)
+	}
+	return out
+}
+
+// collector records the IDs of the messages received by each route.
+type collector struct {
+	mu  sync.Mutex
+	ids map[string][]string
+}
+
+func (c *collector) handler(route string) filteredstream.RouteHandler {
+	return func(out *types.SearchStreamOutput) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.ids[route] = append(c.ids[route], gotwi.StringValue(out.Data.ID))
+		return nil
+	}
+}
+
+func Test_Router_Dispatch(t *testing.T) {
+	asst := assert.New(t)
+
+	col := &collector{ids: map[string][]string{}}
+	unmatched := []string{}
+	r := filteredstream.NewRouter(&filteredstream.RouterOption{
+		OnUnmatched: func(out *types.SearchStreamOutput) {
+			unmatched = append(unmatched, gotwi.StringValue(out.Data.ID))
+		},
+	})
+
+	asst.NoError(r.Handle(&filteredstream.RouteInput{Tags: []string{"cats", "kittens"}, Handler: col.handler("cats")}))
+	asst.NoError(r.Handle(&filteredstream.RouteInput{TagGlobs: []string{"dogs-*"}, Handler: col.handler("dogs")}))
+	asst.NoError(r.Handle(&filteredstream.RouteInput{Name: "rule", RuleIDs: []string{"9"}, Handler: col.handler("rule")}))
+
+	ctx := context.Background()
+	asst.NoError(r.Dispatch(ctx, matched("1", [2]string{"1", "cats"})))
+	asst.NoError(r.Dispatch(ctx, matched("2", [2]string{"1", "cats"}, [2]string{"2", "kittens"}, [2]string{"3", "dogs-en"})))
+	asst.NoError(r.Dispatch(ctx, matched("3", [2]string{"4", "dogs-ja"}, [2]string{"9", ""})))
+	asst.NoError(r.Dispatch(ctx, matched("4", [2]string{"5", "birds"})))
+	asst.NoError(r.Dispatch(ctx, matched("5")))
+	r.Close()
+
+	asst.Equal(map[string][]string{
+		"cats": {"1", "2"},
+		"dogs": {"2", "3"},
+		"rule": {"3"},
+	}, col.ids)
+	asst.Equal([]string{"4", "5"}, unmatched)
+
+	asst.Error(r.Dispatch(ctx, matched("6", [2]string{"1", "cats"})))
+	asst.Error(r.Handle(&filteredstream.RouteInput{Tags: []string{"birds"}, Handler: col.handler("birds")}))
+}
+
+func Test_Router_Handle(t *testing.T) {
+	handler := func(*types.SearchStreamOutput) error { return nil }
+
+	cases := []struct {
+		name    string
+		in      *filteredstream.RouteInput
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			in:   &filteredstream.RouteInput{Tags: []string{"a"}, Handler: handler},
+		},
+		{
+			name:    "error: nil",
+			wantErr: true,
+		},
+		{
+			name:    "error: no handler",
+			in:      &filteredstream.RouteInput{Tags: []string{"a"}},
+			wantErr: true,
+		},
+		{
+			name:    "error: no selector",
+			in:      &filteredstream.RouteInput{Handler: handler},
+			wantErr: true,
+		},
+		{
+			name:    "error: invalid glob",
+			in:      &filteredstream.RouteInput{TagGlobs: []string{"a["}, Handler: handler},
+			wantErr: true,
+		},
+		{
+			name:    "error: duplicated name",
+			in:      &filteredstream.RouteInput{Name: "existing", RuleIDs: []string{"1"}, Handler: handler},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			r := filteredstream.NewRouter(nil)
+			defer r.Close()
+			asst.NoError(r.Handle(&filteredstream.RouteInput{Name: "existing", Tags: []string{"x"}, Handler: handler}))
+
+			err := r.Handle(c.in)
+			if c.wantErr {
+				asst.Error(err)
+				return
+			}
+			asst.NoError(err)
+		})
+	}
+}
+
+func Test_Router_ErrorIsolation(t *testing.T) {
+	asst := assert.New(t)
+
+	var mu sync.Mutex
+	errs := []string{}
+	col := &collector{ids: map[string][]string{}}
+	r := filteredstream.NewRouter(&filteredstream.RouterOption{
+		OnError: func(e *filteredstream.RouteError) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, e.Route+" "+gotwi.StringValue(e.Message.Data.ID)+" "+e.Err.Error())
+		},
+	})
+
+	errFailed := errors.New("failed")
+	asst.NoError(r.Handle(&filteredstream.RouteInput{
+		Tags: []string{"flaky"},
+		Handler: func(out *types.SearchStreamOutput) error {
+			switch gotwi.StringValue(out.Data.ID) {
+			case "1":
+				return errFailed
+			case "2":
+				panic("boom")
+			}
+			return nil
+		},
+	}))
+	asst.NoError(r.Handle(&filteredstream.RouteInput{Tags: []string{"stable"}, Handler: col.handler("stable")}))
+
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3"} {
+		asst.NoError(r.Dispatch(ctx, matched(id, [2]string{"1", "flaky"}, [2]string{"2", "stable"})))
+	}
+	r.Close()
+
+	asst.Equal([]string{"1", "2", "3"}, col.ids["stable"])
+	asst.Equal([]string{"tag:flaky 1 failed", "tag:flaky 2 handler panicked: boom"}, errs)
+}
+
+func Test_Router_Backpressure(t *testing.T) {
+	asst := assert.New(t)
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	r := filteredstream.NewRouter(nil)
+	asst.NoError(r.Handle(&filteredstream.RouteInput{
+		Tags:      []string{"slow"},
+		QueueSize: 1,
+		Handler: func(*types.SearchStreamOutput) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// the first message is taken by the worker and the second fills the queue
+	asst.NoError(r.Dispatch(ctx, matched("1", [2]string{"1", "slow"})))
+	<-started
+	asst.NoError(r.Dispatch(ctx, matched("2", [2]string{"1", "slow"})))
+	asst.ErrorIs(r.Dispatch(ctx, matched("3", [2]string{"1", "slow"})), context.DeadlineExceeded)
+
+	close(release)
+	<-started
+	r.Close()
+}
+
+func Test_Router_Workers(t *testing.T) {
+	asst := assert.New(t)
+
+	// every handler waits until all the workers are busy
+	const workers = 3
+	var started sync.WaitGroup
+	started.Add(workers)
+	r := filteredstream.NewRouter(nil)
+	asst.NoError(r.Handle(&filteredstream.RouteInput{
+		Tags:    []string{"parallel"},
+		Workers: workers,
+		Handler: func(*types.SearchStreamOutput) error {
+			started.Done()
+			started.Wait()
+			return nil
+		},
+	}))
+
+	for i := 0; i < workers; i++ {
+		asst.NoError(r.Dispatch(context.Background(), matched("1", [2]string{"1", "parallel"})))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("workers did not run in parallel")
+	}
+}
+
+func Test_Router_Run(t *testing.T) {
+	asst := assert.New(t)
+
+	lines := strings.Join([]string{
+		`{"data":{"id":"1","text":"a"},"matching_rules":[{"id":"1","tag":"a"}]}`,
+		`{"data":{"id":"2","text":"b"},"matching_rules":[{"id":"2","tag":"b"},{"id":"1","tag":"a"}]}`,
+	}, "\r\n")
+	s, err := gotwi.NewReplayStreamClient[*types.SearchStreamOutput](strings.NewReader(lines), nil)
+	asst.NoError(err)
+
+	col := &collector{ids: map[string][]string{}}
+	r := filteredstream.NewRouter(nil)
+	asst.NoError(r.Handle(&filteredstream.RouteInput{TagGlobs: []string{"*"}, Handler: col.handler("all")}))
+	asst.NoError(r.Handle(&filteredstream.RouteInput{Tags: []string{"b"}, Handler: col.handler("b")}))
+
+	asst.NoError(r.Run(context.Background(), s.Run))
+
+	all := col.ids["all"]
+	sort.Strings(all)
+	asst.Equal([]string{"1", "2"}, all)
+	asst.Equal([]string{"2"}, col.ids["b"])
+}
+
+func Test_Router_SharedMessages(t *testing.T) {
+	asst := assert.New(t)
+
+	// several workers of overlapping routes read the same messages; run with -race
+	col := &collector{ids: map[string][]string{}}
+	read := func(route string) filteredstream.RouteHandler {
+		h := col.handler(route)
+		return func(out *types.SearchStreamOutput) error {
+			for _, mr := range out.MatchingRules {
+				_ = gotwi.StringValue(mr.Tag)
+			}
+			return h(out)
+		}
+	}
+	r := filteredstream.NewRouter(nil)
+	asst.NoError(r.Handle(&filteredstream.RouteInput{Tags: []string{"cats"}, Handler: read("cats"), Workers: 4}))
+	asst.NoError(r.Handle(&filteredstream.RouteInput{TagGlobs: []string{"c*"}, Handler: read("glob"), Workers: 4}))
+	asst.NoError(r.Handle(&filteredstream.RouteInput{RuleIDs: []string{"1"}, Handler: read("rule"), Workers: 4}))
+
+	const messages = 100
+	for i := 0; i < messages; i++ {
+		asst.NoError(r.Dispatch(context.Background(), matched("1", [2]string{"1", "cats"})))
+	}
+	r.Close()
+
+	for _, route := range []string{"cats", "glob", "rule"} {
+		asst.Len(col.ids[route], messages, route)
+	}
+}
+
+func Test_Router_HandleFromHandler(t *testing.T) {
+	asst := assert.New(t)
+
+	col := &collector{ids: map[string][]string{}}
+	r := filteredstream.NewRouter(nil)
+	var once sync.Once
+	asst.NoError(r.Handle(&filteredstream.RouteInput{
+		Tags:      []string{"a"},
+		QueueSize: 1,
+		Handler: func(*types.SearchStreamOutput) error {
+			var err error
+			once.Do(func() {
+				// Dispatch is blocked on the full queue meanwhile
+				time.Sleep(20 * time.Millisecond)
+				err = r.Handle(&filteredstream.RouteInput{Tags: []string{"b"}, Handler: col.handler("b")})
+			})
+			return err
+		},
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, id := range []string{"1", "2", "3"} {
+			asst.NoError(r.Dispatch(context.Background(), matched(id, [2]string{"1", "a"})))
+		}
+		asst.NoError(r.Dispatch(context.Background(), matched("4", [2]string{"2", "b"})))
+		r.Close()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Handle from a handler deadlocked")
+	}
+	asst.Equal([]string{"4"}, col.ids["b"])
+}
+
+func Test_Router_CloseUnblocksDispatch(t *testing.T) {
+	asst := assert.New(t)
+
+	release := make(chan struct{})
+	r := filteredstream.NewRouter(nil)
+	asst.NoError(r.Handle(&filteredstream.RouteInput{
+		Tags:      []string{"slow"},
+		QueueSize: 1,
+		Handler: func(*types.SearchStreamOutput) error {
+			<-release
+			return nil
+		},
+	}))
+
+	errs := make(chan error, 3)
+	go func() {
+		for _, id := range []string{"1", "2", "3"} {
+			errs <- r.Dispatch(context.Background(), matched(id, [2]string{"1", "slow"}))
+		}
+	}()
+	asst.NoError(<-errs)
+	asst.NoError(<-errs)
+
+	// the third Dispatch waits for the full queue until Close
+	closed := make(chan struct{})
+	go func() {
+		r.Close()
+		close(closed)
+	}()
+	asst.Error(<-errs)
+
+	close(release)
+	<-closed
+}
+
+func Test_Router_Overflow(t *testing.T) {
+	cases := []struct {
+		name          string
+		overflow      filteredstream.OverflowPolicy
+		wantErr       error
+		expectSlow    []string
+		expectDropped []string
+	}{
+		{
+			name:          "drop",
+			overflow:      filteredstream.OverflowDrop,
+			expectSlow:    []string{"1", "2"},
+			expectDropped: []string{"3", "4", "5"},
+		},
+		{
+			name:          "block",
+			overflow:      filteredstream.OverflowBlock,
+			wantErr:       context.DeadlineExceeded,
+			expectSlow:    []string{"1", "2"},
+			expectDropped: []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			var mu sync.Mutex
+			dropped := []string{}
+			col := &collector{ids: map[string][]string{}}
+			r := filteredstream.NewRouter(&filteredstream.RouterOption{
+				Overflow: c.overflow,
+				OnDrop: func(route string, out *types.SearchStreamOutput) {
+					mu.Lock()
+					defer mu.Unlock()
+					dropped = append(dropped, gotwi.StringValue(out.Data.ID))
+				},
+			})
+
+			started := make(chan struct{}, 1)
+			release := make(chan struct{})
+			slow := col.handler("slow")
+			asst.NoError(r.Handle(&filteredstream.RouteInput{
+				Name:      "slow",
+				Tags:      []string{"a"},
+				QueueSize: 1,
+				Handler: func(out *types.SearchStreamOutput) error {
+					select {
+					case started <- struct{}{}:
+					default:
+					}
+					<-release
+					return slow(out)
+				},
+			}))
+			asst.NoError(r.Handle(&filteredstream.RouteInput{Name: "fast", Tags: []string{"a"}, Handler: col.handler("fast")}))
+
+			// the slow route blocks on the first message and queues the second
+			asst.NoError(r.Dispatch(context.Background(), matched("1", [2]string{"1", "a"})))
+			<-started
+			asst.NoError(r.Dispatch(context.Background(), matched("2", [2]string{"1", "a"})))
+
+			for _, id := range []string{"3", "4", "5"} {
+				ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+				err := r.Dispatch(ctx, matched(id, [2]string{"1", "a"}))
+				cancel()
+				if c.wantErr != nil {
+					asst.ErrorIs(err, c.wantErr)
+				} else {
+					asst.NoError(err)
+				}
+			}
+
+			close(release)
+			r.Close()
+
+			// the fast route receives every message while the slow one is full
+			asst.Equal([]string{"1", "2", "3", "4", "5"}, col.ids["fast"])
+			asst.Equal(c.expectSlow, col.ids["slow"])
+			asst.Equal(c.expectDropped, dropped)
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package filteredstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/resources"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream/types"
+)
+
+// duplicateRuleType is the problem type of a rule whose value is already active.
+const duplicateRuleType = "https://api.twitter.com/2/problems/duplicate-rules"
+
+// ErrInvalidRules is returned by PlanRules and SyncRules when some of the rules were rejected.
+// The rejected rules are in SyncRulesReport.RuleErrors.
+var ErrInvalidRules = errors.New("Some rules are invalid.")
+
+// Rule is a rule that should be active on the filtered stream.
+// Rules are identified by Tag and Value, so changing the tag replaces the rule.
+type Rule struct {
+	Value string
+	Tag   string
+}
+
+func (r Rule) String() string {
+	if r.Tag == "" {
+		return r.Value
+	}
+	return fmt.Sprintf("%s (tag: %s)", r.Value, r.Tag)
+}
+
+func ruleOf(r resources.FilterdStreamRule) Rule {
+	return Rule{
+		Value: gotwi.StringValue(r.Value),
+		Tag:   gotwi.StringValue(r.Tag),
+	}
+}
+
+// RuleError is the reason why a rule was not created.
+type RuleError struct {
+	Rule  Rule
+	Error resources.PartialError
+}
+
+// SyncRulesReport is the plan of SyncRules and PlanRules, and the result of SyncRules.
+type SyncRulesReport struct {
+	// Unchanged are the active rules that are also desired.
+	Unchanged []resources.FilterdStreamRule
+
+	// ToDelete are the active rules that are not desired.
+	ToDelete []resources.FilterdStreamRule
+
+	// ToCreate are the desired rules that are not active.
+	ToCreate []Rule
+
+	// Applied is true if the rules were changed.
+	Applied bool
+
+	// Created are the rules created by SyncRules, with their IDs.
+	Created []resources.FilterdStreamRule
+
+	CreateSummary resources.CreateSearchStreamRulesMetaSummary
+	DeleteSummary resources.DeleteSearchStreamRulesMetaSummary
+
+	// RuleErrors are the rules rejected by the dry run, the deletion or the creation.
+	RuleErrors []RuleError
+}
+
+// HasChanges reports whether the active rules differ from the desired rules.
+func (r *SyncRulesReport) HasChanges() bool {
+	return len(r.ToDelete) > 0 || len(r.ToCreate) > 0
+}
+
+// String returns the plan as a diff, e.g. for the output of a CI job.
+func (r *SyncRulesReport) String() string {
+	b := strings.Builder{}
+	for _, d := range r.ToDelete {
+		fmt.Fprintf(&b, "- %s\n", ruleOf(d))
+	}
+	for _, c := range r.ToCreate {
+		fmt.Fprintf(&b, "+ %s\n", c)
+	}
+	for _, e := range r.RuleErrors {
+		fmt.Fprintf(&b, "! %s: %s\n", e.Rule, ruleErrorDetail(e.Error))
+	}
+	fmt.Fprintf(&b, "%d to delete, %d to create, %d unchanged\n", len(r.ToDelete), len(r.ToCreate), len(r.Unchanged))
+	return b.String()
+}
+
+func ruleErrorDetail(e resources.PartialError) string {
+	if e.Detail != nil {
+		return *e.Detail
+	}
+	return gotwi.StringValue(e.Title)
+}
+
+// PlanRules compares the desired rules with the active rules without changing them.
+// The changes are validated with a dry run, and if any rule is rejected, ErrInvalidRules
+// is returned with the report, whose RuleErrors hold the errors per rule.
+func PlanRules(ctx context.Context, c *gotwi.Client, desired []Rule) (*SyncRulesReport, error) {
+	if err := validateDesiredRules(desired); err != nil {
+		return nil, err
+	}
+
+	live, err := ListRules(ctx, c, &types.ListRulesInput{})
+	if err != nil && !gotwi.IsPartialErrors(err) {
+		return nil, err
+	}
+
+	report := planRules(live.Data, desired)
+	if !report.HasChanges() {
+		return report, nil
+	}
+
+	if len(report.ToDelete) > 0 {
+		res, err := DeleteRules(ctx, c, deleteRulesInput(report.ToDelete, true))
+		if err != nil && !gotwi.IsPartialErrors(err) {
+			return report, err
+		}
+		report.RuleErrors = append(report.RuleErrors, deleteRuleErrors(report.ToDelete, res.Errors)...)
+	}
+	if len(report.ToCreate) > 0 {
+		res, err := CreateRules(ctx, c, createRulesInput(report.ToCreate, true))
+		if err != nil && !gotwi.IsPartialErrors(err) {
+			return report, err
+		}
+		// the values of the rules to delete are still active during the dry run
+		report.RuleErrors = append(report.RuleErrors, ruleErrors(report.ToCreate, res.Errors, deletedValues(report.ToDelete))...)
+	}
+	if len(report.RuleErrors) > 0 {
+		return report, ErrInvalidRules
+	}
+
+	return report, nil
+}
+
+// SyncRules makes the active rules of the filtered stream equal to desired. The changes are
+// planned and validated with PlanRules first, and nothing is changed if any rule is rejected.
+// Then the stale rules are deleted before the new rules are created, so that a rule can be
+// replaced with another tag. The rules that fail to be deleted or created are also in RuleErrors,
+// with ErrInvalidRules.
+func SyncRules(ctx context.Context, c *gotwi.Client, desired []Rule) (*SyncRulesReport, error) {
+	report, err := PlanRules(ctx, c, desired)
+	if err != nil {
+		return report, err
+	}
+
+	if !report.HasChanges() {
+		return report, nil
+	}
+
+	if len(report.ToDelete) > 0 {
+		res, err := DeleteRules(ctx, c, deleteRulesInput(report.ToDelete, false))
+		if err != nil && !gotwi.IsPartialErrors(err) {
+			return report, err
+		}
+		report.Applied = true
+		report.DeleteSummary = res.Meta.Summary
+		report.RuleErrors = append(report.RuleErrors, deleteRuleErrors(report.ToDelete, res.Errors)...)
+	}
+	if len(report.ToCreate) > 0 {
+		res, err := CreateRules(ctx, c, createRulesInput(report.ToCreate, false))
+		if err != nil && !gotwi.IsPartialErrors(err) {
+			return report, err
+		}
+		report.Applied = true
+		report.Created = res.Data
+		report.CreateSummary = res.Meta.Summary
+		report.RuleErrors = append(report.RuleErrors, ruleErrors(report.ToCreate, res.Errors, nil)...)
+	}
+	if len(report.RuleErrors) > 0 {
+		return report, ErrInvalidRules
+	}
+
+	return report, nil
+}
+
+func validateDesiredRules(desired []Rule) error {
+	values := map[string]struct{}{}
+	for _, r := range desired {
+		if r.Value == "" {
+			return fmt.Errorf("Rule value is required.")
+		}
+		if _, ok := values[r.Value]; ok {
+			return fmt.Errorf("Rule value %q is duplicated.", r.Value)
+		}
+		values[r.Value] = struct{}{}
+	}
+
+	return nil
+}
+
+func planRules(live []resources.FilterdStreamRule, desired []Rule) *SyncRulesReport {
+	report := &SyncRulesReport{
+		Unchanged: []resources.FilterdStreamRule{},
+		ToDelete:  []resources.FilterdStreamRule{},
+		ToCreate:  []Rule{},
+	}
+
+	wanted := map[Rule]struct{}{}
+	for _, r := range desired {
+		wanted[r] = struct{}{}
+	}
+
+	active := map[Rule]struct{}{}
+	for _, l := range live {
+		r := ruleOf(l)
+		if _, ok := wanted[r]; ok {
+			report.Unchanged = append(report.Unchanged, l)
+			active[r] = struct{}{}
+		} else {
+			report.ToDelete = append(report.ToDelete, l)
+		}
+	}
+
+	for _, r := range desired {
+		if _, ok := active[r]; !ok {
+			report.ToCreate = append(report.ToCreate, r)
+		}
+	}
+
+	return report
+}
+
+func createRulesInput(rules []Rule, dryRun bool) *types.CreateRulesInput {
+	add := make(types.AddingRules, 0, len(rules))
+	for _, r := range rules {
+		ar := types.AddingRule{Value: gotwi.String(r.Value)}
+		if r.Tag != "" {
+			ar.Tag = gotwi.String(r.Tag)
+		}
+		add = append(add, ar)
+	}
+
+	return &types.CreateRulesInput{DryRun: dryRun, Add: add}
+}
+
+func deleteRulesInput(rules []resources.FilterdStreamRule, dryRun bool) *types.DeleteRulesInput {
+	ids := make([]string, 0, len(rules))
+	for _, r := range rules {
+		ids = append(ids, gotwi.StringValue(r.ID))
+	}
+
+	return &types.DeleteRulesInput{DryRun: dryRun, Delete: &types.DeletingRules{IDs: ids}}
+}
+
+func deletedValues(rules []resources.FilterdStreamRule) map[string]struct{} {
+	values := map[string]struct{}{}
+	for _, r := range rules {
+		values[gotwi.StringValue(r.Value)] = struct{}{}
+	}
+	return values
+}
+
+// deleteRuleErrors matches the errors of a deletion to the rules by ID.
+func deleteRuleErrors(rules []resources.FilterdStreamRule, errs []resources.PartialError) []RuleError {
+	byID := map[string]Rule{}
+	for _, r := range rules {
+		byID[gotwi.StringValue(r.ID)] = ruleOf(r)
+	}
+
+	res := []RuleError{}
+	for _, e := range errs {
+		id := gotwi.StringValue(e.ResourceID)
+		if id == "" {
+			id = gotwi.StringValue(e.Value)
+		}
+
+		r, ok := byID[id]
+		if !ok {
+			r = Rule{Value: id}
+		}
+		res = append(res, RuleError{Rule: r, Error: e})
+	}
+
+	return res
+}
+
+// ruleErrors matches the errors of a creation to the rules by value. Duplicate rule
+// errors for the values in ignoreDuplicates are skipped.
+func ruleErrors(rules []Rule, errs []resources.PartialError, ignoreDuplicates map[string]struct{}) []RuleError {
+	byValue := map[string]Rule{}
+	for _, r := range rules {
+		byValue[r.Value] = r
+	}
+
+	res := []RuleError{}
+	for _, e := range errs {
+		value := gotwi.StringValue(e.Value)
+		if _, ok := ignoreDuplicates[value]; ok && gotwi.StringValue(e.Type) == duplicateRuleType {
+			continue
+		}
+
+		r, ok := byValue[value]
+		if !ok {
+			r = Rule{Value: value}
+		}
+		res = append(res, RuleError{Rule: r, Error: e})
+	}
+
+	return res
+}
@@ -0,0 +1,345 @@
+package filteredstream_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xxiiaaon/gotwi"
+	"github.com/xxiiaaon/gotwi/tweet/filteredstream"
+	"github.com/stretchr/testify/assert"
+)
+
+type RoundTripFunc func(req *http.Request) *http.Response
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func newJSONResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		Status:     http.StatusText(statusCode),
+		StatusCode: statusCode,
+		Header:     map[string][]string{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// rulesServer answers the rules endpoints, with {} if the body is not set. The calls are
+// recorded as "list", "delete", "create" with a "dry-" prefix for dry runs.
+type rulesServer struct {
+	live      string
+	dryCreate string
+	create    string
+	dryDelete string
+	delete    string
+	calls     []string
+}
+
+func orEmptyObject(body string) string {
+	if body == "" {
+		return "{}"
+	}
+	return body
+}
+
+func (s *rulesServer) client(t *testing.T) *gotwi.Client {
+	c, err := gotwi.NewClientWithAccessToken(&gotwi.NewClientWithAccessTokenInput{
+		AccessToken: "token",
+		HTTPClient: &http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			if req.Method == http.MethodGet {
+				s.calls = append(s.calls, "list")
+				return newJSONResponse(http.StatusOK, s.live)
+			}
+
+			prefix := ""
+			if req.URL.Query().Get("dry_run") == "true" {
+				prefix = "dry-"
+			}
+
+			body := map[string]json.RawMessage{}
+			b, _ := io.ReadAll(req.Body)
+			_ = json.Unmarshal(b, &body)
+			if _, ok := body["delete"]; ok {
+				s.calls = append(s.calls, prefix+"delete")
+				if prefix != "" {
+					return newJSONResponse(http.StatusOK, orEmptyObject(s.dryDelete))
+				}
+				return newJSONResponse(http.StatusOK, orEmptyObject(s.delete))
+			}
+
+			s.calls = append(s.calls, prefix+"create")
+			if prefix != "" {
+				return newJSONResponse(http.StatusOK, orEmptyObject(s.dryCreate))
+			}
+			return newJSONResponse(http.StatusCreated, orEmptyObject(s.create))
+		})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+const liveRules = `{"data":[
+	{"id":"1","value":"cat has:images","tag":"cats"},
+	{"id":"2","value":"dog","tag":"dogs"},
+	{"id":"3","value":"bird"}
+]}`
+
+func Test_PlanRules(t *testing.T) {
+	cases := []struct {
+		name            string
+		server          *rulesServer
+		desired         []filteredstream.Rule
+		wantErr         error
+		expectCalls     []string
+		expectDel       []string
+		expectAdd       []filteredstream.Rule
+		expectSame      int
+		expectRuleError []string
+	}{
+		{
+			name: "ok: no changes",
+			desired: []filteredstream.Rule{
+				{Value: "cat has:images", Tag: "cats"},
+				{Value: "dog", Tag: "dogs"},
+				{Value: "bird"},
+			},
+			expectCalls:     []string{"list"},
+			expectDel:       []string{},
+			expectAdd:       []filteredstream.Rule{},
+			expectSame:      3,
+			expectRuleError: []string{},
+		},
+		{
+			name: "ok: changed tag replaces the rule",
+			desired: []filteredstream.Rule{
+				{Value: "cat has:images", Tag: "cats"},
+				{Value: "dog", Tag: "puppies"},
+				{Value: "fish"},
+			},
+			expectCalls:     []string{"list", "dry-delete", "dry-create"},
+			expectDel:       []string{"2", "3"},
+			expectAdd:       []filteredstream.Rule{{Value: "dog", Tag: "puppies"}, {Value: "fish"}},
+			expectSame:      1,
+			expectRuleError: []string{},
+		},
+		{
+			name:            "ok: no desired rules deletes all",
+			desired:         []filteredstream.Rule{},
+			expectCalls:     []string{"list", "dry-delete"},
+			expectDel:       []string{"1", "2", "3"},
+			expectAdd:       []filteredstream.Rule{},
+			expectSame:      0,
+			expectRuleError: []string{},
+		},
+		{
+			name: "error: dry run rejects a rule",
+			server: &rulesServer{
+				live: liveRules,
+				dryCreate: `{"meta":{"summary":{"created":0,"not_created":1}},"errors":[
+					{"value":"fish (","title":"UnprocessableEntity","detail":"Rule has an unmatched parenthesis.","type":"https://api.twitter.com/2/problems/invalid-rules"}
+				]}`,
+			},
+			desired: []filteredstream.Rule{
+				{Value: "cat has:images", Tag: "cats"},
+				{Value: "fish (", Tag: "fish"},
+			},
+			wantErr:         filteredstream.ErrInvalidRules,
+			expectCalls:     []string{"list", "dry-delete", "dry-create"},
+			expectDel:       []string{"2", "3"},
+			expectAdd:       []filteredstream.Rule{{Value: "fish (", Tag: "fish"}},
+			expectSame:      1,
+			expectRuleError: []string{"fish ("},
+		},
+		{
+			name:    "error: empty value",
+			desired: []filteredstream.Rule{{Tag: "empty"}},
+		},
+		{
+			name:    "error: duplicated value",
+			desired: []filteredstream.Rule{{Value: "dog", Tag: "a"}, {Value: "dog", Tag: "b"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			s := c.server
+			if s == nil {
+				s = &rulesServer{live: liveRules}
+			}
+			report, err := filteredstream.PlanRules(context.Background(), s.client(tt), c.desired)
+			if c.expectCalls == nil {
+				asst.Error(err)
+				asst.Nil(report)
+				asst.Empty(s.calls)
+				return
+			}
+
+			if c.wantErr != nil {
+				asst.ErrorIs(err, c.wantErr)
+			} else {
+				asst.NoError(err)
+			}
+			asst.Equal(c.expectCalls, s.calls)
+			asst.Equal(c.expectAdd, report.ToCreate)
+			asst.Len(report.Unchanged, c.expectSame)
+			asst.Equal(len(c.expectAdd)+len(c.expectDel) > 0, report.HasChanges())
+			asst.False(report.Applied)
+
+			ids := []string{}
+			for _, r := range report.ToDelete {
+				ids = append(ids, gotwi.StringValue(r.ID))
+			}
+			asst.Equal(c.expectDel, ids)
+
+			values := []string{}
+			for _, e := range report.RuleErrors {
+				values = append(values, e.Rule.Value)
+			}
+			asst.Equal(c.expectRuleError, values)
+		})
+	}
+}
+
+func Test_SyncRules(t *testing.T) {
+	cases := []struct {
+		name            string
+		server          *rulesServer
+		desired         []filteredstream.Rule
+		wantErr         error
+		expectCalls     []string
+		expectApplied   bool
+		expectCreated   int
+		expectRuleError []string
+	}{
+		{
+			name: "ok: delete and create",
+			server: &rulesServer{
+				live:      liveRules,
+				dryCreate: `{"meta":{"summary":{"created":2,"not_created":0}}}`,
+				create:    `{"data":[{"id":"10","value":"dog","tag":"puppies"},{"id":"11","value":"fish"}],"meta":{"summary":{"created":2,"not_created":0}}}`,
+				delete:    `{"meta":{"summary":{"deleted":2,"not_deleted":0}}}`,
+			},
+			desired: []filteredstream.Rule{
+				{Value: "cat has:images", Tag: "cats"},
+				{Value: "dog", Tag: "puppies"},
+				{Value: "fish"},
+			},
+			expectCalls:     []string{"list", "dry-delete", "dry-create", "delete", "create"},
+			expectApplied:   true,
+			expectCreated:   2,
+			expectRuleError: []string{},
+		},
+		{
+			name:   "ok: no changes",
+			server: &rulesServer{live: liveRules},
+			desired: []filteredstream.Rule{
+				{Value: "cat has:images", Tag: "cats"},
+				{Value: "dog", Tag: "dogs"},
+				{Value: "bird"},
+			},
+			expectCalls: []string{"list"},
+		},
+		{
+			name: "ok: duplicate of a rule to delete is ignored in dry run",
+			server: &rulesServer{
+				live: liveRules,
+				dryCreate: `{"meta":{"summary":{"created":0,"not_created":1}},"errors":[
+					{"value":"dog","id":"2","title":"DuplicateRule","type":"https://api.twitter.com/2/problems/duplicate-rules"}
+				]}`,
+				create: `{"data":[{"id":"10","value":"dog","tag":"puppies"}],"meta":{"summary":{"created":1,"not_created":0}}}`,
+				delete: `{"meta":{"summary":{"deleted":2,"not_deleted":0}}}`,
+			},
+			desired: []filteredstream.Rule{
+				{Value: "cat has:images", Tag: "cats"},
+				{Value: "dog", Tag: "puppies"},
+			},
+			expectCalls:     []string{"list", "dry-delete", "dry-create", "delete", "create"},
+			expectApplied:   true,
+			expectCreated:   1,
+			expectRuleError: []string{},
+		},
+		{
+			name: "error: invalid rule stops before changes",
+			server: &rulesServer{
+				live: liveRules,
+				dryCreate: `{"meta":{"summary":{"created":1,"not_created":1}},"errors":[
+					{"value":"fish (","title":"UnprocessableEntity","detail":"Rule has an unmatched parenthesis.","type":"https://api.twitter.com/2/problems/invalid-rules"}
+				]}`,
+				delete: `{"meta":{"summary":{"deleted":3,"not_deleted":0}}}`,
+			},
+			desired: []filteredstream.Rule{
+				{Value: "fish (", Tag: "fish"},
+				{Value: "whale"},
+			},
+			wantErr:         filteredstream.ErrInvalidRules,
+			expectCalls:     []string{"list", "dry-delete", "dry-create"},
+			expectRuleError: []string{"fish ("},
+		},
+		{
+			name: "error: deletion errors are reported",
+			server: &rulesServer{
+				live:      liveRules,
+				dryCreate: `{"meta":{"summary":{"created":1,"not_created":0}}}`,
+				create:    `{"data":[{"id":"10","value":"fish"}],"meta":{"summary":{"created":1,"not_created":0}}}`,
+				delete: `{"meta":{"summary":{"deleted":1,"not_deleted":1}},"errors":[
+					{"resource_id":"3","title":"Not Found Error","detail":"Could not find the rule.","type":"https://api.twitter.com/2/problems/resource-not-found"}
+				]}`,
+			},
+			desired: []filteredstream.Rule{
+				{Value: "cat has:images", Tag: "cats"},
+				{Value: "fish"},
+			},
+			wantErr:         filteredstream.ErrInvalidRules,
+			expectCalls:     []string{"list", "dry-delete", "dry-create", "delete", "create"},
+			expectApplied:   true,
+			expectCreated:   1,
+			expectRuleError: []string{"bird"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(tt *testing.T) {
+			asst := assert.New(tt)
+
+			report, err := filteredstream.SyncRules(context.Background(), c.server.client(tt), c.desired)
+			if c.wantErr != nil {
+				asst.ErrorIs(err, c.wantErr)
+			} else {
+				asst.NoError(err)
+			}
+
+			asst.Equal(c.expectCalls, c.server.calls)
+			asst.Equal(c.expectApplied, report.Applied)
+			asst.Len(report.Created, c.expectCreated)
+
+			values := []string{}
+			for _, e := range report.RuleErrors {
+				values = append(values, e.Rule.Value)
+			}
+			if c.expectRuleError != nil {
+				asst.Equal(c.expectRuleError, values)
+			}
+		})
+	}
+}
+
+func Test_SyncRulesReport_String(t *testing.T) {
+	asst := assert.New(t)
+
+	s := &rulesServer{live: liveRules}
+	report, err := filteredstream.PlanRules(context.Background(), s.client(t), []filteredstream.Rule{
+		{Value: "cat has:images", Tag: "cats"},
+		{Value: "fish"},
+	})
+	asst.NoError(err)
+
+	asst.Equal("- dog (tag: dogs)\n- bird\n+ fish\n2 to delete, 1 to create, 1 unchanged\n", report.String())
+}
@@ -8,10 +8,10 @@ import (
 )
 
 const (
-	listUsersEndpoint = "https://api.twitter.com/2/tweets/:id/liking_users"
-	listEndpoint      = "https://api.twitter.com/2/users/:id/liked_tweets"
-	createEndpoint    = "https://api.twitter.com/2/users/:id/likes"
-	deleteEndpoint    = "https://api.twitter.com/2/users/:id/likes/:tweet_id"
+	listUsersEndpoint = "/2/tweets/:id/liking_users"
+	listEndpoint      = "/2/users/:id/liked_tweets"
+	createEndpoint    = "/2/users/:id/likes"
+	deleteEndpoint    = "/2/users/:id/likes/:tweet_id"
 )
 
 // Allows you to get information about a Tweet’s liking users.
@@ -20,6 +20,9 @@ const (
 func ListUsers(ctx context.Context, c *gotwi.Client, p *types.ListUsersInput) (*types.ListUsersOutput, error) {
 	res := &types.ListUsersOutput{}
 	if err := c.CallAPI(ctx, listUsersEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -32,6 +35,9 @@ func ListUsers(ctx context.Context, c *gotwi.Client, p *types.ListUsersInput) (*
 func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.ListOutput, error) {
 	res := &types.ListOutput{}
 	if err := c.CallAPI(ctx, listEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -43,6 +49,9 @@ func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.List
 func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.CreateOutput, error) {
 	res := &types.CreateOutput{}
 	if err := c.CallAPI(ctx, createEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -56,6 +65,9 @@ func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.
 func Delete(ctx context.Context, c *gotwi.Client, p *types.DeleteInput) (*types.DeleteOutput, error) {
 	res := &types.DeleteOutput{}
 	if err := c.CallAPI(ctx, deleteEndpoint, "DELETE", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
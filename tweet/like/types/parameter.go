@@ -51,6 +51,10 @@ func (p *ListUsersInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListUsersInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListUsersInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
@@ -134,6 +138,10 @@ func (p *ListInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
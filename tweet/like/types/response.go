@@ -3,7 +3,8 @@ package types
 import "github.com/xxiiaaon/gotwi/resources"
 
 type ListUsersOutput struct {
-	Data     []resources.User `json:"data"`
+	Data     []resources.User         `json:"data"`
+	Meta     resources.PaginationMeta `json:"meta"`
 	Includes struct {
 		Tweets []resources.Tweet `json:"tweets,omitempty"`
 		Places []resources.Place `json:"places,omitempty"`
@@ -17,6 +18,17 @@ func (r *ListUsersOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListUsersOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListUsersOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type ListOutput struct {
 	Data     []resources.Tweet `json:"data"`
 	Meta     resources.PaginationMeta
@@ -33,6 +45,17 @@ func (r *ListOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type CreateOutput struct {
 	Data struct {
 		Liked bool `json:"liked"`
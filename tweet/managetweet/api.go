@@ -8,8 +8,8 @@ import (
 )
 
 const (
-	createEndpoint = "https://api.twitter.com/2/tweets"
-	deleteEndpoint = "https://api.twitter.com/2/tweets/:id"
+	createEndpoint = "/2/tweets"
+	deleteEndpoint = "/2/tweets/:id"
 )
 
 // Creates a Tweet on behalf of an authenticated user.
@@ -17,6 +17,9 @@ const (
 func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.CreateOutput, error) {
 	res := &types.CreateOutput{}
 	if err := c.CallAPI(ctx, createEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -28,6 +31,9 @@ func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.
 func Delete(ctx context.Context, c *gotwi.Client, p *types.DeleteInput) (*types.DeleteOutput, error) {
 	res := &types.DeleteOutput{}
 	if err := c.CallAPI(ctx, deleteEndpoint, "DELETE", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
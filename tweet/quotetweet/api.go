@@ -8,7 +8,7 @@ import (
 )
 
 const (
-	listEndpoint = "https://api.twitter.com/2/tweets/:id/quote_tweets"
+	listEndpoint = "/2/tweets/:id/quote_tweets"
 )
 
 // Returns Quote Tweets for a Tweet specified by the requested Tweet ID.
@@ -16,6 +16,9 @@ const (
 func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.ListOutput, error) {
 	res := &types.ListOutput{}
 	if err := c.CallAPI(ctx, listEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
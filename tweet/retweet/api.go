@@ -8,9 +8,9 @@ import (
 )
 
 const (
-	listUsersEndpoint = "https://api.twitter.com/2/tweets/:id/retweeted_by"
-	createEndpoint    = "https://api.twitter.com/2/users/:id/retweets"
-	deleteEndpoint    = "https://api.twitter.com/2/users/:id/retweets/:source_tweet_id"
+	listUsersEndpoint = "/2/tweets/:id/retweeted_by"
+	createEndpoint    = "/2/users/:id/retweets"
+	deleteEndpoint    = "/2/users/:id/retweets/:source_tweet_id"
 )
 
 // Allows you to get information about who has Retweeted a Tweet.
@@ -18,6 +18,9 @@ const (
 func ListUsers(ctx context.Context, c *gotwi.Client, p *types.ListUsersInput) (*types.ListUsersOutput, error) {
 	res := &types.ListUsersOutput{}
 	if err := c.CallAPI(ctx, listUsersEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -29,6 +32,9 @@ func ListUsers(ctx context.Context, c *gotwi.Client, p *types.ListUsersInput) (*
 func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.CreateOutput, error) {
 	res := &types.CreateOutput{}
 	if err := c.CallAPI(ctx, createEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -42,6 +48,9 @@ func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.
 func Delete(ctx context.Context, c *gotwi.Client, p *types.DeleteInput) (*types.DeleteOutput, error) {
 	res := &types.DeleteOutput{}
 	if err := c.CallAPI(ctx, deleteEndpoint, "DELETE", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -52,6 +52,10 @@ func (p *ListUsersInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListUsersInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListUsersInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
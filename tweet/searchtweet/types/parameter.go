@@ -78,6 +78,10 @@ func (p *ListRecentInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListRecentInput) SetPaginationToken(token string) {
+	p.NextToken = token
+}
+
 func (p *ListRecentInput) ResolveEndpoint(endpointBase string) string {
 	endpoint := endpointBase
 
@@ -181,6 +185,10 @@ func (p *ListAllInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListAllInput) SetPaginationToken(token string) {
+	p.NextToken = token
+}
+
 func (p *ListAllInput) ResolveEndpoint(endpointBase string) string {
 	endpoint := endpointBase
 
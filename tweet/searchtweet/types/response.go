@@ -19,6 +19,17 @@ func (r *ListRecentOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListRecentOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListRecentOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type ListAllOutput struct {
 	Data     []resources.Tweet        `json:"data"`
 	Meta     resources.PaginationMeta `json:"meta"`
@@ -35,3 +46,14 @@ type ListAllOutput struct {
 func (r *ListAllOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
+
+func (r *ListAllOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListAllOutput) ResultCount() int {
+	return len(r.Data)
+}
@@ -8,9 +8,9 @@ import (
 )
 
 const (
-	listTweetsEndpoint               = "https://api.twitter.com/2/users/:id/tweets"
-	listMentionsEndpoint             = "https://api.twitter.com/2/users/:id/mentions"
-	listReverseChronologicalEndpoint = "https://api.twitter.com/2/users/:id/timelines/reverse_chronological"
+	listTweetsEndpoint               = "/2/users/:id/tweets"
+	listMentionsEndpoint             = "/2/users/:id/mentions"
+	listReverseChronologicalEndpoint = "/2/users/:id/timelines/reverse_chronological"
 )
 
 // Returns Tweets composed by a single user, specified by the requested user ID.
@@ -20,6 +20,9 @@ const (
 func ListTweets(ctx context.Context, c *gotwi.Client, p *types.ListTweetsInput) (*types.ListTweetsOutput, error) {
 	res := &types.ListTweetsOutput{}
 	if err := c.CallAPI(ctx, listTweetsEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -33,6 +36,9 @@ func ListTweets(ctx context.Context, c *gotwi.Client, p *types.ListTweetsInput)
 func ListMentions(ctx context.Context, c *gotwi.Client, p *types.ListMentionsInput) (*types.ListMentionsOutput, error) {
 	res := &types.ListMentionsOutput{}
 	if err := c.CallAPI(ctx, listMentionsEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -46,6 +52,9 @@ func ListMentions(ctx context.Context, c *gotwi.Client, p *types.ListMentionsInp
 func ListReverseChronological(ctx context.Context, c *gotwi.Client, p *types.ListReverseChronologicalInput) (*types.ListReverseChronologicalOutput, error) {
 	res := &types.ListReverseChronologicalOutput{}
 	if err := c.CallAPI(ctx, listReverseChronologicalEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -68,6 +68,10 @@ func (p *ListTweetsInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListTweetsInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListTweetsInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
@@ -167,6 +171,10 @@ func (p *ListMentionsInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListMentionsInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListMentionsInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
@@ -266,6 +274,10 @@ func (p *ListReverseChronologicalInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListReverseChronologicalInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListReverseChronologicalInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
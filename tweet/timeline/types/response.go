@@ -19,6 +19,17 @@ func (r *ListTweetsOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListTweetsOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListTweetsOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type ListMentionsOutput struct {
 	Data     []resources.Tweet `json:"data"`
 	Includes struct {
@@ -36,6 +47,17 @@ func (r *ListMentionsOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListMentionsOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListMentionsOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type ListReverseChronologicalOutput struct {
 	Data     []resources.Tweet `json:"data"`
 	Includes struct {
@@ -52,3 +74,14 @@ type ListReverseChronologicalOutput struct {
 func (r *ListReverseChronologicalOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
+
+func (r *ListReverseChronologicalOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListReverseChronologicalOutput) ResultCount() int {
+	return len(r.Data)
+}
@@ -8,8 +8,8 @@ import (
 )
 
 const (
-	listRecentEndpoint = "https://api.twitter.com/2/tweets/counts/recent"
-	listAllEndpoint    = "https://api.twitter.com/2/tweets/counts/all"
+	listRecentEndpoint = "/2/tweets/counts/recent"
+	listAllEndpoint    = "/2/tweets/counts/all"
 )
 
 // The recent Tweet counts endpoint returns count of Tweets from the last seven days that match a search query.
@@ -17,6 +17,9 @@ const (
 func ListRecent(ctx context.Context, c *gotwi.Client, p *types.ListRecentInput) (*types.ListRecentOutput, error) {
 	res := &types.ListRecentOutput{}
 	if err := c.CallAPI(ctx, listRecentEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -29,6 +32,9 @@ func ListRecent(ctx context.Context, c *gotwi.Client, p *types.ListRecentInput)
 func ListAll(ctx context.Context, c *gotwi.Client, p *types.ListAllInput) (*types.ListAllOutput, error) {
 	res := &types.ListAllOutput{}
 	if err := c.CallAPI(ctx, listAllEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -133,6 +133,10 @@ func (p *ListAllInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListAllInput) SetPaginationToken(token string) {
+	p.NextToken = token
+}
+
 func (p *ListAllInput) ResolveEndpoint(endpointBase string) string {
 	endpoint := endpointBase
 
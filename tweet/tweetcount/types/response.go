@@ -21,3 +21,14 @@ type ListAllOutput struct {
 func (r *ListAllOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
+
+func (r *ListAllOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListAllOutput) ResultCount() int {
+	return len(r.Data)
+}
@@ -8,8 +8,8 @@ import (
 )
 
 const (
-	listEndpoint = "https://api.twitter.com/2/tweets"
-	getEndpoint  = "https://api.twitter.com/2/tweets/:id"
+	listEndpoint = "/2/tweets"
+	getEndpoint  = "/2/tweets/:id"
 )
 
 // Returns a variety of information about the Tweet specified by the requested ID or list of IDs.
@@ -17,6 +17,9 @@ const (
 func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.ListOutput, error) {
 	res := &types.ListOutput{}
 	if err := c.CallAPI(ctx, listEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -28,6 +31,9 @@ func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.List
 func Get(ctx context.Context, c *gotwi.Client, p *types.GetInput) (*types.GetOutput, error) {
 	res := &types.GetOutput{}
 	if err := c.CallAPI(ctx, getEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -8,7 +8,7 @@ import (
 )
 
 const (
-	sampleStreamEndpoint = "https://api.twitter.com/2/tweets/sample/stream"
+	sampleStreamEndpoint = "/2/tweets/sample/stream"
 )
 
 // Streams about 1% of all Tweets in real-time.
@@ -23,3 +23,28 @@ func SampleStream(ctx context.Context, c *gotwi.Client, p *types.SampleStreamInp
 
 	return s, nil
 }
+
+// NewSampleStreamRunner returns a runner that keeps SampleStream connected with p.
+// When opt.Backfill is true, the BackfillMinutes of p is replaced on reconnection
+// by the minutes since the last message. Tweets delivered twice are dropped by ID.
+func NewSampleStreamRunner(c *gotwi.Client, p *types.SampleStreamInput, opt *gotwi.StreamRunnerOption) *gotwi.StreamRunner[*types.SampleStreamOutput] {
+	connect := func(ctx context.Context, backfillMinutes int) (*gotwi.StreamClient[*types.SampleStreamOutput], error) {
+		in := types.SampleStreamInput{}
+		if p != nil {
+			in = *p
+		}
+		if backfillMinutes > 0 {
+			in.BackfillMinutes = types.SampleStreamBackfillMinutes(backfillMinutes)
+		}
+		return SampleStream(ctx, c, &in)
+	}
+
+	id := func(o *types.SampleStreamOutput) string {
+		if o == nil {
+			return ""
+		}
+		return gotwi.StringValue(o.Data.ID)
+	}
+
+	return gotwi.NewStreamRunner(connect, id, opt)
+}
@@ -2,19 +2,30 @@ package gotwi
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/xxiiaaon/gotwi/internal/util"
 	"github.com/xxiiaaon/gotwi/resources"
 )
 
 type TypedClient[T util.Response] struct {
-	Client               *http.Client
-	accessToken          string
-	authenticationMethod AuthenticationMethod
-	oauthToken           string
-	oauthConsumerKey     string
-	signingKey           string
+	Client          *http.Client
+	credentials     *CredentialProvider
+	signer          *oauth1Signer
+	tokenSource     TokenSource
+	baseURL         string
+	rateLimits      *rateLimitTracker
+	rateLimiter     *RateLimiter
+	middlewares     []Middleware
+	logger          *slog.Logger
+	instrumentation Instrumentation
+
+	maxStreamMessageSize int
+	streamIdleTimeout    time.Duration
 }
 
 func NewTypedClient[T util.Response](c *Client) *TypedClient[T] {
@@ -23,12 +34,19 @@ func NewTypedClient[T util.Response](c *Client) *TypedClient[T] {
 	}
 
 	return &TypedClient[T]{
-		Client:               c.Client,
-		accessToken:          c.AccessToken(),
-		authenticationMethod: c.AuthenticationMethod(),
-		oauthToken:           c.OAuthToken(),
-		oauthConsumerKey:     c.OAuthConsumerKey(),
-		signingKey:           c.SigningKey(),
+		Client:          c.StreamHTTPClient(),
+		credentials:     c.CredentialProvider(),
+		signer:          c.oauth1Signer(),
+		tokenSource:     c.TokenSource(),
+		baseURL:         c.BaseURL(),
+		rateLimits:      &c.rateLimits,
+		rateLimiter:     c.rateLimiter,
+		middlewares:     c.Middlewares(),
+		logger:          c.Logger(),
+		instrumentation: c.instrumentation,
+
+		maxStreamMessageSize: c.maxStreamMessageSize,
+		streamIdleTimeout:    c.StreamIdleTimeout(),
 	}
 }
 
@@ -37,22 +55,7 @@ func (c *TypedClient[T]) IsReady() bool {
 		return false
 	}
 
-	if !c.AuthenticationMethod().Valid() {
-		return false
-	}
-
-	switch c.AuthenticationMethod() {
-	case AuthenMethodOAuth1UserContext:
-		if c.OAuthToken() == "" || c.SigningKey() == "" {
-			return false
-		}
-	case AuthenMethodOAuth2BearerToken:
-		if c.AccessToken() == "" {
-			return false
-		}
-	}
-
-	return true
+	return credentialsReady(c.credentials.Credentials(), c.TokenSource())
 }
 
 func (c *TypedClient[T]) Exec(req *http.Request, i util.Response) (*resources.Non2XXError, error) {
@@ -61,53 +64,128 @@ func (c *TypedClient[T]) Exec(req *http.Request, i util.Response) (*resources.No
 }
 
 func (c *TypedClient[T]) AccessToken() string {
-	return c.accessToken
+	return c.credentials.Credentials().AccessToken
+}
+
+// CredentialProvider returns the live credentials shared with the Client the TypedClient was created from.
+func (c *TypedClient[T]) CredentialProvider() *CredentialProvider {
+	if c == nil {
+		return nil
+	}
+	return c.credentials
+}
+
+func (c *TypedClient[T]) TokenSource() TokenSource {
+	if c == nil {
+		return nil
+	}
+	return c.tokenSource
 }
 
 func (c *TypedClient[T]) AuthenticationMethod() AuthenticationMethod {
-	return c.authenticationMethod
+	return c.credentials.Credentials().AuthenticationMethod
+}
+
+func (c *TypedClient[T]) BaseURL() string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return DefaultBaseURL
 }
 
 func (c *TypedClient[T]) OAuthToken() string {
-	return c.oauthToken
+	return c.credentials.Credentials().OAuthToken
 }
 func (c *TypedClient[T]) OAuthConsumerKey() string {
-	return c.oauthConsumerKey
+	return c.credentials.Credentials().OAuthConsumerKey
 }
 func (c *TypedClient[T]) SigningKey() string {
-	return c.signingKey
+	return c.credentials.Credentials().SigningKey
 }
 
-func (c *TypedClient[T]) CallStreamAPI(ctx context.Context, endpoint, method string, p util.Parameters) (*StreamClient[T], error) {
-	req, err := prepare(ctx, endpoint, method, p, c)
-	if err != nil {
-		return nil, wrapErr(err)
+func (c *TypedClient[T]) oauth1Signer() *oauth1Signer {
+	if c == nil {
+		return nil
 	}
+	return c.signer
+}
 
-	res, non200err, err := c.ExecStream(req)
-	if err != nil {
-		return nil, wrapErr(err)
+func (c *TypedClient[T]) limiter() *RateLimiter {
+	if c == nil {
+		return nil
 	}
+	return c.rateLimiter
+}
 
-	if non200err != nil {
-		return nil, wrapWithAPIErr(non200err)
+func (c *TypedClient[T]) CallStreamAPI(ctx context.Context, endpoint, method string, p util.Parameters) (*StreamClient[T], error) {
+	var res *http.Response
+	for authRetried := false; ; authRetried = true {
+		if err := c.limiter().wait(ctx, strings.ToUpper(method)+" "+endpointTemplate(endpoint)); err != nil {
+			return nil, wrapErr(err)
+		}
+
+		req, err := prepare(ctx, endpoint, method, p, c)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+
+		r, non200err, err := c.ExecStream(req)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+
+		if non200err != nil {
+			if !authRetried && retryAuthError(c.TokenSource(), c.signer, non200err, p.AccessToken()) {
+				// retry once with a new token or a corrected clock
+				continue
+			}
+			return nil, wrapWithAPIErr(non200err)
+		}
+
+		res = r
+		break
 	}
 
-	s, err := newStreamClient[T](res)
+	res.Body = withIdleTimeout(res.Body, c.streamIdleTimeout)
+	s, err := newStreamClient[T](res, c.maxStreamMessageSize)
 	if err != nil {
 		return nil, err
 	}
 
+	if c.instrumentation != nil {
+		sctx := ctx
+		if res.Request != nil {
+			// carries the trace span started by the instrumentation
+			sctx = res.Request.Context()
+		}
+		s.instrument(sctx, c.instrumentation, RequestInfo{
+			Method:   method,
+			Endpoint: endpointTemplate(endpoint),
+			Stream:   true,
+		})
+	}
+
 	return s, nil
 }
 
 func (c *TypedClient[T]) ExecStream(req *http.Request) (*http.Response, *resources.Non2XXError, error) {
-	res, err := c.Client.Do(req)
+	exec := chainMiddlewares(execHTTP(c.Client, c.rateLimits, c.rateLimiter, c.signer), buildMiddlewares(c.middlewares, c.instrumentation, c.logger, true))
+	res, non200err, err := exec(req)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if non200err != nil {
+		return nil, non200err, nil
+	}
+
+	if res == nil {
+		return nil, nil, errors.New("HTTP Response is nil.")
+	}
+
 	if _, ok := okCodes[res.StatusCode]; !ok {
+		// a middleware returned a response without resolving the error
+		defer res.Body.Close()
 		non200err, err := resolveNon2XXResponse(res)
 		if err != nil {
 			return nil, nil, err
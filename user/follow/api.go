@@ -8,10 +8,10 @@ import (
 )
 
 const (
-	listFollowingsEndpoint  = "https://api.twitter.com/2/users/:id/following"
-	listFollowersEndpoint   = "https://api.twitter.com/2/users/:id/followers"
-	createFollowingEndpoint = "https://api.twitter.com/2/users/:id/following"
-	deleteFollowingEndpoint = "https://api.twitter.com/2/users/:source_user_id/following/:target_user_id"
+	listFollowingsEndpoint  = "/2/users/:id/following"
+	listFollowersEndpoint   = "/2/users/:id/followers"
+	createFollowingEndpoint = "/2/users/:id/following"
+	deleteFollowingEndpoint = "/2/users/:source_user_id/following/:target_user_id"
 )
 
 // Returns a list of users the specified user ID is following.
@@ -19,6 +19,9 @@ const (
 func ListFollowings(ctx context.Context, c *gotwi.Client, p *types.ListFollowingsInput) (*types.ListFollowingsOutput, error) {
 	res := &types.ListFollowingsOutput{}
 	if err := c.CallAPI(ctx, listFollowingsEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -30,6 +33,9 @@ func ListFollowings(ctx context.Context, c *gotwi.Client, p *types.ListFollowing
 func ListFollowers(ctx context.Context, c *gotwi.Client, p *types.ListFollowersInput) (*types.ListFollowersOutput, error) {
 	res := &types.ListFollowersOutput{}
 	if err := c.CallAPI(ctx, listFollowersEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -44,6 +50,9 @@ func ListFollowers(ctx context.Context, c *gotwi.Client, p *types.ListFollowersI
 func CreateFollowing(ctx context.Context, c *gotwi.Client, p *types.CreateFollowingInput) (*types.CreateFollowingOutput, error) {
 	res := &types.CreateFollowingOutput{}
 	if err := c.CallAPI(ctx, createFollowingEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -56,6 +65,9 @@ func CreateFollowing(ctx context.Context, c *gotwi.Client, p *types.CreateFollow
 func DeleteFollowing(ctx context.Context, c *gotwi.Client, p *types.DeleteFollowingInput) (*types.DeleteFollowingOutput, error) {
 	res := &types.DeleteFollowingOutput{}
 	if err := c.CallAPI(ctx, deleteFollowingEndpoint, "DELETE", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -51,6 +51,10 @@ func (p *ListFollowingsInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListFollowingsInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListFollowingsInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
@@ -128,6 +132,10 @@ func (p *ListFollowersInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListFollowersInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListFollowersInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
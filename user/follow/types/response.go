@@ -15,6 +15,17 @@ func (r *ListFollowingsOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListFollowingsOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListFollowingsOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type ListFollowersOutput struct {
 	Data     []resources.User         `json:"data"`
 	Meta     resources.PaginationMeta `json:"meta"`
@@ -28,6 +39,17 @@ func (r *ListFollowersOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListFollowersOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListFollowersOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 type CreateFollowingOutput struct {
 	Data struct {
 		Following     bool `json:"following"`
@@ -8,9 +8,9 @@ import (
 )
 
 const (
-	listEndpoint   = "https://api.twitter.com/2/users/:id/muting"
-	createEndpoint = "https://api.twitter.com/2/users/:id/muting"
-	deleteEndpoint = "https://api.twitter.com/2/users/:source_user_id/muting/:target_user_id"
+	listEndpoint   = "/2/users/:id/muting"
+	createEndpoint = "/2/users/:id/muting"
+	deleteEndpoint = "/2/users/:source_user_id/muting/:target_user_id"
 )
 
 // Returns a list of users who are muted by the specified user ID.
@@ -18,6 +18,9 @@ const (
 func Lists(ctx context.Context, c *gotwi.Client, p *types.ListsInput) (*types.ListsOutput, error) {
 	res := &types.ListsOutput{}
 	if err := c.CallAPI(ctx, listEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -29,6 +32,9 @@ func Lists(ctx context.Context, c *gotwi.Client, p *types.ListsInput) (*types.Li
 func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.CreateOutput, error) {
 	res := &types.CreateOutput{}
 	if err := c.CallAPI(ctx, createEndpoint, "POST", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -41,6 +47,9 @@ func Create(ctx context.Context, c *gotwi.Client, p *types.CreateInput) (*types.
 func Delete(ctx context.Context, c *gotwi.Client, p *types.DeleteInput) (*types.DeleteOutput, error) {
 	res := &types.DeleteOutput{}
 	if err := c.CallAPI(ctx, deleteEndpoint, "DELETE", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
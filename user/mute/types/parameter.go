@@ -53,6 +53,10 @@ func (p *ListsInput) AccessToken() string {
 	return p.accessToken
 }
 
+func (p *ListsInput) SetPaginationToken(token string) {
+	p.PaginationToken = token
+}
+
 func (p *ListsInput) ResolveEndpoint(endpointBase string) string {
 	if p.ID == "" {
 		return ""
@@ -18,6 +18,17 @@ func (r *ListsOutput) HasPartialError() bool {
 	return !(r.Errors == nil || len(r.Errors) == 0)
 }
 
+func (r *ListsOutput) NextPaginationToken() string {
+	if r.Meta.NextToken == nil {
+		return ""
+	}
+	return *r.Meta.NextToken
+}
+
+func (r *ListsOutput) ResultCount() int {
+	return len(r.Data)
+}
+
 // CreateOutput is struct for response of `POST /2/users/:id/muting`.
 // more information: https://developer.twitter.com/en/docs/twitter-api/users/mutes/api-reference/post-users-user_id-muting
 // more information:
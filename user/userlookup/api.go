@@ -8,11 +8,11 @@ import (
 )
 
 const (
-	listEndpoint            = "https://api.twitter.com/2/users"
-	getEndpoint             = "https://api.twitter.com/2/users/:id"
-	listByUsernamesEndpoint = "https://api.twitter.com/2/users/by"
-	getByUsernameEndpoint   = "https://api.twitter.com/2/users/by/username/:username"
-	getMeEndpoint           = "https://api.twitter.com/2/users/me"
+	listEndpoint            = "/2/users"
+	getEndpoint             = "/2/users/:id"
+	listByUsernamesEndpoint = "/2/users/by"
+	getByUsernameEndpoint   = "/2/users/by/username/:username"
+	getMeEndpoint           = "/2/users/me"
 )
 
 // GET /2/users
@@ -21,6 +21,9 @@ const (
 func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.ListOutput, error) {
 	res := &types.ListOutput{}
 	if err := c.CallAPI(ctx, listEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -33,6 +36,9 @@ func List(ctx context.Context, c *gotwi.Client, p *types.ListInput) (*types.List
 func Get(ctx context.Context, c *gotwi.Client, p *types.GetInput) (*types.GetOutput, error) {
 	res := &types.GetOutput{}
 	if err := c.CallAPI(ctx, getEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -45,6 +51,9 @@ func Get(ctx context.Context, c *gotwi.Client, p *types.GetInput) (*types.GetOut
 func ListByUsernames(ctx context.Context, c *gotwi.Client, p *types.ListByUsernamesInput) (*types.ListByUsernamesOutput, error) {
 	res := &types.ListByUsernamesOutput{}
 	if err := c.CallAPI(ctx, listByUsernamesEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -57,6 +66,9 @@ func ListByUsernames(ctx context.Context, c *gotwi.Client, p *types.ListByUserna
 func GetByUsername(ctx context.Context, c *gotwi.Client, p *types.GetByUsernameInput) (*types.GetByUsernameOutput, error) {
 	res := &types.GetByUsernameOutput{}
 	if err := c.CallAPI(ctx, getByUsernameEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 
@@ -69,6 +81,9 @@ func GetByUsername(ctx context.Context, c *gotwi.Client, p *types.GetByUsernameI
 func GetMe(ctx context.Context, c *gotwi.Client, p *types.GetMeInput) (*types.GetMeOutput, error) {
 	res := &types.GetMeOutput{}
 	if err := c.CallAPI(ctx, getMeEndpoint, "GET", p, res); err != nil {
+		if gotwi.IsPartialErrors(err) {
+			return res, err
+		}
 		return nil, err
 	}
 